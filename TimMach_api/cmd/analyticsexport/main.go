@@ -0,0 +1,85 @@
+// Command analyticsexport ghi dataset ẩn danh (layout cardio_train.csv) từ predictions để huấn luyện lại model.
+// Chạy: go run ./cmd/analyticsexport -out tmp/analytics/training_export.csv -from 2025-01-01 -k 5
+// Cần ANALYTICS_PSEUDONYM_SALT; xem modules/analytics để biết cách ẩn danh và k-anonymity.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"chidinh/config"
+	db "chidinh/db/sqlc"
+	"chidinh/modules/analytics"
+	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	out := flag.String("out", "tmp/analytics/training_export.csv", "output csv file")
+	from := flag.String("from", "", "first prediction date (YYYY-MM-DD)")
+	to := flag.String("to", "", "last prediction date (YYYY-MM-DD, inclusive)")
+	k := flag.Int("k", 5, "minimum patients per (age group, gender) class")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+
+	logger := utils.InitLogger()
+	defer func() { _ = logger.Sync() }()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("cannot parse env", "error", err)
+	}
+	keyring, err := cfg.FieldKeyring()
+	if err != nil {
+		logger.Fatalw("cannot load field encryption keys", "error", err)
+	}
+	fieldcrypt.SetDefault(keyring)
+
+	opts, err := analytics.ParseExportOptions(analytics.TrainingExportParams{From: *from, To: *to, K: k}, cfg.AnalyticsPseudonymSalt)
+	if err != nil {
+		logger.Fatalw("invalid export options", "error", err)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Fatalw("cannot connect db", "error", err, "db_url", cfg.DBURL)
+	}
+	defer pool.Close()
+
+	export, err := analytics.BuildTrainingExport(ctx, db.New(pool), opts)
+	if err != nil {
+		logger.Fatalw("cannot build training export", "error", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		logger.Fatalw("cannot create output dir", "error", err)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		logger.Fatalw("cannot create output file", "error", err, "file", *out)
+	}
+	if err := export.WriteCSV(f); err != nil {
+		_ = f.Close()
+		logger.Fatalw("cannot write csv", "error", err, "file", *out)
+	}
+	if err := f.Close(); err != nil {
+		logger.Fatalw("cannot close output file", "error", err, "file", *out)
+	}
+
+	logger.Infow("training export written",
+		"file", *out,
+		"rows", len(export.Rows),
+		"labeled", export.Labeled,
+		"total", export.Total,
+		"suppressed", export.Suppressed,
+		"skipped", export.Skipped,
+		"k", export.K,
+	)
+}
@@ -0,0 +1,65 @@
+// Command mlparity so sánh scorer Go (utils/mlscorer) với output của FastAPI
+// trên bộ case do ml-python/training/export_model.py sinh ra.
+// Chạy: go run ./cmd/mlparity -model ../ml-python/training/heart_disease_model.json -cases ../ml-python/training/parity_cases.json
+// Exit code 1 nếu có case lệch quá ngưỡng.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"chidinh/utils/mlscorer"
+)
+
+func main() {
+	modelPath := flag.String("model", "../ml-python/training/heart_disease_model.json", "exported model JSON")
+	casesPath := flag.String("cases", "../ml-python/training/parity_cases.json", "parity cases JSON")
+	probTol := flag.Float64("prob-tol", mlscorer.DefaultProbTol, "max absolute probability difference")
+	contribTol := flag.Float64("contrib-tol", mlscorer.DefaultContribTol, "max absolute contribution difference")
+	flag.Parse()
+
+	model, err := mlscorer.Load(*modelPath)
+	if err != nil {
+		fatalf("cannot load model: %v", err)
+	}
+
+	raw, err := os.ReadFile(*casesPath)
+	if err != nil {
+		fatalf("cannot read cases: %v", err)
+	}
+	var cases []mlscorer.ParityCase
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		fatalf("cannot decode cases: %v", err)
+	}
+	if len(cases) == 0 {
+		fatalf("no parity cases in %s", *casesPath)
+	}
+
+	failures := 0
+	for i, c := range cases {
+		got, err := model.Predict(c.Input)
+		if err != nil {
+			fmt.Printf("case %d: predict error: %v\n", i, err)
+			failures++
+			continue
+		}
+		if msgs := mlscorer.Compare(got, c.Output, *probTol, *contribTol); len(msgs) > 0 {
+			failures++
+			for _, m := range msgs {
+				fmt.Printf("case %d: %s\n", i, m)
+			}
+		}
+	}
+
+	fmt.Printf("%d/%d cases match (model %s %s)\n", len(cases)-failures, len(cases), model.ModelName, model.ModelVersion)
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
+}
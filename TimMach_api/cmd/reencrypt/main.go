@@ -0,0 +1,113 @@
+// Command reencrypt mã hoá lại PII (patients.name, patients.dob, predictions.raw_features) bằng key active.
+// Chạy: go run ./cmd/reencrypt
+// Dùng sau khi bật mã hoá (mã hoá dữ liệu plaintext cũ) hoặc sau khi thêm key mới để xoay key;
+// chỉ bản ghi có key_version khác key active hoặc chưa có name_index được xử lý (API cũng tự dựng name_index
+// còn thiếu lúc khởi động), -all để xử lý lại toàn bộ (ví dụ dựng lại name_index khi đổi FIELD_INDEX_KEY). Có thể chạy lại nhiều lần; khi xong có thể bỏ key cũ khỏi FIELD_ENCRYPTION_KEYS.
+package main
+
+import (
+	"context"
+	"flag"
+
+	"chidinh/config"
+	db "chidinh/db/sqlc"
+	"chidinh/modules/patients"
+	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	all := flag.Bool("all", false, "re-encrypt every row, not only rows with another key version")
+	batch := flag.Int("batch", 200, "rows per batch")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+
+	logger := utils.InitLogger()
+	defer func() { _ = logger.Sync() }()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("cannot parse env", "error", err)
+	}
+	keyring, err := cfg.FieldKeyring()
+	if err != nil {
+		logger.Fatalw("cannot load field encryption keys", "error", err)
+	}
+	if !keyring.Enabled() {
+		logger.Warnw("FIELD_ENCRYPTION_KEYS not set, rows are rewritten as plaintext")
+	}
+	fieldcrypt.SetDefault(keyring)
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Fatalw("cannot connect db", "error", err, "db_url", cfg.DBURL)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	// key_version không bao giờ âm nên -1 khớp mọi bản ghi.
+	version := keyring.ActiveVersion()
+	if *all {
+		version = -1
+	}
+
+	patientsDone, patientsSkipped, err := patients.ReencryptPatients(ctx, queries, version, int32(*batch))
+	if err != nil {
+		logger.Fatalw("re-encrypt patients failed", "error", err, "done", patientsDone)
+	}
+	predictionsDone, err := reencryptPredictions(ctx, queries, keyring, version, int32(*batch))
+	if err != nil {
+		logger.Fatalw("re-encrypt predictions failed", "error", err, "done", predictionsDone)
+	}
+
+	logger.Infow("re-encrypt finished",
+		"key_version", keyring.ActiveVersion(),
+		"patients", patientsDone,
+		"patients_skipped", patientsSkipped,
+		"predictions", predictionsDone,
+	)
+}
+
+// reencryptPredictions: raw_features không bị sửa sau khi tạo nên ghi đè trực tiếp.
+func reencryptPredictions(ctx context.Context, q *db.Queries, keyring *fieldcrypt.Keyring, version, batch int32) (int, error) {
+	done := 0
+	var afterID int64
+	for {
+		rows, err := q.ListPredictionsForReencrypt(ctx, db.ListPredictionsForReencryptParams{
+			KeyVersion: version,
+			AfterID:    afterID,
+			Limit:      batch,
+		})
+		if err != nil {
+			return done, err
+		}
+		if len(rows) == 0 {
+			return done, nil
+		}
+		for _, row := range rows {
+			afterID = row.ID
+			plain, err := keyring.OpenJSON(row.RawFeatures)
+			if err != nil {
+				utils.L().Warnw("cannot decrypt raw_features", "error", err, "prediction_id", row.ID)
+				continue
+			}
+			sealed, err := keyring.SealJSON(plain)
+			if err != nil {
+				return done, err
+			}
+			if err := q.UpdatePredictionFeatures(ctx, db.UpdatePredictionFeaturesParams{
+				ID:                 row.ID,
+				RawFeatures:        sealed,
+				FeaturesKeyVersion: keyring.ActiveVersion(),
+			}); err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Command seed nạp catalog bài tập mặc định vào database.
+// Chạy: go run ./cmd/seed -file db/seeds/exercise_catalog.yaml
+// Template đã tồn tại (cùng slug) được giữ nguyên trừ khi truyền -overwrite.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"chidinh/config"
+	db "chidinh/db/sqlc"
+	"chidinh/modules/exercises"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "db/seeds/exercise_catalog.yaml", "catalog file (.json, .yaml)")
+	overwrite := flag.Bool("overwrite", false, "overwrite templates that already exist with different content")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+
+	logger := utils.InitLogger()
+	defer func() { _ = logger.Sync() }()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("cannot parse env", "error", err)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		logger.Fatalw("cannot read catalog", "error", err, "file", *file)
+	}
+
+	format := "json"
+	if ext := strings.ToLower(filepath.Ext(*file)); ext == ".yaml" || ext == ".yml" {
+		format = "yaml"
+	}
+	doc, err := exercises.DecodeCatalog(format, data)
+	if err != nil {
+		logger.Fatalw("cannot decode catalog", "error", err, "file", *file)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Fatalw("cannot connect db", "error", err, "db_url", cfg.DBURL)
+	}
+	defer pool.Close()
+
+	onConflict := "skip"
+	if *overwrite {
+		onConflict = "overwrite"
+	}
+	result, err := exercises.ImportCatalog(ctx, pool, db.New(pool), doc, exercises.ImportOptions{
+		OnConflict: onConflict,
+		DryRun:     *dryRun,
+	})
+	if err != nil {
+		logger.Fatalw("seed failed", "error", err, "errors", result.Errors)
+	}
+
+	logger.Infow("seed completed",
+		"created", len(result.Created),
+		"updated", len(result.Updated),
+		"unchanged", len(result.Unchanged),
+		"conflicts", result.Conflicts,
+		"dry_run", result.DryRun,
+	)
+}
@@ -0,0 +1,81 @@
+// Command statscheck so sánh patient_latest_prediction với predictions và báo số row thiếu/thừa/sai.
+// Chạy: go run ./cmd/statscheck
+// -fix dựng lại các row lệch rồi đánh dấu và làm mới rollup stats của mọi user; có thể chạy lại nhiều lần.
+// Exit code 1 khi còn lệch mà không có -fix (dùng được trong cron/CI để phát hiện).
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"chidinh/config"
+	db "chidinh/db/sqlc"
+	"chidinh/modules/stats"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "rebuild drifted rows and refresh every user's stats rollup")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+
+	logger := utils.InitLogger()
+	defer func() { _ = logger.Sync() }()
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Fatalw("cannot parse env", "error", err)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Fatalw("cannot connect db", "error", err, "db_url", cfg.DBURL)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	drift, err := queries.CountLatestPredictionDrift(ctx)
+	if err != nil {
+		logger.Fatalw("cannot check latest prediction projection", "error", err)
+	}
+	drifted := drift.Missing + drift.Orphaned + drift.Stale
+	logger.Infow("latest prediction projection checked",
+		"missing", drift.Missing,
+		"orphaned", drift.Orphaned,
+		"stale", drift.Stale,
+	)
+
+	if !*fix {
+		if drifted > 0 {
+			logger.Warnw("projection drifted, rerun with -fix to rebuild", "rows", drifted)
+			_ = logger.Sync()
+			os.Exit(1)
+		}
+		return
+	}
+
+	rebuilt, err := queries.RebuildPatientLatestPrediction(ctx)
+	if err != nil {
+		logger.Fatalw("rebuild latest prediction projection failed", "error", err)
+	}
+	marked, err := queries.MarkAllStatsDirty(ctx)
+	if err != nil {
+		logger.Fatalw("cannot mark stats rollups dirty", "error", err)
+	}
+	refreshed, err := stats.NewRefresher(queries).RefreshDirty(ctx)
+	if err != nil {
+		logger.Fatalw("refresh stats rollups failed", "error", err, "refreshed", refreshed)
+	}
+
+	logger.Infow("stats check finished",
+		"rebuilt", rebuilt,
+		"users_marked", marked,
+		"rollups_refreshed", refreshed,
+	)
+}
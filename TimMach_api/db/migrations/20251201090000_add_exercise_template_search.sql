@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS exercise_tags (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Backfill tag vocabulary from existing templates (normalized to lower case).
+UPDATE exercise_templates
+SET tags = ARRAY(
+    SELECT DISTINCT lower(btrim(t))
+    FROM unnest(tags) AS t
+    WHERE btrim(t) <> ''
+);
+
+INSERT INTO exercise_tags (name)
+SELECT DISTINCT unnest(tags) FROM exercise_templates
+ON CONFLICT (name) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_exercise_templates_tags ON exercise_templates USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_exercise_templates_search ON exercise_templates
+    USING GIN (to_tsvector('simple', name || ' ' || description));
+CREATE INDEX IF NOT EXISTS idx_exercise_templates_name_id ON exercise_templates(name, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_exercise_templates_name_id;
+DROP INDEX IF EXISTS idx_exercise_templates_search;
+DROP INDEX IF EXISTS idx_exercise_templates_tags;
+DROP TABLE IF EXISTS exercise_tags;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE exercise_templates ADD COLUMN IF NOT EXISTS slug TEXT;
+
+-- Backfill slug ổn định cho template cũ (thêm hậu tố id để tránh trùng).
+UPDATE exercise_templates
+SET slug = btrim(regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g'), '-') || '-' || id
+WHERE slug IS NULL;
+
+ALTER TABLE exercise_templates ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exercise_templates_slug ON exercise_templates(slug);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_exercise_templates_slug;
+ALTER TABLE exercise_templates DROP COLUMN IF EXISTS slug;
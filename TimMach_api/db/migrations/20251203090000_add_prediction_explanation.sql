@@ -0,0 +1,7 @@
+-- +goose Up
+-- Đóng góp theo từng feature do ML trả về: {"base_value":..., "features":[{feature,value,contribution,share,rank,direction}]}
+ALTER TABLE predictions
+ADD COLUMN IF NOT EXISTS explanation JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE predictions DROP COLUMN IF EXISTS explanation;
@@ -0,0 +1,30 @@
+-- +goose Up
+ALTER TABLE predictions
+ADD COLUMN IF NOT EXISTS model_name TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS model_version TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS feature_schema_hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_predictions_model ON predictions(model_name, model_version);
+
+-- Kết quả chấm lại prediction cũ bằng model khác (để so sánh, không thay thế prediction gốc).
+CREATE TABLE IF NOT EXISTS prediction_rescores (
+    id BIGSERIAL PRIMARY KEY,
+    prediction_id BIGINT NOT NULL REFERENCES predictions(id) ON DELETE CASCADE,
+    model_name TEXT NOT NULL,
+    model_version TEXT NOT NULL,
+    feature_schema_hash TEXT NOT NULL DEFAULT '',
+    probability DOUBLE PRECISION NOT NULL,
+    risk_label TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (prediction_id, model_name, model_version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prediction_rescores_prediction ON prediction_rescores(prediction_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS prediction_rescores;
+DROP INDEX IF EXISTS idx_predictions_model;
+ALTER TABLE predictions
+DROP COLUMN IF EXISTS feature_schema_hash,
+DROP COLUMN IF EXISTS model_version,
+DROP COLUMN IF EXISTS model_name;
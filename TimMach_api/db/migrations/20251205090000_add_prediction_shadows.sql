@@ -0,0 +1,21 @@
+-- +goose Up
+-- Kết quả của model shadow/canary cho cùng input với prediction chính (không ảnh hưởng response).
+-- error != '' khi gọi model shadow thất bại; khi đó probability là NULL.
+CREATE TABLE IF NOT EXISTS prediction_shadows (
+    id BIGSERIAL PRIMARY KEY,
+    prediction_id BIGINT NOT NULL REFERENCES predictions(id) ON DELETE CASCADE,
+    model_name TEXT NOT NULL DEFAULT '',
+    model_version TEXT NOT NULL DEFAULT '',
+    feature_schema_hash TEXT NOT NULL DEFAULT '',
+    probability DOUBLE PRECISION,
+    risk_label TEXT NOT NULL DEFAULT '',
+    latency_ms INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_prediction_shadows_prediction ON prediction_shadows(prediction_id);
+CREATE INDEX IF NOT EXISTS idx_prediction_shadows_created_at ON prediction_shadows(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS prediction_shadows;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Cảnh báo validate lâm sàng (giá trị hợp lệ nhưng bất thường): [{"field","code","message"}]
+ALTER TABLE predictions
+ADD COLUMN IF NOT EXISTS warnings JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE predictions DROP COLUMN IF EXISTS warnings;
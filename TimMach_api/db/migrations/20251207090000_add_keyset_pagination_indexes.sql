@@ -0,0 +1,14 @@
+-- +goose Up
+-- Index cho phân trang keyset (created_at, id) / (name, id) thay cho LIMIT/OFFSET.
+CREATE INDEX IF NOT EXISTS idx_patients_user_created_id ON patients(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_patients_user_name_id ON patients(user_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_predictions_patient_created_id ON predictions(patient_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_ex_rec_patient_created_id ON exercise_recommendations(patient_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_reports_patient_created_id ON reports(patient_id, created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reports_patient_created_id;
+DROP INDEX IF EXISTS idx_ex_rec_patient_created_id;
+DROP INDEX IF EXISTS idx_predictions_patient_created_id;
+DROP INDEX IF EXISTS idx_patients_user_name_id;
+DROP INDEX IF EXISTS idx_patients_user_created_id;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Tìm bệnh nhân theo tên không phân biệt dấu (tiếng Việt) + trigram index cho LIKE '%q%'.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- unaccent() chỉ là STABLE nên không dùng được trong index; bọc lại bằng hàm IMMUTABLE
+-- với dictionary cố định. lower() trước để "Đ" → "đ" → "d".
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_normalize(input TEXT)
+RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, lower(input))
+$$;
+-- +goose StatementEnd
+
+CREATE INDEX IF NOT EXISTS idx_patients_name_search ON patients
+    USING GIN (search_normalize(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_user_dob ON patients(user_id, dob);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_patients_user_dob;
+DROP INDEX IF EXISTS idx_patients_name_search;
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
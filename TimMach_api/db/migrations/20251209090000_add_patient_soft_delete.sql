@@ -0,0 +1,10 @@
+-- +goose Up
+-- Xoá mềm bệnh nhân: deleted_at khác NULL là đã xoá; job retention xoá hẳn sau PATIENT_RETENTION_DAYS.
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_patients_deleted_at ON patients(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_patients_deleted_at;
+ALTER TABLE patients DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Yêu cầu của chủ thể dữ liệu (xuất / xoá toàn bộ dữ liệu bệnh nhân).
+-- patient_id không có FK để bản ghi (tombstone) còn lại sau khi bệnh nhân bị xoá hẳn.
+CREATE TABLE IF NOT EXISTS data_subject_requests (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    patient_id BIGINT NOT NULL,
+    request_type TEXT NOT NULL CHECK (request_type IN ('export', 'erasure')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    archive_path TEXT NOT NULL DEFAULT '',
+    tombstone JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_dsr_user_created_id ON data_subject_requests(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_dsr_patient_id ON data_subject_requests(patient_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS data_subject_requests;
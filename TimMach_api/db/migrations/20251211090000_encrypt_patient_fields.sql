@@ -0,0 +1,37 @@
+-- +goose Up
+-- Mã hoá PII ở tầng ứng dụng (utils/fieldcrypt): patients.name, patients.dob và predictions.raw_features
+-- giữ ciphertext "enc:v1:..."; key_version = 0 là plaintext cũ, chạy `make reencrypt` để mã hoá.
+ALTER TABLE patients ALTER COLUMN dob TYPE TEXT USING to_char(dob, 'YYYY-MM-DD');
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS birth_year SMALLINT;
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS name_index TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS key_version INT NOT NULL DEFAULT 0;
+ALTER TABLE predictions ADD COLUMN IF NOT EXISTS features_key_version INT NOT NULL DEFAULT 0;
+
+-- Năm sinh (không mã hoá) phục vụ lọc theo tuổi khi dob đã mã hoá.
+UPDATE patients SET birth_year = left(dob, 4)::smallint WHERE dob ~ '^\d{4}-';
+
+-- Index trên plaintext không còn dùng được khi cột chứa ciphertext.
+DROP INDEX IF EXISTS idx_patients_name_search;
+DROP INDEX IF EXISTS idx_patients_user_name_id;
+DROP INDEX IF EXISTS idx_patients_user_dob;
+
+CREATE INDEX IF NOT EXISTS idx_patients_name_index ON patients USING GIN (name_index);
+CREATE INDEX IF NOT EXISTS idx_patients_user_birth_year ON patients(user_id, birth_year);
+CREATE INDEX IF NOT EXISTS idx_patients_key_version ON patients(key_version, id);
+CREATE INDEX IF NOT EXISTS idx_predictions_features_key_version ON predictions(features_key_version, id);
+
+-- +goose Down
+-- Chỉ khôi phục được khi dữ liệu đã giải mã (key_version = 0).
+DROP INDEX IF EXISTS idx_predictions_features_key_version;
+DROP INDEX IF EXISTS idx_patients_key_version;
+DROP INDEX IF EXISTS idx_patients_user_birth_year;
+DROP INDEX IF EXISTS idx_patients_name_index;
+ALTER TABLE predictions DROP COLUMN IF EXISTS features_key_version;
+ALTER TABLE patients DROP COLUMN IF EXISTS key_version;
+ALTER TABLE patients DROP COLUMN IF EXISTS name_index;
+ALTER TABLE patients DROP COLUMN IF EXISTS birth_year;
+ALTER TABLE patients ALTER COLUMN dob TYPE DATE USING dob::date;
+CREATE INDEX IF NOT EXISTS idx_patients_user_dob ON patients(user_id, dob);
+CREATE INDEX IF NOT EXISTS idx_patients_user_name_id ON patients(user_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_patients_name_search ON patients
+    USING GIN (search_normalize(name) gin_trgm_ops);
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Kết cục lâm sàng của bệnh nhân (chẩn đoán bệnh tim mạch hoặc lần theo dõi xác nhận không bệnh),
+-- dùng để gắn nhãn predictions và đo calibration/discrimination của model.
+CREATE TABLE IF NOT EXISTS patient_outcomes (
+    id BIGSERIAL PRIMARY KEY,
+    patient_id BIGINT NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL CHECK (event_type IN ('diagnosis', 'negative_followup')),
+    condition TEXT NOT NULL DEFAULT '',
+    occurred_on DATE NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('clinician', 'ehr', 'registry', 'self_report')),
+    recorded_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_patient_outcomes_patient_created_id ON patient_outcomes(patient_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_patient_outcomes_patient_occurred ON patient_outcomes(patient_id, occurred_on);
+
+-- Phòng khám của user (đồng bộ từ claim "clinic" của Keycloak) để tách metrics theo phòng khám.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS clinic TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS clinic;
+DROP TABLE IF EXISTS patient_outcomes;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- Projection prediction mới nhất của từng bệnh nhân, cập nhật cùng câu lệnh CreatePrediction
+-- để stats và danh sách bệnh nhân không phải DISTINCT ON toàn bộ predictions mỗi request.
+CREATE TABLE IF NOT EXISTS patient_latest_prediction (
+    patient_id BIGINT PRIMARY KEY REFERENCES patients(id) ON DELETE CASCADE,
+    prediction_id BIGINT NOT NULL REFERENCES predictions(id) ON DELETE CASCADE,
+    probability DOUBLE PRECISION NOT NULL,
+    risk_label TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO patient_latest_prediction (patient_id, prediction_id, probability, risk_label, created_at)
+SELECT DISTINCT ON (patient_id) patient_id, id, probability, risk_label, created_at
+FROM predictions
+ORDER BY patient_id, created_at DESC, id DESC
+ON CONFLICT (patient_id) DO NOTHING;
+
+-- Số liệu GET /stats theo user, do job nền làm mới cho các user nằm trong stats_dirty_users.
+CREATE TABLE IF NOT EXISTS user_stats_rollups (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    total_patients BIGINT NOT NULL DEFAULT 0,
+    total_predictions BIGINT NOT NULL DEFAULT 0,
+    high_risk BIGINT NOT NULL DEFAULT 0,
+    medium_risk BIGINT NOT NULL DEFAULT 0,
+    low_risk BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- User có bệnh nhân/prediction thay đổi từ lần làm mới rollup gần nhất.
+CREATE TABLE IF NOT EXISTS stats_dirty_users (
+    user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stats_dirty_users_marked_at ON stats_dirty_users(marked_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS stats_dirty_users;
+DROP TABLE IF EXISTS user_stats_rollups;
+DROP TABLE IF EXISTS patient_latest_prediction;
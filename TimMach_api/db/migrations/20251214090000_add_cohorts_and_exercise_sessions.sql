@@ -0,0 +1,34 @@
+-- +goose Up
+-- Buổi tập bệnh nhân đã thực hiện (bác sĩ hoặc bệnh nhân ghi nhận), dùng để đo mức tuân thủ
+-- so với kế hoạch trong exercise_recommendations.
+CREATE TABLE IF NOT EXISTS exercise_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    patient_id BIGINT NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    recommendation_id BIGINT REFERENCES exercise_recommendations(id) ON DELETE SET NULL,
+    performed_on DATE NOT NULL,
+    duration_min INT NOT NULL CHECK (duration_min > 0),
+    notes TEXT NOT NULL DEFAULT '',
+    recorded_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercise_sessions_patient_created_id ON exercise_sessions(patient_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_exercise_sessions_patient_performed ON exercise_sessions(patient_id, performed_on);
+
+-- Cohort là bộ lọc bệnh nhân đã lưu (JSON, xem modules/cohorts.Filter) để so sánh các nhóm.
+CREATE TABLE IF NOT EXISTS cohorts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    filter JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cohorts_user_created_id ON cohorts(user_id, created_at, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS cohorts;
+DROP TABLE IF EXISTS exercise_sessions;
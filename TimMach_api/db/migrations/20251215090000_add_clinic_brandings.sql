@@ -0,0 +1,17 @@
+-- +goose Up
+-- Branding theo phòng khám (khớp users.clinic) cho email và báo cáo PDF;
+-- phòng khám chưa cấu hình dùng CLINIC_NAME mặc định.
+CREATE TABLE IF NOT EXISTS clinic_brandings (
+    clinic TEXT PRIMARY KEY CHECK (clinic <> ''),
+    display_name TEXT NOT NULL,
+    logo BYTEA,
+    logo_mime TEXT NOT NULL DEFAULT '',
+    primary_color TEXT NOT NULL DEFAULT '#dc2626',
+    default_locale TEXT NOT NULL DEFAULT 'vi',
+    footer TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS clinic_brandings;
@@ -0,0 +1,46 @@
+-- +goose Up
+-- Outbox email: request chỉ ghi vào bảng này, job nền (modules/emails.Sender) gửi SMTP, retry với backoff.
+-- status: queued → sending → sent | failed (hết lượt retry) | bounced (SMTP từ chối vĩnh viễn, 5xx).
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    patient_id BIGINT REFERENCES patients(id) ON DELETE CASCADE,
+    report_id BIGINT REFERENCES reports(id) ON DELETE SET NULL,
+    to_address TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body_text TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    -- []mailer.Attachment dạng JSON (content base64), gồm cả logo inline.
+    attachments JSONB NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'sending', 'sent', 'failed', 'bounced')),
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(next_attempt_at, id) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_email_outbox_sending ON email_outbox(locked_at) WHERE status = 'sending';
+CREATE INDEX IF NOT EXISTS idx_email_outbox_patient ON email_outbox(patient_id);
+CREATE INDEX IF NOT EXISTS idx_email_outbox_report ON email_outbox(report_id);
+
+-- Kết quả từng lần gửi (sent | failed | bounced) để truy vết.
+CREATE TABLE IF NOT EXISTS email_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    outbox_id BIGINT NOT NULL REFERENCES email_outbox(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed', 'bounced')),
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_attempts_outbox ON email_attempts(outbox_id, attempt);
+
+-- +goose Down
+DROP TABLE IF EXISTS email_attempts;
+DROP TABLE IF EXISTS email_outbox;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Mỗi địa chỉ (to/cc/bcc) là một dòng outbox riêng để theo dõi trạng thái từng người nhận;
+-- header_to/header_cc là header hiển thị chung của email (bcc không xuất hiện trong header).
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS recipient_role TEXT NOT NULL DEFAULT 'to' CHECK (recipient_role IN ('to', 'cc', 'bcc')),
+    ADD COLUMN IF NOT EXISTS header_to TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS header_cc TEXT[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE email_outbox
+    DROP COLUMN IF EXISTS header_cc,
+    DROP COLUMN IF EXISTS header_to,
+    DROP COLUMN IF EXISTS recipient_role;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Mã hoá PDF báo cáo gửi qua email: none | dob (mật khẩu là ngày sinh bệnh nhân DDMMYYYY)
+-- | code (mã dùng một lần, trả cho bác sĩ để gửi riêng, không lưu lại).
+ALTER TABLE clinic_brandings
+    ADD COLUMN IF NOT EXISTS pdf_protection TEXT NOT NULL DEFAULT 'none' CHECK (pdf_protection IN ('none', 'dob', 'code'));
+
+-- Ghi lại file đính kèm của email đã được mã hoá theo cách nào.
+ALTER TABLE email_outbox
+    ADD COLUMN IF NOT EXISTS pdf_protection TEXT NOT NULL DEFAULT 'none' CHECK (pdf_protection IN ('none', 'dob', 'code'));
+
+-- +goose Down
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS pdf_protection;
+ALTER TABLE clinic_brandings DROP COLUMN IF EXISTS pdf_protection;
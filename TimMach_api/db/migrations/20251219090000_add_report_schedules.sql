@@ -0,0 +1,51 @@
+-- +goose Up
+-- Lịch gửi định kỳ (modules/schedules), job nền chạy trong tiến trình API và khoá từng lịch bằng advisory lock.
+-- kind = report: gửi báo cáo PDF của patient_id tới to_addresses/cc_addresses;
+-- kind = reminder: nhắc khi bệnh nhân chưa có prediction trong inactive_months tháng
+-- (patient_id NULL = mọi bệnh nhân của user, to_addresses rỗng = gửi cho email của user).
+-- cron: biểu thức 5 trường hoặc @daily/@weekly/@monthly, tuỳ chọn tiền tố "CRON_TZ=Asia/Ho_Chi_Minh ".
+CREATE TABLE IF NOT EXISTS report_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    patient_id BIGINT REFERENCES patients(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('report', 'reminder')),
+    cron TEXT NOT NULL,
+    to_addresses TEXT[] NOT NULL DEFAULT '{}',
+    cc_addresses TEXT[] NOT NULL DEFAULT '{}',
+    locale TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    inactive_months INT NOT NULL DEFAULT 3 CHECK (inactive_months > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (kind = 'reminder' OR patient_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedules_due ON report_schedules(next_run_at, id) WHERE enabled;
+CREATE INDEX IF NOT EXISTS idx_report_schedules_user_created ON report_schedules(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_report_schedules_patient ON report_schedules(patient_id);
+
+-- Mỗi lần chạy của một lịch; UNIQUE (schedule_id, scheduled_for) để một mốc chỉ chạy một lần dù có nhiều replica.
+-- status: running → succeeded | skipped (không có gì để gửi) | failed.
+CREATE TABLE IF NOT EXISTS report_schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'skipped', 'failed')),
+    report_id BIGINT REFERENCES reports(id) ON DELETE SET NULL,
+    -- email_outbox.id đã đưa vào outbox và bệnh nhân được nhắc (reminder).
+    email_ids BIGINT[] NOT NULL DEFAULT '{}',
+    patient_ids BIGINT[] NOT NULL DEFAULT '{}',
+    detail TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    UNIQUE (schedule_id, scheduled_for)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_schedule_runs_schedule ON report_schedule_runs(schedule_id, scheduled_for DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS report_schedule_runs;
+DROP TABLE IF EXISTS report_schedules;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- search_normalize() chỉ phục vụ trigram index trên tên plaintext, đã bỏ khi mã hoá tên
+-- (tìm tên giờ qua patients.name_index).
+DROP FUNCTION IF EXISTS search_normalize(TEXT);
+
+-- +goose Down
+-- Khôi phục hàm để Down của 20251211090000_encrypt_patient_fields dựng lại được idx_patients_name_search.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_normalize(input TEXT)
+RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE PARALLEL SAFE STRICT
+AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, lower(input))
+$$;
+-- +goose StatementEnd
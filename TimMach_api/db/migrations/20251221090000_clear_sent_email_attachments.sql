@@ -0,0 +1,10 @@
+-- +goose Up
+-- File đính kèm (PDF báo cáo) chỉ cần tới khi gửi xong; bỏ bản sao còn lại ở các email đã kết thúc.
+-- Từ nay FinishEmailAttempt tự xoá khi chuyển sang sent/failed/bounced.
+UPDATE email_outbox
+SET attachments = '[]'::jsonb
+WHERE status IN ('sent', 'failed', 'bounced')
+  AND attachments <> '[]'::jsonb;
+
+-- +goose Down
+-- Không khôi phục được file đính kèm đã xoá.
@@ -0,0 +1,19 @@
+-- name: ListPredictionsForAnalytics :many
+-- Dữ liệu cho export huấn luyện lại model và metrics calibration: mọi prediction của bệnh nhân chưa xoá, keyset theo id.
+SELECT
+    pr.id,
+    pr.patient_id,
+    pr.raw_features,
+    pr.probability,
+    pr.model_version,
+    pr.created_at,
+    u.clinic
+FROM predictions pr
+JOIN patients pa ON pa.id = pr.patient_id
+JOIN users u ON u.id = pa.user_id
+WHERE pa.deleted_at IS NULL
+  AND (sqlc.narg('from_time')::timestamptz IS NULL OR pr.created_at >= sqlc.narg('from_time')::timestamptz)
+  AND (sqlc.narg('to_time')::timestamptz IS NULL OR pr.created_at < sqlc.narg('to_time')::timestamptz)
+  AND pr.id > sqlc.arg('after_id')
+ORDER BY pr.id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,34 @@
+-- name: GetClinicBranding :one
+SELECT * FROM clinic_brandings
+WHERE clinic = $1
+LIMIT 1;
+
+-- name: GetClinicBrandingByUser :one
+-- Branding của phòng khám mà user thuộc về (users.clinic).
+SELECT b.clinic, b.display_name, b.logo, b.logo_mime, b.primary_color, b.default_locale, b.footer, b.created_at, b.updated_at, b.pdf_protection
+FROM clinic_brandings b
+JOIN users u ON u.clinic = b.clinic
+WHERE u.id = $1
+LIMIT 1;
+
+-- name: ListClinicBrandings :many
+SELECT * FROM clinic_brandings
+ORDER BY clinic;
+
+-- name: UpsertClinicBranding :one
+INSERT INTO clinic_brandings (clinic, display_name, logo, logo_mime, primary_color, default_locale, footer, pdf_protection)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (clinic) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    logo = EXCLUDED.logo,
+    logo_mime = EXCLUDED.logo_mime,
+    primary_color = EXCLUDED.primary_color,
+    default_locale = EXCLUDED.default_locale,
+    footer = EXCLUDED.footer,
+    pdf_protection = EXCLUDED.pdf_protection,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteClinicBranding :execrows
+DELETE FROM clinic_brandings
+WHERE clinic = $1;
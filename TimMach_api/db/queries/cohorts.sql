@@ -0,0 +1,89 @@
+-- name: CreateCohort :one
+INSERT INTO cohorts (user_id, name, description, filter)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetCohortByID :one
+SELECT * FROM cohorts
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListCohortsByUserDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM cohorts
+WHERE user_id = sqlc.arg('user_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListCohortsByUserAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM cohorts
+WHERE user_id = sqlc.arg('user_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateCohort :one
+UPDATE cohorts
+SET
+    name = $2,
+    description = $3,
+    filter = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCohort :exec
+DELETE FROM cohorts
+WHERE id = $1;
+
+-- name: ListCohortCandidates :many
+-- Bệnh nhân chưa xoá của user kèm prediction mới nhất (projection), factors của nó và số buổi tập đã ghi nhận.
+SELECT
+    pa.id,
+    pa.gender,
+    pa.birth_year,
+    pa.created_at,
+    l.risk_label AS latest_risk_label,
+    l.probability AS latest_probability,
+    COALESCE(
+        (SELECT array_agg(DISTINCT f->>'field' ORDER BY f->>'field')
+         FROM predictions pr
+         CROSS JOIN LATERAL jsonb_array_elements(
+             CASE WHEN jsonb_typeof(pr.factors) = 'array' THEN pr.factors ELSE '[]'::jsonb END
+         ) f
+         WHERE pr.id = l.prediction_id
+           AND f->>'field' IS NOT NULL),
+        '{}'
+    )::text[] AS latest_factor_fields,
+    (SELECT COUNT(*) FROM exercise_sessions s WHERE s.patient_id = pa.id) AS exercise_sessions
+FROM patients pa
+LEFT JOIN patient_latest_prediction l ON l.patient_id = pa.id
+WHERE pa.user_id = $1
+  AND pa.deleted_at IS NULL
+ORDER BY pa.id;
+
+-- name: ListPredictionPointsByPatients :many
+SELECT patient_id, probability, created_at
+FROM predictions
+WHERE patient_id = ANY(sqlc.arg('patient_ids')::bigint[])
+  AND created_at >= sqlc.arg('from_time')
+  AND created_at < sqlc.arg('to_time')
+ORDER BY patient_id, created_at, id;
+
+-- name: ListLatestRecommendationPlans :many
+-- Kế hoạch tập gần nhất trước to_time của từng bệnh nhân (để tính số buổi được giao mỗi tuần kể từ created_at).
+SELECT DISTINCT ON (patient_id) patient_id, plan, created_at
+FROM exercise_recommendations
+WHERE patient_id = ANY(sqlc.arg('patient_ids')::bigint[])
+  AND created_at < sqlc.arg('to_time')
+ORDER BY patient_id, created_at DESC, id DESC;
+
+-- name: CountExerciseSessionsByPatients :many
+SELECT patient_id, COUNT(*) AS sessions
+FROM exercise_sessions
+WHERE patient_id = ANY(sqlc.arg('patient_ids')::bigint[])
+  AND performed_on >= sqlc.arg('from_date')
+  AND performed_on < sqlc.arg('to_date')
+GROUP BY patient_id;
@@ -0,0 +1,101 @@
+-- name: CreateDataRequest :one
+INSERT INTO data_subject_requests (user_id, patient_id, request_type)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: CompleteDataRequest :one
+UPDATE data_subject_requests
+SET
+    status = $2,
+    archive_path = $3,
+    tombstone = $4,
+    error = $5,
+    completed_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDataRequestByID :one
+SELECT * FROM data_subject_requests
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListDataRequestsByUserDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM data_subject_requests
+WHERE user_id = sqlc.arg('user_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListDataRequestsByUserAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM data_subject_requests
+WHERE user_id = sqlc.arg('user_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListDataRequestArchivesByPatient :many
+SELECT archive_path FROM data_subject_requests
+WHERE patient_id = $1
+  AND archive_path <> '';
+
+-- name: ClearDataRequestArchivesByPatient :exec
+UPDATE data_subject_requests
+SET archive_path = ''
+WHERE patient_id = $1
+  AND archive_path <> '';
+
+-- name: CountPatientData :one
+SELECT
+    (SELECT COUNT(*) FROM patients p WHERE p.id = sqlc.arg('patient_id')) AS patients,
+    (SELECT COUNT(*) FROM predictions pr WHERE pr.patient_id = sqlc.arg('patient_id')) AS predictions,
+    (SELECT COUNT(*) FROM prediction_rescores rs
+        JOIN predictions pr ON pr.id = rs.prediction_id
+        WHERE pr.patient_id = sqlc.arg('patient_id')) AS rescores,
+    (SELECT COUNT(*) FROM prediction_shadows s
+        JOIN predictions pr ON pr.id = s.prediction_id
+        WHERE pr.patient_id = sqlc.arg('patient_id')) AS shadows,
+    (SELECT COUNT(*) FROM exercise_recommendations er WHERE er.patient_id = sqlc.arg('patient_id')) AS recommendations,
+    (SELECT COUNT(*) FROM reports r WHERE r.patient_id = sqlc.arg('patient_id')) AS reports,
+    (SELECT COUNT(*) FROM patient_outcomes o WHERE o.patient_id = sqlc.arg('patient_id')) AS outcomes,
+    (SELECT COUNT(*) FROM exercise_sessions es WHERE es.patient_id = sqlc.arg('patient_id')) AS exercise_sessions,
+    (SELECT COUNT(*) FROM email_outbox eo WHERE eo.patient_id = sqlc.arg('patient_id')) AS emails,
+    (SELECT COUNT(*) FROM report_schedules rs WHERE rs.patient_id = sqlc.arg('patient_id')) AS schedules;
+
+-- name: ExportPredictionsByPatient :many
+SELECT * FROM predictions
+WHERE patient_id = $1
+ORDER BY created_at, id;
+
+-- name: ExportPredictionRescoresByPatient :many
+SELECT rs.* FROM prediction_rescores rs
+JOIN predictions pr ON pr.id = rs.prediction_id
+WHERE pr.patient_id = $1
+ORDER BY rs.created_at, rs.id;
+
+-- name: ExportPredictionShadowsByPatient :many
+SELECT s.* FROM prediction_shadows s
+JOIN predictions pr ON pr.id = s.prediction_id
+WHERE pr.patient_id = $1
+ORDER BY s.created_at, s.id;
+
+-- name: ExportOutcomesByPatient :many
+SELECT * FROM patient_outcomes
+WHERE patient_id = $1
+ORDER BY occurred_on, id;
+
+-- name: ExportExerciseSessionsByPatient :many
+SELECT * FROM exercise_sessions
+WHERE patient_id = $1
+ORDER BY performed_on, id;
+
+-- name: ExportRecommendationsByPatient :many
+SELECT * FROM exercise_recommendations
+WHERE patient_id = $1
+ORDER BY created_at, id;
+
+-- name: ExportReportsByPatient :many
+SELECT * FROM reports
+WHERE patient_id = $1
+ORDER BY created_at, id;
@@ -0,0 +1,129 @@
+-- name: EnqueueEmails :many
+-- Ghi một dòng outbox cho mỗi địa chỉ (to_addresses/roles cùng độ dài, theo thứ tự); nếu gắn report thì thêm
+-- recipient trạng thái queued cho từng địa chỉ vào reports.recipients trong cùng câu lệnh.
+WITH inserted AS (
+    INSERT INTO email_outbox (
+        user_id, patient_id, report_id, to_address, recipient_role, header_to, header_cc,
+        subject, body_text, body_html, attachments, pdf_protection, max_attempts
+    )
+    SELECT
+        sqlc.arg('user_id')::text,
+        sqlc.narg('patient_id')::bigint,
+        sqlc.narg('report_id')::bigint,
+        rcpt.address,
+        rcpt.role,
+        sqlc.arg('header_to')::text[],
+        sqlc.arg('header_cc')::text[],
+        sqlc.arg('subject')::text,
+        sqlc.arg('body_text')::text,
+        sqlc.arg('body_html')::text,
+        sqlc.arg('attachments')::jsonb,
+        sqlc.arg('pdf_protection')::text,
+        sqlc.arg('max_attempts')::int
+    FROM unnest(sqlc.arg('to_addresses')::text[], sqlc.arg('roles')::text[]) WITH ORDINALITY AS rcpt(address, role, ord)
+    ORDER BY rcpt.ord
+    RETURNING id, user_id, patient_id, report_id, to_address, subject, body_text, body_html, attachments, status, attempts, max_attempts, next_attempt_at, locked_at, last_error, sent_at, created_at, updated_at, recipient_role, header_to, header_cc, pdf_protection
+), recipient AS (
+    UPDATE reports r
+    SET recipients = r.recipients || COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'email', i.to_address,
+            'role', i.recipient_role,
+            'status', i.status,
+            'outbox_id', i.id,
+            'queued_at', i.created_at,
+            'attempts', i.attempts,
+            'pdf_protection', i.pdf_protection
+        ) ORDER BY i.id)
+        FROM inserted i
+    ), '[]'::jsonb)
+    WHERE r.id = sqlc.narg('report_id')::bigint
+)
+SELECT id, user_id, patient_id, report_id, to_address, subject, body_text, body_html, attachments, status, attempts, max_attempts, next_attempt_at, locked_at, last_error, sent_at, created_at, updated_at, recipient_role, header_to, header_cc, pdf_protection
+FROM inserted
+ORDER BY id;
+
+-- name: ClaimDueEmails :many
+-- Lấy các email đến hạn (hoặc kẹt ở sending quá 10 phút do worker dừng giữa chừng) và khoá bằng status sending.
+UPDATE email_outbox
+SET status = 'sending',
+    attempts = attempts + 1,
+    locked_at = NOW(),
+    updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM email_outbox
+    WHERE (status = 'queued' AND next_attempt_at <= NOW())
+       OR (status = 'sending' AND locked_at < NOW() - INTERVAL '10 minutes')
+    ORDER BY next_attempt_at, id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RefreshEmailClaim :one
+-- Gia hạn claim ngay trước khi gửi; không có dòng nào nghĩa là claim đã quá hạn và worker khác đã lấy lại email.
+UPDATE email_outbox
+SET locked_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+  AND status = 'sending'
+  AND locked_at = $2
+RETURNING locked_at;
+
+-- name: FinishEmailAttempt :one
+-- Ghi kết quả một lần gửi: cập nhật outbox (status queued = chờ retry tại next_attempt_at), thêm email_attempts
+-- và đồng bộ trạng thái recipient tương ứng trong reports.recipients. Chỉ ghi khi worker còn giữ claim
+-- (status sending, locked_at = claimed_at); mất claim thì không có dòng nào.
+WITH updated AS (
+    UPDATE email_outbox
+    SET status = sqlc.arg('status')::text,
+        next_attempt_at = COALESCE(sqlc.narg('next_attempt_at')::timestamptz, next_attempt_at),
+        last_error = sqlc.arg('last_error')::text,
+        sent_at = CASE WHEN sqlc.arg('status')::text = 'sent' THEN NOW() ELSE sent_at END,
+        -- Xong (sent/failed/bounced) thì bỏ file đính kèm, không giữ bản sao PDF báo cáo trong outbox.
+        attachments = CASE WHEN sqlc.arg('status')::text = 'queued' THEN attachments ELSE '[]'::jsonb END,
+        locked_at = NULL,
+        updated_at = NOW()
+    WHERE id = sqlc.arg('id')
+      AND status = 'sending'
+      AND locked_at = sqlc.arg('claimed_at')::timestamptz
+    RETURNING id, user_id, patient_id, report_id, to_address, subject, body_text, body_html, attachments, status, attempts, max_attempts, next_attempt_at, locked_at, last_error, sent_at, created_at, updated_at, recipient_role, header_to, header_cc, pdf_protection
+), attempt AS (
+    INSERT INTO email_attempts (outbox_id, attempt, status, error, started_at)
+    SELECT u.id, u.attempts, sqlc.arg('attempt_status')::text, u.last_error, sqlc.arg('started_at')::timestamptz
+    FROM updated u
+), recipient AS (
+    UPDATE reports r
+    SET recipients = (
+        SELECT COALESCE(jsonb_agg(
+            CASE WHEN (x.e->>'outbox_id')::bigint = u.id
+                THEN x.e || jsonb_build_object('status', u.status, 'attempts', u.attempts, 'error', u.last_error, 'sent_at', u.sent_at)
+                ELSE x.e
+            END ORDER BY x.ord), '[]'::jsonb)
+        FROM jsonb_array_elements(r.recipients) WITH ORDINALITY AS x(e, ord)
+    )
+    FROM updated u
+    WHERE r.id = u.report_id
+)
+SELECT id, user_id, patient_id, report_id, to_address, subject, body_text, body_html, attachments, status, attempts, max_attempts, next_attempt_at, locked_at, last_error, sent_at, created_at, updated_at, recipient_role, header_to, header_cc, pdf_protection
+FROM updated;
+
+-- name: CancelQueuedReportEmails :execrows
+-- Email chưa gửi của một báo cáo sắp bị xoá: đánh dấu failed và bỏ file đính kèm.
+UPDATE email_outbox
+SET status = 'failed',
+    attachments = '[]'::jsonb,
+    last_error = 'report deleted',
+    updated_at = NOW()
+WHERE report_id = $1
+  AND status = 'queued';
+
+-- name: GetEmailOutboxByID :one
+SELECT * FROM email_outbox
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListEmailAttempts :many
+SELECT * FROM email_attempts
+WHERE outbox_id = $1
+ORDER BY attempt, id;
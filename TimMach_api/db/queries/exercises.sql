@@ -1,9 +1,32 @@
 -- name: CreateExerciseTemplate :one
 INSERT INTO exercise_templates (
-    name, intensity, description, duration_min, freq_per_week, target_risk_level, tags
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
+    slug, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
+-- name: UpdateExerciseTemplateBySlug :one
+UPDATE exercise_templates
+SET
+    name = $2,
+    intensity = $3,
+    description = $4,
+    duration_min = $5,
+    freq_per_week = $6,
+    target_risk_level = $7,
+    tags = $8
+WHERE slug = $1
+RETURNING *;
+
+-- name: UpdateExerciseTemplateSlug :exec
+UPDATE exercise_templates
+SET slug = $2
+WHERE id = $1;
+
+-- name: GetExerciseTemplateBySlug :one
+SELECT * FROM exercise_templates
+WHERE slug = $1
+LIMIT 1;
+
 -- name: ListExerciseTemplates :many
 SELECT * FROM exercise_templates
 ORDER BY name ASC;
@@ -14,13 +37,109 @@ INSERT INTO exercise_recommendations (
 ) VALUES ($1, $2, $3)
 RETURNING *;
 
--- name: ListExerciseRecommendationsByPatient :many
+-- name: ListExerciseRecommendationsByPatientDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
 SELECT * FROM exercise_recommendations
-WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListExerciseRecommendationsByPatientAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM exercise_recommendations
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
 
 -- name: GetExerciseRecommendationByPrediction :one
 SELECT * FROM exercise_recommendations
 WHERE prediction_id = $1
 LIMIT 1;
+
+-- name: SearchExerciseTemplatesDesc :many
+-- Keyset giảm dần theo id (template không có created_at); trang đầu truyền mốc của pagination.Request.CursorID.
+SELECT * FROM exercise_templates
+WHERE (sqlc.narg('intensity')::text IS NULL OR intensity = sqlc.narg('intensity')::text)
+  AND (sqlc.narg('target_risk_level')::text IS NULL OR target_risk_level = sqlc.narg('target_risk_level')::text)
+  AND (sqlc.narg('tags_any')::text[] IS NULL OR tags && sqlc.narg('tags_any')::text[])
+  AND (sqlc.narg('tags_all')::text[] IS NULL OR tags @> sqlc.narg('tags_all')::text[])
+  AND (sqlc.narg('min_duration')::int IS NULL OR duration_min >= sqlc.narg('min_duration')::int)
+  AND (sqlc.narg('max_duration')::int IS NULL OR duration_min <= sqlc.narg('max_duration')::int)
+  AND (sqlc.narg('min_freq')::int IS NULL OR freq_per_week >= sqlc.narg('min_freq')::int)
+  AND (sqlc.narg('max_freq')::int IS NULL OR freq_per_week <= sqlc.narg('max_freq')::int)
+  AND (
+    sqlc.narg('query')::text IS NULL
+    OR to_tsvector('simple', name || ' ' || description) @@ websearch_to_tsquery('simple', sqlc.narg('query')::text)
+  )
+  AND id < sqlc.arg('cursor_id')::bigint
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchExerciseTemplatesAsc :many
+-- Keyset tăng dần theo id (template không có created_at); trang đầu truyền mốc của pagination.Request.CursorID.
+SELECT * FROM exercise_templates
+WHERE (sqlc.narg('intensity')::text IS NULL OR intensity = sqlc.narg('intensity')::text)
+  AND (sqlc.narg('target_risk_level')::text IS NULL OR target_risk_level = sqlc.narg('target_risk_level')::text)
+  AND (sqlc.narg('tags_any')::text[] IS NULL OR tags && sqlc.narg('tags_any')::text[])
+  AND (sqlc.narg('tags_all')::text[] IS NULL OR tags @> sqlc.narg('tags_all')::text[])
+  AND (sqlc.narg('min_duration')::int IS NULL OR duration_min >= sqlc.narg('min_duration')::int)
+  AND (sqlc.narg('max_duration')::int IS NULL OR duration_min <= sqlc.narg('max_duration')::int)
+  AND (sqlc.narg('min_freq')::int IS NULL OR freq_per_week >= sqlc.narg('min_freq')::int)
+  AND (sqlc.narg('max_freq')::int IS NULL OR freq_per_week <= sqlc.narg('max_freq')::int)
+  AND (
+    sqlc.narg('query')::text IS NULL
+    OR to_tsvector('simple', name || ' ' || description) @@ websearch_to_tsquery('simple', sqlc.narg('query')::text)
+  )
+  AND id > sqlc.arg('cursor_id')::bigint
+ORDER BY id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateExerciseTag :one
+INSERT INTO exercise_tags (name, description)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ListExerciseTags :many
+SELECT * FROM exercise_tags
+ORDER BY name ASC;
+
+-- name: ListExerciseTagsByNames :many
+SELECT * FROM exercise_tags
+WHERE name = ANY(sqlc.arg('names')::text[])
+ORDER BY name ASC;
+
+-- name: CountTemplatesByTag :one
+SELECT COUNT(*) FROM exercise_templates
+WHERE sqlc.arg('tag')::text = ANY(tags);
+
+-- name: DeleteExerciseTag :exec
+DELETE FROM exercise_tags
+WHERE name = $1;
+
+-- name: EnsureExerciseTag :exec
+INSERT INTO exercise_tags (name, description)
+VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING;
+
+-- name: CreateExerciseSession :one
+INSERT INTO exercise_sessions (patient_id, recommendation_id, performed_on, duration_min, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListExerciseSessionsByPatientDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM exercise_sessions
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListExerciseSessionsByPatientAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM exercise_sessions
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
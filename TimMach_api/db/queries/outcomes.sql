@@ -0,0 +1,37 @@
+-- name: CreatePatientOutcome :one
+INSERT INTO patient_outcomes (patient_id, event_type, condition, occurred_on, source, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPatientOutcomeByID :one
+SELECT * FROM patient_outcomes
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListPatientOutcomesDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM patient_outcomes
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPatientOutcomesAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM patient_outcomes
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: DeletePatientOutcome :exec
+DELETE FROM patient_outcomes
+WHERE id = $1;
+
+-- name: ListOutcomeEventsForAnalytics :many
+-- Toàn bộ outcome của bệnh nhân chưa xoá, để gắn nhãn predictions khi tính metrics / export.
+SELECT o.patient_id, o.event_type, o.occurred_on
+FROM patient_outcomes o
+JOIN patients pa ON pa.id = o.patient_id
+WHERE pa.deleted_at IS NULL
+ORDER BY o.patient_id, o.occurred_on;
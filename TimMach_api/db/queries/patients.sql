@@ -1,34 +1,53 @@
 -- name: CreatePatient :one
-INSERT INTO patients (user_id, name, gender, dob)
-VALUES ($1, $2, $3, $4)
-RETURNING *;
+WITH inserted AS (
+    INSERT INTO patients (user_id, name, gender, dob, birth_year, name_index, key_version)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM inserted
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT * FROM inserted;
 
 -- name: GetPatientByID :one
 SELECT * FROM patients
 WHERE id = $1
+  AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetAnyPatientByID :one
+SELECT * FROM patients
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetDeletedPatientByID :one
+SELECT * FROM patients
+WHERE id = $1
+  AND deleted_at IS NOT NULL
 LIMIT 1;
 
 -- name: ListPatientsByUser :many
 SELECT * FROM patients
 WHERE user_id = $1
+  AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
--- name: ListPatientsWithLatestPrediction :many
+-- name: ListPatientsWithLatestPredictionDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
 WITH pa AS (
     SELECT *
     FROM patients
-    WHERE user_id = $1
-),
-latest AS (
-    SELECT DISTINCT ON (pr.patient_id)
-        pr.patient_id,
-        pr.probability,
-        pr.risk_label,
-        pr.created_at
-    FROM predictions pr
-    JOIN pa ON pa.id = pr.patient_id
-    ORDER BY pr.patient_id, pr.created_at DESC
+    WHERE user_id = sqlc.arg('user_id')
+      AND deleted_at IS NULL
+      AND (sqlc.narg('name_tokens')::text[] IS NULL OR name_index @> sqlc.narg('name_tokens')::text[])
+      AND (sqlc.narg('gender')::smallint IS NULL OR gender = sqlc.narg('gender')::smallint)
+      AND (sqlc.narg('min_age')::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year >= sqlc.narg('min_age')::int)
+      AND (sqlc.narg('max_age')::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year <= sqlc.narg('max_age')::int)
 )
 SELECT
     p.id,
@@ -41,23 +60,149 @@ SELECT
     l.risk_label AS latest_risk_label,
     l.created_at AS latest_prediction_at
 FROM pa p
-LEFT JOIN latest l ON l.patient_id = p.id
-WHERE ($4 = '' OR COALESCE(l.risk_label, 'none') = $4)
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3;
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+WHERE (sqlc.arg('risk')::text = '' OR COALESCE(l.risk_label, 'none') = sqlc.arg('risk')::text)
+  AND (sqlc.narg('predicted_from')::timestamptz IS NULL OR l.created_at >= sqlc.narg('predicted_from')::timestamptz)
+  AND (sqlc.narg('predicted_to')::timestamptz IS NULL OR l.created_at < sqlc.narg('predicted_to')::timestamptz)
+  AND (sqlc.narg('min_probability')::float8 IS NULL OR l.probability >= sqlc.narg('min_probability')::float8)
+  AND (sqlc.narg('max_probability')::float8 IS NULL OR l.probability <= sqlc.narg('max_probability')::float8)
+  AND (p.created_at, p.id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPatientsWithLatestPredictionAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+WITH pa AS (
+    SELECT *
+    FROM patients
+    WHERE user_id = sqlc.arg('user_id')
+      AND deleted_at IS NULL
+      AND (sqlc.narg('name_tokens')::text[] IS NULL OR name_index @> sqlc.narg('name_tokens')::text[])
+      AND (sqlc.narg('gender')::smallint IS NULL OR gender = sqlc.narg('gender')::smallint)
+      AND (sqlc.narg('min_age')::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year >= sqlc.narg('min_age')::int)
+      AND (sqlc.narg('max_age')::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year <= sqlc.narg('max_age')::int)
+)
+SELECT
+    p.id,
+    p.user_id,
+    p.name,
+    p.gender,
+    p.dob,
+    p.created_at,
+    l.probability AS latest_probability,
+    l.risk_label AS latest_risk_label,
+    l.created_at AS latest_prediction_at
+FROM pa p
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+WHERE (sqlc.arg('risk')::text = '' OR COALESCE(l.risk_label, 'none') = sqlc.arg('risk')::text)
+  AND (sqlc.narg('predicted_from')::timestamptz IS NULL OR l.created_at >= sqlc.narg('predicted_from')::timestamptz)
+  AND (sqlc.narg('predicted_to')::timestamptz IS NULL OR l.created_at < sqlc.narg('predicted_to')::timestamptz)
+  AND (sqlc.narg('min_probability')::float8 IS NULL OR l.probability >= sqlc.narg('min_probability')::float8)
+  AND (sqlc.narg('max_probability')::float8 IS NULL OR l.probability <= sqlc.narg('max_probability')::float8)
+  AND (p.created_at, p.id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY p.created_at ASC, p.id ASC
+LIMIT sqlc.arg('limit');
 
 -- name: UpdatePatient :one
 UPDATE patients
 SET
-    name = COALESCE($2, name),
-    gender = COALESCE($3, gender),
-    dob = COALESCE($4, dob)
+    name = $2,
+    gender = $3,
+    dob = $4,
+    birth_year = $5,
+    name_index = $6,
+    key_version = $7
 WHERE id = $1
+  AND deleted_at IS NULL
 RETURNING *;
 
--- name: DeletePatient :exec
+-- name: SoftDeletePatient :exec
+WITH deleted AS (
+    UPDATE patients
+    SET deleted_at = NOW()
+    WHERE id = $1
+      AND deleted_at IS NULL
+    RETURNING user_id
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM deleted
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT 1;
+
+-- name: RestorePatient :one
+WITH restored AS (
+    UPDATE patients
+    SET deleted_at = NULL
+    WHERE id = $1
+      AND deleted_at IS NOT NULL
+    RETURNING *
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM restored
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT * FROM restored;
+
+-- name: ListPatientsForPurge :many
+-- Keyset theo id để lượt purge bỏ qua được bệnh nhân xoá lỗi (thử lại ở lượt sau) thay vì gặp lại nó đầu tiên.
+SELECT id FROM patients
+WHERE deleted_at IS NOT NULL
+  AND deleted_at < sqlc.arg('deleted_at')
+  AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: PurgePatient :exec
 DELETE FROM patients
-WHERE id = $1;
+WHERE id = $1
+  AND deleted_at IS NOT NULL;
+
+-- name: ErasePatient :exec
+WITH erased AS (
+    DELETE FROM patients
+    WHERE id = $1
+    RETURNING user_id
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM erased
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT 1;
+
+-- name: ListPatientsForReencrypt :many
+-- Gồm cả bản ghi chưa có name_index (dữ liệu trước migration mã hoá) để dựng index kể cả khi chạy passthrough.
+SELECT id, name, dob FROM patients
+WHERE (key_version <> sqlc.arg('key_version') OR name_index = '{}')
+  AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListPatientsWithoutNameIndex :many
+-- Bệnh nhân chưa có blind index tên (tạo trước migration mã hoá), cho job dựng index lúc khởi động.
+SELECT id, name, dob FROM patients
+WHERE name_index = '{}'
+  AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdatePatientEncryption :execrows
+-- So khớp ciphertext cũ để không ghi đè thay đổi đồng thời từ API.
+UPDATE patients
+SET
+    name = sqlc.arg('name'),
+    dob = sqlc.arg('dob'),
+    birth_year = sqlc.arg('birth_year'),
+    name_index = sqlc.arg('name_index'),
+    key_version = sqlc.arg('key_version')
+WHERE id = sqlc.arg('id')
+  AND name = sqlc.arg('old_name')
+  AND dob = sqlc.arg('old_dob');
 
 -- name: CountPatientsByUser :one
-SELECT COUNT(*) FROM patients WHERE user_id = $1;
+SELECT COUNT(*) FROM patients WHERE user_id = $1 AND deleted_at IS NULL;
@@ -1,13 +1,41 @@
 -- name: CreatePrediction :one
-INSERT INTO predictions (
-    patient_id,
-    probability,
-    risk_label,
-    raw_features,
-    factors
+-- Ghi prediction, cập nhật patient_latest_prediction và đánh dấu rollup stats của user cần làm mới.
+WITH inserted AS (
+    INSERT INTO predictions (
+        patient_id,
+        probability,
+        risk_label,
+        raw_features,
+        factors,
+        explanation,
+        model_name,
+        model_version,
+        feature_schema_hash,
+        warnings,
+        features_key_version
+    )
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    RETURNING *
+),
+latest AS (
+    INSERT INTO patient_latest_prediction (patient_id, prediction_id, probability, risk_label, created_at)
+    SELECT patient_id, id, probability, risk_label, created_at FROM inserted
+    ON CONFLICT (patient_id) DO UPDATE
+    SET
+        prediction_id = EXCLUDED.prediction_id,
+        probability = EXCLUDED.probability,
+        risk_label = EXCLUDED.risk_label,
+        created_at = EXCLUDED.created_at,
+        updated_at = NOW()
+    WHERE (patient_latest_prediction.created_at, patient_latest_prediction.prediction_id)
+        <= (EXCLUDED.created_at, EXCLUDED.prediction_id)
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT pa.user_id FROM patients pa JOIN inserted i ON i.patient_id = pa.id
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
 )
-VALUES ($1, $2, $3, $4, $5)
-RETURNING *;
+SELECT * FROM inserted;
 
 -- name: GetPredictionByID :one
 SELECT * FROM predictions
@@ -20,27 +48,137 @@ WHERE patient_id = $1
 ORDER BY created_at DESC
 LIMIT 1;
 
--- name: ListPredictionsByPatient :many
+-- name: ListPredictionsByPatientDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
 SELECT * FROM predictions
-WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListPredictionsByPatientAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM predictions
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
 
 -- name: CountLatestRiskByUser :many
 WITH pa AS (
     SELECT id
     FROM patients
     WHERE user_id = $1
-),
-latest AS (
-    SELECT DISTINCT ON (pr.patient_id)
-        pr.patient_id,
-        pr.risk_label
-    FROM predictions pr
-    JOIN pa ON pa.id = pr.patient_id
-    ORDER BY pr.patient_id, pr.created_at DESC
+      AND deleted_at IS NULL
 )
-SELECT COALESCE(latest.risk_label, 'none') AS risk_label, COUNT(*) AS count
+SELECT COALESCE(l.risk_label, 'none') AS risk_label, COUNT(*) AS count
 FROM pa p
-LEFT JOIN latest ON latest.patient_id = p.id
-GROUP BY COALESCE(latest.risk_label, 'none');
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+GROUP BY COALESCE(l.risk_label, 'none');
+
+-- name: ListPredictionsForRescore :many
+SELECT pr.* FROM predictions pr
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = sqlc.arg('user_id')
+  AND pa.deleted_at IS NULL
+  AND (sqlc.narg('patient_id')::bigint IS NULL OR pr.patient_id = sqlc.narg('patient_id')::bigint)
+  AND NOT (pr.model_name = sqlc.arg('model_name') AND pr.model_version = sqlc.arg('model_version'))
+  AND NOT EXISTS (
+    SELECT 1 FROM prediction_rescores rs
+    WHERE rs.prediction_id = pr.id
+      AND rs.model_name = sqlc.arg('model_name')
+      AND rs.model_version = sqlc.arg('model_version')
+  )
+ORDER BY pr.created_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: UpsertPredictionRescore :one
+INSERT INTO prediction_rescores (
+    prediction_id,
+    model_name,
+    model_version,
+    feature_schema_hash,
+    probability,
+    risk_label
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (prediction_id, model_name, model_version) DO UPDATE
+SET
+    feature_schema_hash = EXCLUDED.feature_schema_hash,
+    probability = EXCLUDED.probability,
+    risk_label = EXCLUDED.risk_label,
+    created_at = NOW()
+RETURNING *;
+
+-- name: ListRescoresByPrediction :many
+SELECT * FROM prediction_rescores
+WHERE prediction_id = $1
+ORDER BY created_at DESC;
+
+-- name: CreatePredictionShadow :one
+INSERT INTO prediction_shadows (
+    prediction_id,
+    model_name,
+    model_version,
+    feature_schema_hash,
+    probability,
+    risk_label,
+    latency_ms,
+    error
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetShadowComparisonSummary :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE s.error <> '') AS errors,
+    COUNT(*) FILTER (WHERE s.error = '') AS compared,
+    COUNT(*) FILTER (WHERE s.error = '' AND s.risk_label = pr.risk_label) AS agreed,
+    COALESCE(AVG(s.probability - pr.probability) FILTER (WHERE s.error = ''), 0)::float8 AS mean_diff,
+    COALESCE(AVG(ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''), 0)::float8 AS mean_abs_diff,
+    COALESCE(MAX(ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''), 0)::float8 AS max_abs_diff,
+    COALESCE(
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''),
+        0
+    )::float8 AS p95_abs_diff,
+    COALESCE(AVG(s.latency_ms), 0)::float8 AS mean_latency_ms
+FROM prediction_shadows s
+JOIN predictions pr ON pr.id = s.prediction_id
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = sqlc.arg('user_id')
+  AND pa.deleted_at IS NULL
+  AND s.created_at >= sqlc.arg('from_time')
+  AND s.created_at < sqlc.arg('to_time')
+  AND (sqlc.narg('model_version')::text IS NULL OR s.model_version = sqlc.narg('model_version')::text);
+
+-- name: ListShadowConfusion :many
+SELECT
+    pr.risk_label AS primary_label,
+    s.risk_label AS shadow_label,
+    COUNT(*) AS count
+FROM prediction_shadows s
+JOIN predictions pr ON pr.id = s.prediction_id
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = sqlc.arg('user_id')
+  AND pa.deleted_at IS NULL
+  AND s.created_at >= sqlc.arg('from_time')
+  AND s.created_at < sqlc.arg('to_time')
+  AND (sqlc.narg('model_version')::text IS NULL OR s.model_version = sqlc.narg('model_version')::text)
+  AND s.error = ''
+GROUP BY pr.risk_label, s.risk_label
+ORDER BY pr.risk_label, s.risk_label;
+
+-- name: ListPredictionsForReencrypt :many
+SELECT id, raw_features FROM predictions
+WHERE features_key_version <> sqlc.arg('key_version')
+  AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdatePredictionFeatures :exec
+UPDATE predictions
+SET
+    raw_features = $2,
+    features_key_version = $3
+WHERE id = $1;
@@ -0,0 +1,108 @@
+-- name: CreateReportSchedule :one
+INSERT INTO report_schedules (
+    user_id, patient_id, kind, cron, to_addresses, cc_addresses, locale, message, inactive_months, enabled, next_run_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetReportScheduleByID :one
+SELECT * FROM report_schedules
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListReportSchedulesByUserDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM report_schedules
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('patient_id')::bigint IS NULL OR patient_id = sqlc.narg('patient_id')::bigint)
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListReportSchedulesByUserAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM report_schedules
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('patient_id')::bigint IS NULL OR patient_id = sqlc.narg('patient_id')::bigint)
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateReportSchedule :one
+UPDATE report_schedules
+SET
+    cron = $2,
+    to_addresses = $3,
+    cc_addresses = $4,
+    locale = $5,
+    message = $6,
+    inactive_months = $7,
+    enabled = $8,
+    next_run_at = $9,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteReportSchedule :exec
+DELETE FROM report_schedules
+WHERE id = $1;
+
+-- name: ListDueReportSchedules :many
+SELECT id FROM report_schedules
+WHERE enabled
+  AND next_run_at <= NOW()
+ORDER BY next_run_at, id
+LIMIT $1;
+
+-- name: TryLockReportSchedule :one
+-- Advisory lock mức session: phải gọi trên cùng một connection với UnlockReportSchedule.
+SELECT pg_try_advisory_lock(hashtextextended('report_schedule:' || sqlc.arg('id')::bigint, 0)) AS locked;
+
+-- name: UnlockReportSchedule :one
+SELECT pg_advisory_unlock(hashtextextended('report_schedule:' || sqlc.arg('id')::bigint, 0)) AS unlocked;
+
+-- name: StartReportScheduleRun :one
+-- Dời next_run_at sang mốc kế tiếp và tạo run cho mốc scheduled_for trong cùng câu lệnh;
+-- không trả về dòng nào nếu mốc này đã được xử lý (lịch đã bị dời, tắt hoặc run đã tồn tại).
+WITH advanced AS (
+    UPDATE report_schedules
+    SET next_run_at = sqlc.arg('next_run_at'), last_run_at = NOW(), updated_at = NOW()
+    WHERE id = sqlc.arg('schedule_id')
+      AND enabled
+      AND next_run_at = sqlc.arg('scheduled_for')
+    RETURNING id
+)
+INSERT INTO report_schedule_runs (schedule_id, scheduled_for)
+SELECT id, sqlc.arg('scheduled_for') FROM advanced
+ON CONFLICT (schedule_id, scheduled_for) DO NOTHING
+RETURNING *;
+
+-- name: FinishReportScheduleRun :one
+UPDATE report_schedule_runs
+SET
+    status = sqlc.arg('status'),
+    report_id = sqlc.narg('report_id'),
+    email_ids = sqlc.arg('email_ids'),
+    patient_ids = sqlc.arg('patient_ids'),
+    detail = sqlc.arg('detail'),
+    finished_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListReportScheduleRuns :many
+SELECT * FROM report_schedule_runs
+WHERE schedule_id = $1
+ORDER BY scheduled_for DESC, id DESC
+LIMIT $2;
+
+-- name: ListInactivePatients :many
+-- Bệnh nhân (chưa xoá) có prediction gần nhất, hoặc ngày tạo hồ sơ nếu chưa có prediction, trước mốc before.
+SELECT p.id, p.name, p.dob, l.created_at AS last_prediction_at
+FROM patients p
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+WHERE p.user_id = sqlc.arg('user_id')
+  AND p.deleted_at IS NULL
+  AND (sqlc.narg('patient_id')::bigint IS NULL OR p.id = sqlc.narg('patient_id')::bigint)
+  AND COALESCE(l.created_at, p.created_at) < sqlc.arg('before')::timestamptz
+ORDER BY COALESCE(l.created_at, p.created_at), p.id
+LIMIT sqlc.arg('limit');
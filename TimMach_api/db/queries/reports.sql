@@ -8,11 +8,21 @@ SELECT * FROM reports
 WHERE id = $1
 LIMIT 1;
 
--- name: ListReportsByPatient :many
+-- name: ListReportsByPatientDesc :many
+-- Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
 SELECT * FROM reports
-WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) < (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListReportsByPatientAsc :many
+-- Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+SELECT * FROM reports
+WHERE patient_id = sqlc.arg('patient_id')
+  AND (created_at, id) > (sqlc.arg('cursor_created_at')::timestamptz, sqlc.arg('cursor_id')::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
 
 -- name: UpdateReportRecipients :one
 UPDATE reports
@@ -24,6 +34,10 @@ RETURNING *;
 DELETE FROM reports
 WHERE id = $1;
 
+-- name: ListReportFilesByPatient :many
+SELECT file_url FROM reports
+WHERE patient_id = $1;
+
 -- name: CountReportsByPatient :one
 SELECT COUNT(*) FROM reports
 WHERE patient_id = $1;
@@ -1,23 +1,183 @@
--- name: GetTotalPatients :one
-SELECT COUNT(*) FROM patients
-WHERE user_id = $1;
+-- name: GetNewPatientsTimeseries :many
+SELECT
+    date_trunc(sqlc.arg('bucket')::text, created_at, sqlc.arg('tz')::text)::timestamptz AS bucket_start,
+    COUNT(*) AS new_patients
+FROM patients
+WHERE user_id = sqlc.arg('user_id')
+  AND deleted_at IS NULL
+  AND created_at >= sqlc.arg('from_time')::timestamptz
+  AND created_at < sqlc.arg('to_time')::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start;
 
--- name: GetTotalPredictions :one
-SELECT COUNT(*) 
+-- name: GetPredictionsTimeseries :many
+SELECT
+    date_trunc(sqlc.arg('bucket')::text, p.created_at, sqlc.arg('tz')::text)::timestamptz AS bucket_start,
+    COUNT(*) AS predictions,
+    AVG(p.probability)::float8 AS avg_probability
 FROM predictions p
 JOIN patients pa ON p.patient_id = pa.id
-WHERE pa.user_id = $1;
-
--- name: GetRiskDistribution :many
-WITH latest_predictions AS (
-    SELECT DISTINCT ON (p.patient_id) p.risk_label
-    FROM predictions p
-    JOIN patients pa ON p.patient_id = pa.id
-    WHERE pa.user_id = $1
-    ORDER BY p.patient_id, p.created_at DESC
-)
-SELECT 
-    risk_label,
+WHERE pa.user_id = sqlc.arg('user_id')
+  AND pa.deleted_at IS NULL
+  AND p.created_at >= sqlc.arg('from_time')::timestamptz
+  AND p.created_at < sqlc.arg('to_time')::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start;
+
+-- name: GetRiskTimeseries :many
+SELECT
+    date_trunc(sqlc.arg('bucket')::text, p.created_at, sqlc.arg('tz')::text)::timestamptz AS bucket_start,
+    p.risk_label,
     COUNT(*) AS count
-FROM latest_predictions
-GROUP BY risk_label;
+FROM predictions p
+JOIN patients pa ON p.patient_id = pa.id
+WHERE pa.user_id = sqlc.arg('user_id')
+  AND pa.deleted_at IS NULL
+  AND p.created_at >= sqlc.arg('from_time')::timestamptz
+  AND p.created_at < sqlc.arg('to_time')::timestamptz
+GROUP BY bucket_start, p.risk_label
+ORDER BY bucket_start, p.risk_label;
+
+-- name: ListLatestPredictionFactors :many
+-- Prediction mới nhất của từng bệnh nhân kèm danh sách field trong factors (không trùng, đã sắp xếp).
+WITH latest AS (
+    SELECT
+        l.patient_id,
+        l.probability,
+        p.factors,
+        l.created_at,
+        pa.gender,
+        pa.birth_year
+    FROM patient_latest_prediction l
+    JOIN patients pa ON pa.id = l.patient_id
+    JOIN predictions p ON p.id = l.prediction_id
+    WHERE pa.user_id = sqlc.arg('user_id')
+      AND pa.deleted_at IS NULL
+)
+SELECT
+    l.patient_id,
+    l.probability,
+    l.created_at,
+    l.gender,
+    l.birth_year,
+    COALESCE(
+        (SELECT array_agg(DISTINCT f->>'field' ORDER BY f->>'field')
+         FROM jsonb_array_elements(
+             CASE WHEN jsonb_typeof(l.factors) = 'array' THEN l.factors ELSE '[]'::jsonb END
+         ) f
+         WHERE f->>'field' IS NOT NULL),
+        '{}'
+    )::text[] AS factor_fields
+FROM latest l
+ORDER BY l.patient_id;
+
+-- name: GetUserStatsRollup :one
+SELECT
+    r.user_id,
+    r.total_patients,
+    r.total_predictions,
+    r.high_risk,
+    r.medium_risk,
+    r.low_risk,
+    r.refreshed_at,
+    EXISTS (SELECT 1 FROM stats_dirty_users d WHERE d.user_id = r.user_id) AS dirty
+FROM user_stats_rollups r
+WHERE r.user_id = $1;
+
+-- name: RefreshUserStatsRollup :one
+-- Tính lại rollup của user từ patient_latest_prediction rồi gỡ đánh dấu có trước câu lệnh này.
+WITH cleared AS (
+    DELETE FROM stats_dirty_users
+    WHERE user_id = sqlc.arg('user_id')::text
+      AND marked_at <= NOW()
+),
+pa AS (
+    SELECT id
+    FROM patients
+    WHERE user_id = sqlc.arg('user_id')::text
+      AND deleted_at IS NULL
+)
+INSERT INTO user_stats_rollups (
+    user_id,
+    total_patients,
+    total_predictions,
+    high_risk,
+    medium_risk,
+    low_risk,
+    refreshed_at
+)
+SELECT
+    sqlc.arg('user_id')::text,
+    (SELECT COUNT(*) FROM pa),
+    (SELECT COUNT(*) FROM predictions pr JOIN pa ON pa.id = pr.patient_id),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'high'),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'medium'),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'low'),
+    NOW()
+FROM pa
+JOIN patient_latest_prediction l ON l.patient_id = pa.id
+ON CONFLICT (user_id) DO UPDATE
+SET
+    total_patients = EXCLUDED.total_patients,
+    total_predictions = EXCLUDED.total_predictions,
+    high_risk = EXCLUDED.high_risk,
+    medium_risk = EXCLUDED.medium_risk,
+    low_risk = EXCLUDED.low_risk,
+    refreshed_at = EXCLUDED.refreshed_at
+RETURNING *;
+
+-- name: ListDirtyStatsUsers :many
+SELECT user_id FROM stats_dirty_users
+ORDER BY marked_at
+LIMIT $1;
+
+-- name: MarkAllStatsDirty :execrows
+INSERT INTO stats_dirty_users (user_id)
+SELECT id FROM users
+ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW();
+
+-- name: CountLatestPredictionDrift :one
+-- So projection với prediction mới nhất tính trực tiếp từ predictions.
+WITH expected AS (
+    SELECT DISTINCT ON (patient_id) patient_id, id, probability, risk_label, created_at
+    FROM predictions
+    ORDER BY patient_id, created_at DESC, id DESC
+)
+SELECT
+    COUNT(*) FILTER (WHERE l.patient_id IS NULL) AS missing,
+    COUNT(*) FILTER (WHERE e.patient_id IS NULL) AS orphaned,
+    COUNT(*) FILTER (
+        WHERE e.patient_id IS NOT NULL
+          AND l.patient_id IS NOT NULL
+          AND (l.prediction_id <> e.id
+               OR l.probability <> e.probability
+               OR l.risk_label <> e.risk_label
+               OR l.created_at <> e.created_at)
+    ) AS stale
+FROM expected e
+FULL OUTER JOIN patient_latest_prediction l ON l.patient_id = e.patient_id;
+
+-- name: RebuildPatientLatestPrediction :execrows
+-- Ghi lại các row thiếu/sai của projection; row không còn prediction tương ứng bị xoá.
+WITH expected AS (
+    SELECT DISTINCT ON (patient_id) patient_id, id, probability, risk_label, created_at
+    FROM predictions
+    ORDER BY patient_id, created_at DESC, id DESC
+),
+removed AS (
+    DELETE FROM patient_latest_prediction l
+    WHERE NOT EXISTS (SELECT 1 FROM expected e WHERE e.patient_id = l.patient_id)
+)
+INSERT INTO patient_latest_prediction (patient_id, prediction_id, probability, risk_label, created_at)
+SELECT patient_id, id, probability, risk_label, created_at FROM expected
+ON CONFLICT (patient_id) DO UPDATE
+SET
+    prediction_id = EXCLUDED.prediction_id,
+    probability = EXCLUDED.probability,
+    risk_label = EXCLUDED.risk_label,
+    created_at = EXCLUDED.created_at,
+    updated_at = NOW()
+WHERE patient_latest_prediction.prediction_id <> EXCLUDED.prediction_id
+   OR patient_latest_prediction.probability <> EXCLUDED.probability
+   OR patient_latest_prediction.risk_label <> EXCLUDED.risk_label
+   OR patient_latest_prediction.created_at <> EXCLUDED.created_at;
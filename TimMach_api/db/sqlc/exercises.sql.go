@@ -7,8 +7,22 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countTemplatesByTag = `-- name: CountTemplatesByTag :one
+SELECT COUNT(*) FROM exercise_templates
+WHERE $1::text = ANY(tags)
+`
+
+func (q *Queries) CountTemplatesByTag(ctx context.Context, tag string) (int64, error) {
+	row := q.db.QueryRow(ctx, countTemplatesByTag, tag)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createExerciseRecommendation = `-- name: CreateExerciseRecommendation :one
 INSERT INTO exercise_recommendations (
     patient_id, prediction_id, plan
@@ -35,14 +49,71 @@ func (q *Queries) CreateExerciseRecommendation(ctx context.Context, arg CreateEx
 	return i, err
 }
 
+const createExerciseSession = `-- name: CreateExerciseSession :one
+INSERT INTO exercise_sessions (patient_id, recommendation_id, performed_on, duration_min, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, patient_id, recommendation_id, performed_on, duration_min, notes, recorded_by, created_at
+`
+
+type CreateExerciseSessionParams struct {
+	PatientID        int64       `json:"patient_id"`
+	RecommendationID *int64      `json:"recommendation_id"`
+	PerformedOn      pgtype.Date `json:"performed_on"`
+	DurationMin      int32       `json:"duration_min"`
+	Notes            string      `json:"notes"`
+	RecordedBy       *string     `json:"recorded_by"`
+}
+
+func (q *Queries) CreateExerciseSession(ctx context.Context, arg CreateExerciseSessionParams) (ExerciseSession, error) {
+	row := q.db.QueryRow(ctx, createExerciseSession,
+		arg.PatientID,
+		arg.RecommendationID,
+		arg.PerformedOn,
+		arg.DurationMin,
+		arg.Notes,
+		arg.RecordedBy,
+	)
+	var i ExerciseSession
+	err := row.Scan(
+		&i.ID,
+		&i.PatientID,
+		&i.RecommendationID,
+		&i.PerformedOn,
+		&i.DurationMin,
+		&i.Notes,
+		&i.RecordedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createExerciseTag = `-- name: CreateExerciseTag :one
+INSERT INTO exercise_tags (name, description)
+VALUES ($1, $2)
+RETURNING name, description, created_at
+`
+
+type CreateExerciseTagParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) CreateExerciseTag(ctx context.Context, arg CreateExerciseTagParams) (ExerciseTag, error) {
+	row := q.db.QueryRow(ctx, createExerciseTag, arg.Name, arg.Description)
+	var i ExerciseTag
+	err := row.Scan(&i.Name, &i.Description, &i.CreatedAt)
+	return i, err
+}
+
 const createExerciseTemplate = `-- name: CreateExerciseTemplate :one
 INSERT INTO exercise_templates (
-    name, intensity, description, duration_min, freq_per_week, target_risk_level, tags
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags
+    slug, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug
 `
 
 type CreateExerciseTemplateParams struct {
+	Slug            string   `json:"slug"`
 	Name            string   `json:"name"`
 	Intensity       string   `json:"intensity"`
 	Description     string   `json:"description"`
@@ -54,6 +125,7 @@ type CreateExerciseTemplateParams struct {
 
 func (q *Queries) CreateExerciseTemplate(ctx context.Context, arg CreateExerciseTemplateParams) (ExerciseTemplate, error) {
 	row := q.db.QueryRow(ctx, createExerciseTemplate,
+		arg.Slug,
 		arg.Name,
 		arg.Intensity,
 		arg.Description,
@@ -72,10 +144,37 @@ func (q *Queries) CreateExerciseTemplate(ctx context.Context, arg CreateExercise
 		&i.FreqPerWeek,
 		&i.TargetRiskLevel,
 		&i.Tags,
+		&i.Slug,
 	)
 	return i, err
 }
 
+const deleteExerciseTag = `-- name: DeleteExerciseTag :exec
+DELETE FROM exercise_tags
+WHERE name = $1
+`
+
+func (q *Queries) DeleteExerciseTag(ctx context.Context, name string) error {
+	_, err := q.db.Exec(ctx, deleteExerciseTag, name)
+	return err
+}
+
+const ensureExerciseTag = `-- name: EnsureExerciseTag :exec
+INSERT INTO exercise_tags (name, description)
+VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING
+`
+
+type EnsureExerciseTagParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) EnsureExerciseTag(ctx context.Context, arg EnsureExerciseTagParams) error {
+	_, err := q.db.Exec(ctx, ensureExerciseTag, arg.Name, arg.Description)
+	return err
+}
+
 const getExerciseRecommendationByPrediction = `-- name: GetExerciseRecommendationByPrediction :one
 SELECT id, patient_id, prediction_id, plan, created_at FROM exercise_recommendations
 WHERE prediction_id = $1
@@ -95,21 +194,99 @@ func (q *Queries) GetExerciseRecommendationByPrediction(ctx context.Context, pre
 	return i, err
 }
 
-const listExerciseRecommendationsByPatient = `-- name: ListExerciseRecommendationsByPatient :many
+const getExerciseTemplateBySlug = `-- name: GetExerciseTemplateBySlug :one
+SELECT id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug FROM exercise_templates
+WHERE slug = $1
+LIMIT 1
+`
+
+func (q *Queries) GetExerciseTemplateBySlug(ctx context.Context, slug string) (ExerciseTemplate, error) {
+	row := q.db.QueryRow(ctx, getExerciseTemplateBySlug, slug)
+	var i ExerciseTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Intensity,
+		&i.Description,
+		&i.DurationMin,
+		&i.FreqPerWeek,
+		&i.TargetRiskLevel,
+		&i.Tags,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const listExerciseRecommendationsByPatientAsc = `-- name: ListExerciseRecommendationsByPatientAsc :many
+SELECT id, patient_id, prediction_id, plan, created_at FROM exercise_recommendations
+WHERE patient_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListExerciseRecommendationsByPatientAscParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListExerciseRecommendationsByPatientAsc(ctx context.Context, arg ListExerciseRecommendationsByPatientAscParams) ([]ExerciseRecommendation, error) {
+	rows, err := q.db.Query(ctx, listExerciseRecommendationsByPatientAsc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseRecommendation
+	for rows.Next() {
+		var i ExerciseRecommendation
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.PredictionID,
+			&i.Plan,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseRecommendationsByPatientDesc = `-- name: ListExerciseRecommendationsByPatientDesc :many
 SELECT id, patient_id, prediction_id, plan, created_at FROM exercise_recommendations
 WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-type ListExerciseRecommendationsByPatientParams struct {
-	PatientID int64 `json:"patient_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+type ListExerciseRecommendationsByPatientDescParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
 }
 
-func (q *Queries) ListExerciseRecommendationsByPatient(ctx context.Context, arg ListExerciseRecommendationsByPatientParams) ([]ExerciseRecommendation, error) {
-	rows, err := q.db.Query(ctx, listExerciseRecommendationsByPatient, arg.PatientID, arg.Limit, arg.Offset)
+// Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListExerciseRecommendationsByPatientDesc(ctx context.Context, arg ListExerciseRecommendationsByPatientDescParams) ([]ExerciseRecommendation, error) {
+	rows, err := q.db.Query(ctx, listExerciseRecommendationsByPatientDesc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -134,8 +311,159 @@ func (q *Queries) ListExerciseRecommendationsByPatient(ctx context.Context, arg
 	return items, nil
 }
 
+const listExerciseSessionsByPatientAsc = `-- name: ListExerciseSessionsByPatientAsc :many
+SELECT id, patient_id, recommendation_id, performed_on, duration_min, notes, recorded_by, created_at FROM exercise_sessions
+WHERE patient_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListExerciseSessionsByPatientAscParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListExerciseSessionsByPatientAsc(ctx context.Context, arg ListExerciseSessionsByPatientAscParams) ([]ExerciseSession, error) {
+	rows, err := q.db.Query(ctx, listExerciseSessionsByPatientAsc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseSession
+	for rows.Next() {
+		var i ExerciseSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.RecommendationID,
+			&i.PerformedOn,
+			&i.DurationMin,
+			&i.Notes,
+			&i.RecordedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseSessionsByPatientDesc = `-- name: ListExerciseSessionsByPatientDesc :many
+SELECT id, patient_id, recommendation_id, performed_on, duration_min, notes, recorded_by, created_at FROM exercise_sessions
+WHERE patient_id = $1
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListExerciseSessionsByPatientDescParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListExerciseSessionsByPatientDesc(ctx context.Context, arg ListExerciseSessionsByPatientDescParams) ([]ExerciseSession, error) {
+	rows, err := q.db.Query(ctx, listExerciseSessionsByPatientDesc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseSession
+	for rows.Next() {
+		var i ExerciseSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.RecommendationID,
+			&i.PerformedOn,
+			&i.DurationMin,
+			&i.Notes,
+			&i.RecordedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseTags = `-- name: ListExerciseTags :many
+SELECT name, description, created_at FROM exercise_tags
+ORDER BY name ASC
+`
+
+func (q *Queries) ListExerciseTags(ctx context.Context) ([]ExerciseTag, error) {
+	rows, err := q.db.Query(ctx, listExerciseTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseTag
+	for rows.Next() {
+		var i ExerciseTag
+		if err := rows.Scan(&i.Name, &i.Description, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExerciseTagsByNames = `-- name: ListExerciseTagsByNames :many
+SELECT name, description, created_at FROM exercise_tags
+WHERE name = ANY($1::text[])
+ORDER BY name ASC
+`
+
+func (q *Queries) ListExerciseTagsByNames(ctx context.Context, names []string) ([]ExerciseTag, error) {
+	rows, err := q.db.Query(ctx, listExerciseTagsByNames, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseTag
+	for rows.Next() {
+		var i ExerciseTag
+		if err := rows.Scan(&i.Name, &i.Description, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listExerciseTemplates = `-- name: ListExerciseTemplates :many
-SELECT id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags FROM exercise_templates
+SELECT id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug FROM exercise_templates
 ORDER BY name ASC
 `
 
@@ -157,6 +485,7 @@ func (q *Queries) ListExerciseTemplates(ctx context.Context) ([]ExerciseTemplate
 			&i.FreqPerWeek,
 			&i.TargetRiskLevel,
 			&i.Tags,
+			&i.Slug,
 		); err != nil {
 			return nil, err
 		}
@@ -167,3 +496,222 @@ func (q *Queries) ListExerciseTemplates(ctx context.Context) ([]ExerciseTemplate
 	}
 	return items, nil
 }
+
+const searchExerciseTemplatesAsc = `-- name: SearchExerciseTemplatesAsc :many
+SELECT id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug FROM exercise_templates
+WHERE ($1::text IS NULL OR intensity = $1::text)
+  AND ($2::text IS NULL OR target_risk_level = $2::text)
+  AND ($3::text[] IS NULL OR tags && $3::text[])
+  AND ($4::text[] IS NULL OR tags @> $4::text[])
+  AND ($5::int IS NULL OR duration_min >= $5::int)
+  AND ($6::int IS NULL OR duration_min <= $6::int)
+  AND ($7::int IS NULL OR freq_per_week >= $7::int)
+  AND ($8::int IS NULL OR freq_per_week <= $8::int)
+  AND (
+    $9::text IS NULL
+    OR to_tsvector('simple', name || ' ' || description) @@ websearch_to_tsquery('simple', $9::text)
+  )
+  AND id > $10::bigint
+ORDER BY id ASC
+LIMIT $11
+`
+
+type SearchExerciseTemplatesAscParams struct {
+	Intensity       *string  `json:"intensity"`
+	TargetRiskLevel *string  `json:"target_risk_level"`
+	TagsAny         []string `json:"tags_any"`
+	TagsAll         []string `json:"tags_all"`
+	MinDuration     *int32   `json:"min_duration"`
+	MaxDuration     *int32   `json:"max_duration"`
+	MinFreq         *int32   `json:"min_freq"`
+	MaxFreq         *int32   `json:"max_freq"`
+	Query           *string  `json:"query"`
+	CursorID        int64    `json:"cursor_id"`
+	Limit           int32    `json:"limit"`
+}
+
+// Keyset tăng dần theo id (template không có created_at); trang đầu truyền mốc của pagination.Request.CursorID.
+func (q *Queries) SearchExerciseTemplatesAsc(ctx context.Context, arg SearchExerciseTemplatesAscParams) ([]ExerciseTemplate, error) {
+	rows, err := q.db.Query(ctx, searchExerciseTemplatesAsc,
+		arg.Intensity,
+		arg.TargetRiskLevel,
+		arg.TagsAny,
+		arg.TagsAll,
+		arg.MinDuration,
+		arg.MaxDuration,
+		arg.MinFreq,
+		arg.MaxFreq,
+		arg.Query,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseTemplate
+	for rows.Next() {
+		var i ExerciseTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Intensity,
+			&i.Description,
+			&i.DurationMin,
+			&i.FreqPerWeek,
+			&i.TargetRiskLevel,
+			&i.Tags,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchExerciseTemplatesDesc = `-- name: SearchExerciseTemplatesDesc :many
+SELECT id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug FROM exercise_templates
+WHERE ($1::text IS NULL OR intensity = $1::text)
+  AND ($2::text IS NULL OR target_risk_level = $2::text)
+  AND ($3::text[] IS NULL OR tags && $3::text[])
+  AND ($4::text[] IS NULL OR tags @> $4::text[])
+  AND ($5::int IS NULL OR duration_min >= $5::int)
+  AND ($6::int IS NULL OR duration_min <= $6::int)
+  AND ($7::int IS NULL OR freq_per_week >= $7::int)
+  AND ($8::int IS NULL OR freq_per_week <= $8::int)
+  AND (
+    $9::text IS NULL
+    OR to_tsvector('simple', name || ' ' || description) @@ websearch_to_tsquery('simple', $9::text)
+  )
+  AND id < $10::bigint
+ORDER BY id DESC
+LIMIT $11
+`
+
+type SearchExerciseTemplatesDescParams struct {
+	Intensity       *string  `json:"intensity"`
+	TargetRiskLevel *string  `json:"target_risk_level"`
+	TagsAny         []string `json:"tags_any"`
+	TagsAll         []string `json:"tags_all"`
+	MinDuration     *int32   `json:"min_duration"`
+	MaxDuration     *int32   `json:"max_duration"`
+	MinFreq         *int32   `json:"min_freq"`
+	MaxFreq         *int32   `json:"max_freq"`
+	Query           *string  `json:"query"`
+	CursorID        int64    `json:"cursor_id"`
+	Limit           int32    `json:"limit"`
+}
+
+// Keyset giảm dần theo id (template không có created_at); trang đầu truyền mốc của pagination.Request.CursorID.
+func (q *Queries) SearchExerciseTemplatesDesc(ctx context.Context, arg SearchExerciseTemplatesDescParams) ([]ExerciseTemplate, error) {
+	rows, err := q.db.Query(ctx, searchExerciseTemplatesDesc,
+		arg.Intensity,
+		arg.TargetRiskLevel,
+		arg.TagsAny,
+		arg.TagsAll,
+		arg.MinDuration,
+		arg.MaxDuration,
+		arg.MinFreq,
+		arg.MaxFreq,
+		arg.Query,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExerciseTemplate
+	for rows.Next() {
+		var i ExerciseTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Intensity,
+			&i.Description,
+			&i.DurationMin,
+			&i.FreqPerWeek,
+			&i.TargetRiskLevel,
+			&i.Tags,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateExerciseTemplateBySlug = `-- name: UpdateExerciseTemplateBySlug :one
+UPDATE exercise_templates
+SET
+    name = $2,
+    intensity = $3,
+    description = $4,
+    duration_min = $5,
+    freq_per_week = $6,
+    target_risk_level = $7,
+    tags = $8
+WHERE slug = $1
+RETURNING id, name, intensity, description, duration_min, freq_per_week, target_risk_level, tags, slug
+`
+
+type UpdateExerciseTemplateBySlugParams struct {
+	Slug            string   `json:"slug"`
+	Name            string   `json:"name"`
+	Intensity       string   `json:"intensity"`
+	Description     string   `json:"description"`
+	DurationMin     int32    `json:"duration_min"`
+	FreqPerWeek     int32    `json:"freq_per_week"`
+	TargetRiskLevel string   `json:"target_risk_level"`
+	Tags            []string `json:"tags"`
+}
+
+func (q *Queries) UpdateExerciseTemplateBySlug(ctx context.Context, arg UpdateExerciseTemplateBySlugParams) (ExerciseTemplate, error) {
+	row := q.db.QueryRow(ctx, updateExerciseTemplateBySlug,
+		arg.Slug,
+		arg.Name,
+		arg.Intensity,
+		arg.Description,
+		arg.DurationMin,
+		arg.FreqPerWeek,
+		arg.TargetRiskLevel,
+		arg.Tags,
+	)
+	var i ExerciseTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Intensity,
+		&i.Description,
+		&i.DurationMin,
+		&i.FreqPerWeek,
+		&i.TargetRiskLevel,
+		&i.Tags,
+		&i.Slug,
+	)
+	return i, err
+}
+
+const updateExerciseTemplateSlug = `-- name: UpdateExerciseTemplateSlug :exec
+UPDATE exercise_templates
+SET slug = $2
+WHERE id = $1
+`
+
+type UpdateExerciseTemplateSlugParams struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+}
+
+func (q *Queries) UpdateExerciseTemplateSlug(ctx context.Context, arg UpdateExerciseTemplateSlugParams) error {
+	_, err := q.db.Exec(ctx, updateExerciseTemplateSlug, arg.ID, arg.Slug)
+	return err
+}
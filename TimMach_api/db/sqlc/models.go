@@ -8,6 +8,77 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ClinicBranding struct {
+	Clinic        string             `json:"clinic"`
+	DisplayName   string             `json:"display_name"`
+	Logo          []byte             `json:"logo"`
+	LogoMime      string             `json:"logo_mime"`
+	PrimaryColor  string             `json:"primary_color"`
+	DefaultLocale string             `json:"default_locale"`
+	Footer        string             `json:"footer"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	PdfProtection string             `json:"pdf_protection"`
+}
+
+type Cohort struct {
+	ID          int64              `json:"id"`
+	UserID      string             `json:"user_id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Filter      []byte             `json:"filter"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type DataSubjectRequest struct {
+	ID          int64              `json:"id"`
+	UserID      string             `json:"user_id"`
+	PatientID   int64              `json:"patient_id"`
+	RequestType string             `json:"request_type"`
+	Status      string             `json:"status"`
+	ArchivePath string             `json:"archive_path"`
+	Tombstone   []byte             `json:"tombstone"`
+	Error       string             `json:"error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type EmailAttempt struct {
+	ID         int64              `json:"id"`
+	OutboxID   int64              `json:"outbox_id"`
+	Attempt    int32              `json:"attempt"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error"`
+	StartedAt  pgtype.Timestamptz `json:"started_at"`
+	FinishedAt pgtype.Timestamptz `json:"finished_at"`
+}
+
+type EmailOutbox struct {
+	ID            int64              `json:"id"`
+	UserID        string             `json:"user_id"`
+	PatientID     *int64             `json:"patient_id"`
+	ReportID      *int64             `json:"report_id"`
+	ToAddress     string             `json:"to_address"`
+	Subject       string             `json:"subject"`
+	BodyText      string             `json:"body_text"`
+	BodyHtml      string             `json:"body_html"`
+	Attachments   []byte             `json:"attachments"`
+	Status        string             `json:"status"`
+	Attempts      int32              `json:"attempts"`
+	MaxAttempts   int32              `json:"max_attempts"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	LockedAt      pgtype.Timestamptz `json:"locked_at"`
+	LastError     string             `json:"last_error"`
+	SentAt        pgtype.Timestamptz `json:"sent_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	RecipientRole string             `json:"recipient_role"`
+	HeaderTo      []string           `json:"header_to"`
+	HeaderCc      []string           `json:"header_cc"`
+	PdfProtection string             `json:"pdf_protection"`
+}
+
 type ExerciseRecommendation struct {
 	ID           int64              `json:"id"`
 	PatientID    int64              `json:"patient_id"`
@@ -16,6 +87,23 @@ type ExerciseRecommendation struct {
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
 
+type ExerciseSession struct {
+	ID               int64              `json:"id"`
+	PatientID        int64              `json:"patient_id"`
+	RecommendationID *int64             `json:"recommendation_id"`
+	PerformedOn      pgtype.Date        `json:"performed_on"`
+	DurationMin      int32              `json:"duration_min"`
+	Notes            string             `json:"notes"`
+	RecordedBy       *string            `json:"recorded_by"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type ExerciseTag struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type ExerciseTemplate struct {
 	ID              int64    `json:"id"`
 	Name            string   `json:"name"`
@@ -25,25 +113,80 @@ type ExerciseTemplate struct {
 	FreqPerWeek     int32    `json:"freq_per_week"`
 	TargetRiskLevel string   `json:"target_risk_level"`
 	Tags            []string `json:"tags"`
+	Slug            string   `json:"slug"`
 }
 
 type Patient struct {
-	ID        int64              `json:"id"`
-	UserID    string             `json:"user_id"`
-	Name      string             `json:"name"`
-	Gender    int16              `json:"gender"`
-	Dob       pgtype.Date        `json:"dob"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID         int64              `json:"id"`
+	UserID     string             `json:"user_id"`
+	Name       string             `json:"name"`
+	Gender     int16              `json:"gender"`
+	Dob        string             `json:"dob"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	DeletedAt  pgtype.Timestamptz `json:"deleted_at"`
+	BirthYear  *int16             `json:"birth_year"`
+	NameIndex  []string           `json:"name_index"`
+	KeyVersion int32              `json:"key_version"`
+}
+
+type PatientLatestPrediction struct {
+	PatientID    int64              `json:"patient_id"`
+	PredictionID int64              `json:"prediction_id"`
+	Probability  float64            `json:"probability"`
+	RiskLabel    string             `json:"risk_label"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PatientOutcome struct {
+	ID         int64              `json:"id"`
+	PatientID  int64              `json:"patient_id"`
+	EventType  string             `json:"event_type"`
+	Condition  string             `json:"condition"`
+	OccurredOn pgtype.Date        `json:"occurred_on"`
+	Source     string             `json:"source"`
+	RecordedBy *string            `json:"recorded_by"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type Prediction struct {
-	ID          int64              `json:"id"`
-	PatientID   int64              `json:"patient_id"`
-	Probability float64            `json:"probability"`
-	RiskLabel   string             `json:"risk_label"`
-	RawFeatures []byte             `json:"raw_features"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	Factors     []byte             `json:"factors"`
+	ID                 int64              `json:"id"`
+	PatientID          int64              `json:"patient_id"`
+	Probability        float64            `json:"probability"`
+	RiskLabel          string             `json:"risk_label"`
+	RawFeatures        []byte             `json:"raw_features"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	Factors            []byte             `json:"factors"`
+	Explanation        []byte             `json:"explanation"`
+	ModelName          string             `json:"model_name"`
+	ModelVersion       string             `json:"model_version"`
+	FeatureSchemaHash  string             `json:"feature_schema_hash"`
+	Warnings           []byte             `json:"warnings"`
+	FeaturesKeyVersion int32              `json:"features_key_version"`
+}
+
+type PredictionRescore struct {
+	ID                int64              `json:"id"`
+	PredictionID      int64              `json:"prediction_id"`
+	ModelName         string             `json:"model_name"`
+	ModelVersion      string             `json:"model_version"`
+	FeatureSchemaHash string             `json:"feature_schema_hash"`
+	Probability       float64            `json:"probability"`
+	RiskLabel         string             `json:"risk_label"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+}
+
+type PredictionShadow struct {
+	ID                int64              `json:"id"`
+	PredictionID      int64              `json:"prediction_id"`
+	ModelName         string             `json:"model_name"`
+	ModelVersion      string             `json:"model_version"`
+	FeatureSchemaHash string             `json:"feature_schema_hash"`
+	Probability       *float64           `json:"probability"`
+	RiskLabel         string             `json:"risk_label"`
+	LatencyMs         int32              `json:"latency_ms"`
+	Error             string             `json:"error"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
 }
 
 type Report struct {
@@ -55,9 +198,56 @@ type Report struct {
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
+type ReportSchedule struct {
+	ID             int64              `json:"id"`
+	UserID         string             `json:"user_id"`
+	PatientID      *int64             `json:"patient_id"`
+	Kind           string             `json:"kind"`
+	Cron           string             `json:"cron"`
+	ToAddresses    []string           `json:"to_addresses"`
+	CcAddresses    []string           `json:"cc_addresses"`
+	Locale         string             `json:"locale"`
+	Message        string             `json:"message"`
+	InactiveMonths int32              `json:"inactive_months"`
+	Enabled        bool               `json:"enabled"`
+	NextRunAt      pgtype.Timestamptz `json:"next_run_at"`
+	LastRunAt      pgtype.Timestamptz `json:"last_run_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ReportScheduleRun struct {
+	ID           int64              `json:"id"`
+	ScheduleID   int64              `json:"schedule_id"`
+	ScheduledFor pgtype.Timestamptz `json:"scheduled_for"`
+	Status       string             `json:"status"`
+	ReportID     *int64             `json:"report_id"`
+	EmailIds     []int64            `json:"email_ids"`
+	PatientIds   []int64            `json:"patient_ids"`
+	Detail       string             `json:"detail"`
+	StartedAt    pgtype.Timestamptz `json:"started_at"`
+	FinishedAt   pgtype.Timestamptz `json:"finished_at"`
+}
+
+type StatsDirtyUser struct {
+	UserID   string             `json:"user_id"`
+	MarkedAt pgtype.Timestamptz `json:"marked_at"`
+}
+
 type User struct {
 	ID         string             `json:"id"`
 	Email      string             `json:"email"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 	KeycloakID *string            `json:"keycloak_id"`
+	Clinic     string             `json:"clinic"`
+}
+
+type UserStatsRollup struct {
+	UserID           string             `json:"user_id"`
+	TotalPatients    int64              `json:"total_patients"`
+	TotalPredictions int64              `json:"total_predictions"`
+	HighRisk         int64              `json:"high_risk"`
+	MediumRisk       int64              `json:"medium_risk"`
+	LowRisk          int64              `json:"low_risk"`
+	RefreshedAt      pgtype.Timestamptz `json:"refreshed_at"`
 }
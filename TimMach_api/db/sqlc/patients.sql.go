@@ -12,7 +12,7 @@ import (
 )
 
 const countPatientsByUser = `-- name: CountPatientsByUser :one
-SELECT COUNT(*) FROM patients WHERE user_id = $1
+SELECT COUNT(*) FROM patients WHERE user_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) CountPatientsByUser(ctx context.Context, userID string) (int64, error) {
@@ -23,16 +23,27 @@ func (q *Queries) CountPatientsByUser(ctx context.Context, userID string) (int64
 }
 
 const createPatient = `-- name: CreatePatient :one
-INSERT INTO patients (user_id, name, gender, dob)
-VALUES ($1, $2, $3, $4)
-RETURNING id, user_id, name, gender, dob, created_at
+WITH inserted AS (
+    INSERT INTO patients (user_id, name, gender, dob, birth_year, name_index, key_version)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM inserted
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM inserted
 `
 
 type CreatePatientParams struct {
-	UserID string      `json:"user_id"`
-	Name   string      `json:"name"`
-	Gender int16       `json:"gender"`
-	Dob    pgtype.Date `json:"dob"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	Gender     int16    `json:"gender"`
+	Dob        string   `json:"dob"`
+	BirthYear  *int16   `json:"birth_year"`
+	NameIndex  []string `json:"name_index"`
+	KeyVersion int32    `json:"key_version"`
 }
 
 func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (Patient, error) {
@@ -41,6 +52,9 @@ func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (P
 		arg.Name,
 		arg.Gender,
 		arg.Dob,
+		arg.BirthYear,
+		arg.NameIndex,
+		arg.KeyVersion,
 	)
 	var i Patient
 	err := row.Scan(
@@ -50,23 +64,86 @@ func (q *Queries) CreatePatient(ctx context.Context, arg CreatePatientParams) (P
 		&i.Gender,
 		&i.Dob,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
 	)
 	return i, err
 }
 
-const deletePatient = `-- name: DeletePatient :exec
-DELETE FROM patients
-WHERE id = $1
+const erasePatient = `-- name: ErasePatient :exec
+WITH erased AS (
+    DELETE FROM patients
+    WHERE id = $1
+    RETURNING user_id
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM erased
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT 1
 `
 
-func (q *Queries) DeletePatient(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deletePatient, id)
+func (q *Queries) ErasePatient(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, erasePatient, id)
 	return err
 }
 
+const getAnyPatientByID = `-- name: GetAnyPatientByID :one
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM patients
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAnyPatientByID(ctx context.Context, id int64) (Patient, error) {
+	row := q.db.QueryRow(ctx, getAnyPatientByID, id)
+	var i Patient
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Gender,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
+	)
+	return i, err
+}
+
+const getDeletedPatientByID = `-- name: GetDeletedPatientByID :one
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM patients
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+LIMIT 1
+`
+
+func (q *Queries) GetDeletedPatientByID(ctx context.Context, id int64) (Patient, error) {
+	row := q.db.QueryRow(ctx, getDeletedPatientByID, id)
+	var i Patient
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Gender,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
+	)
+	return i, err
+}
+
 const getPatientByID = `-- name: GetPatientByID :one
-SELECT id, user_id, name, gender, dob, created_at FROM patients
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM patients
 WHERE id = $1
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -80,13 +157,18 @@ func (q *Queries) GetPatientByID(ctx context.Context, id int64) (Patient, error)
 		&i.Gender,
 		&i.Dob,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
 	)
 	return i, err
 }
 
 const listPatientsByUser = `-- name: ListPatientsByUser :many
-SELECT id, user_id, name, gender, dob, created_at FROM patients
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM patients
 WHERE user_id = $1
+  AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
@@ -113,6 +195,10 @@ func (q *Queries) ListPatientsByUser(ctx context.Context, arg ListPatientsByUser
 			&i.Gender,
 			&i.Dob,
 			&i.CreatedAt,
+			&i.DeletedAt,
+			&i.BirthYear,
+			&i.NameIndex,
+			&i.KeyVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -124,21 +210,95 @@ func (q *Queries) ListPatientsByUser(ctx context.Context, arg ListPatientsByUser
 	return items, nil
 }
 
-const listPatientsWithLatestPrediction = `-- name: ListPatientsWithLatestPrediction :many
+const listPatientsForPurge = `-- name: ListPatientsForPurge :many
+SELECT id FROM patients
+WHERE deleted_at IS NOT NULL
+  AND deleted_at < $1
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListPatientsForPurgeParams struct {
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	AfterID   int64              `json:"after_id"`
+	Limit     int32              `json:"limit"`
+}
+
+// Keyset theo id để lượt purge bỏ qua được bệnh nhân xoá lỗi (thử lại ở lượt sau) thay vì gặp lại nó đầu tiên.
+func (q *Queries) ListPatientsForPurge(ctx context.Context, arg ListPatientsForPurgeParams) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listPatientsForPurge, arg.DeletedAt, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPatientsForReencrypt = `-- name: ListPatientsForReencrypt :many
+SELECT id, name, dob FROM patients
+WHERE (key_version <> $1 OR name_index = '{}')
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListPatientsForReencryptParams struct {
+	KeyVersion int32 `json:"key_version"`
+	AfterID    int64 `json:"after_id"`
+	Limit      int32 `json:"limit"`
+}
+
+type ListPatientsForReencryptRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Dob  string `json:"dob"`
+}
+
+// Gồm cả bản ghi chưa có name_index (dữ liệu trước migration mã hoá) để dựng index kể cả khi chạy passthrough.
+func (q *Queries) ListPatientsForReencrypt(ctx context.Context, arg ListPatientsForReencryptParams) ([]ListPatientsForReencryptRow, error) {
+	rows, err := q.db.Query(ctx, listPatientsForReencrypt, arg.KeyVersion, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPatientsForReencryptRow
+	for rows.Next() {
+		var i ListPatientsForReencryptRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Dob); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPatientsWithLatestPredictionAsc = `-- name: ListPatientsWithLatestPredictionAsc :many
 WITH pa AS (
-    SELECT id, user_id, name, gender, dob, created_at
+    SELECT *
     FROM patients
     WHERE user_id = $1
-),
-latest AS (
-    SELECT DISTINCT ON (pr.patient_id)
-        pr.patient_id,
-        pr.probability,
-        pr.risk_label,
-        pr.created_at
-    FROM predictions pr
-    JOIN pa ON pa.id = pr.patient_id
-    ORDER BY pr.patient_id, pr.created_at DESC
+      AND deleted_at IS NULL
+      AND ($2::text[] IS NULL OR name_index @> $2::text[])
+      AND ($3::smallint IS NULL OR gender = $3::smallint)
+      AND ($4::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year >= $4::int)
+      AND ($5::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year <= $5::int)
 )
 SELECT
     p.id,
@@ -151,45 +311,177 @@ SELECT
     l.risk_label AS latest_risk_label,
     l.created_at AS latest_prediction_at
 FROM pa p
-LEFT JOIN latest l ON l.patient_id = p.id
-WHERE ($4 = '' OR COALESCE(l.risk_label, 'none') = $4)
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+WHERE ($6::text = '' OR COALESCE(l.risk_label, 'none') = $6::text)
+  AND ($7::timestamptz IS NULL OR l.created_at >= $7::timestamptz)
+  AND ($8::timestamptz IS NULL OR l.created_at < $8::timestamptz)
+  AND ($9::float8 IS NULL OR l.probability >= $9::float8)
+  AND ($10::float8 IS NULL OR l.probability <= $10::float8)
+  AND (p.created_at, p.id) > ($11::timestamptz, $12::bigint)
+ORDER BY p.created_at ASC, p.id ASC
+LIMIT $13
+`
+
+type ListPatientsWithLatestPredictionAscParams struct {
+	UserID          string             `json:"user_id"`
+	NameTokens      []string           `json:"name_tokens"`
+	Gender          *int16             `json:"gender"`
+	MinAge          *int32             `json:"min_age"`
+	MaxAge          *int32             `json:"max_age"`
+	Risk            string             `json:"risk"`
+	PredictedFrom   pgtype.Timestamptz `json:"predicted_from"`
+	PredictedTo     pgtype.Timestamptz `json:"predicted_to"`
+	MinProbability  *float64           `json:"min_probability"`
+	MaxProbability  *float64           `json:"max_probability"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+type ListPatientsWithLatestPredictionAscRow struct {
+	ID                 int64              `json:"id"`
+	UserID             string             `json:"user_id"`
+	Name               string             `json:"name"`
+	Gender             int16              `json:"gender"`
+	Dob                string             `json:"dob"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	LatestProbability  *float64           `json:"latest_probability"`
+	LatestRiskLabel    *string            `json:"latest_risk_label"`
+	LatestPredictionAt pgtype.Timestamptz `json:"latest_prediction_at"`
+}
+
+// Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListPatientsWithLatestPredictionAsc(ctx context.Context, arg ListPatientsWithLatestPredictionAscParams) ([]ListPatientsWithLatestPredictionAscRow, error) {
+	rows, err := q.db.Query(ctx, listPatientsWithLatestPredictionAsc,
+		arg.UserID,
+		arg.NameTokens,
+		arg.Gender,
+		arg.MinAge,
+		arg.MaxAge,
+		arg.Risk,
+		arg.PredictedFrom,
+		arg.PredictedTo,
+		arg.MinProbability,
+		arg.MaxProbability,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPatientsWithLatestPredictionAscRow
+	for rows.Next() {
+		var i ListPatientsWithLatestPredictionAscRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Gender,
+			&i.Dob,
+			&i.CreatedAt,
+			&i.LatestProbability,
+			&i.LatestRiskLabel,
+			&i.LatestPredictionAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPatientsWithLatestPredictionDesc = `-- name: ListPatientsWithLatestPredictionDesc :many
+WITH pa AS (
+    SELECT *
+    FROM patients
+    WHERE user_id = $1
+      AND deleted_at IS NULL
+      AND ($2::text[] IS NULL OR name_index @> $2::text[])
+      AND ($3::smallint IS NULL OR gender = $3::smallint)
+      AND ($4::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year >= $4::int)
+      AND ($5::int IS NULL
+           OR EXTRACT(YEAR FROM CURRENT_DATE)::int - birth_year <= $5::int)
+)
+SELECT
+    p.id,
+    p.user_id,
+    p.name,
+    p.gender,
+    p.dob,
+    p.created_at,
+    l.probability AS latest_probability,
+    l.risk_label AS latest_risk_label,
+    l.created_at AS latest_prediction_at
+FROM pa p
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+WHERE ($6::text = '' OR COALESCE(l.risk_label, 'none') = $6::text)
+  AND ($7::timestamptz IS NULL OR l.created_at >= $7::timestamptz)
+  AND ($8::timestamptz IS NULL OR l.created_at < $8::timestamptz)
+  AND ($9::float8 IS NULL OR l.probability >= $9::float8)
+  AND ($10::float8 IS NULL OR l.probability <= $10::float8)
+  AND (p.created_at, p.id) < ($11::timestamptz, $12::bigint)
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $13
 `
 
-type ListPatientsWithLatestPredictionParams struct {
-	UserID  string      `json:"user_id"`
-	Limit   int32       `json:"limit"`
-	Offset  int32       `json:"offset"`
-	Column4 interface{} `json:"column_4"`
+type ListPatientsWithLatestPredictionDescParams struct {
+	UserID          string             `json:"user_id"`
+	NameTokens      []string           `json:"name_tokens"`
+	Gender          *int16             `json:"gender"`
+	MinAge          *int32             `json:"min_age"`
+	MaxAge          *int32             `json:"max_age"`
+	Risk            string             `json:"risk"`
+	PredictedFrom   pgtype.Timestamptz `json:"predicted_from"`
+	PredictedTo     pgtype.Timestamptz `json:"predicted_to"`
+	MinProbability  *float64           `json:"min_probability"`
+	MaxProbability  *float64           `json:"max_probability"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
 }
 
-type ListPatientsWithLatestPredictionRow struct {
+type ListPatientsWithLatestPredictionDescRow struct {
 	ID                 int64              `json:"id"`
 	UserID             string             `json:"user_id"`
 	Name               string             `json:"name"`
 	Gender             int16              `json:"gender"`
-	Dob                pgtype.Date        `json:"dob"`
+	Dob                string             `json:"dob"`
 	CreatedAt          pgtype.Timestamptz `json:"created_at"`
 	LatestProbability  *float64           `json:"latest_probability"`
 	LatestRiskLabel    *string            `json:"latest_risk_label"`
 	LatestPredictionAt pgtype.Timestamptz `json:"latest_prediction_at"`
 }
 
-func (q *Queries) ListPatientsWithLatestPrediction(ctx context.Context, arg ListPatientsWithLatestPredictionParams) ([]ListPatientsWithLatestPredictionRow, error) {
-	rows, err := q.db.Query(ctx, listPatientsWithLatestPrediction,
+// Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListPatientsWithLatestPredictionDesc(ctx context.Context, arg ListPatientsWithLatestPredictionDescParams) ([]ListPatientsWithLatestPredictionDescRow, error) {
+	rows, err := q.db.Query(ctx, listPatientsWithLatestPredictionDesc,
 		arg.UserID,
+		arg.NameTokens,
+		arg.Gender,
+		arg.MinAge,
+		arg.MaxAge,
+		arg.Risk,
+		arg.PredictedFrom,
+		arg.PredictedTo,
+		arg.MinProbability,
+		arg.MaxProbability,
+		arg.CursorCreatedAt,
+		arg.CursorID,
 		arg.Limit,
-		arg.Offset,
-		arg.Column4,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListPatientsWithLatestPredictionRow
+	var items []ListPatientsWithLatestPredictionDescRow
 	for rows.Next() {
-		var i ListPatientsWithLatestPredictionRow
+		var i ListPatientsWithLatestPredictionDescRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.UserID,
@@ -211,21 +503,134 @@ func (q *Queries) ListPatientsWithLatestPrediction(ctx context.Context, arg List
 	return items, nil
 }
 
+const listPatientsWithoutNameIndex = `-- name: ListPatientsWithoutNameIndex :many
+SELECT id, name, dob FROM patients
+WHERE name_index = '{}'
+  AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListPatientsWithoutNameIndexParams struct {
+	AfterID int64 `json:"after_id"`
+	Limit   int32 `json:"limit"`
+}
+
+type ListPatientsWithoutNameIndexRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Dob  string `json:"dob"`
+}
+
+// Bệnh nhân chưa có blind index tên (tạo trước migration mã hoá), cho job dựng index lúc khởi động.
+func (q *Queries) ListPatientsWithoutNameIndex(ctx context.Context, arg ListPatientsWithoutNameIndexParams) ([]ListPatientsWithoutNameIndexRow, error) {
+	rows, err := q.db.Query(ctx, listPatientsWithoutNameIndex, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPatientsWithoutNameIndexRow
+	for rows.Next() {
+		var i ListPatientsWithoutNameIndexRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Dob); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgePatient = `-- name: PurgePatient :exec
+DELETE FROM patients
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgePatient(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, purgePatient, id)
+	return err
+}
+
+const restorePatient = `-- name: RestorePatient :one
+WITH restored AS (
+    UPDATE patients
+    SET deleted_at = NULL
+    WHERE id = $1
+      AND deleted_at IS NOT NULL
+    RETURNING id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM restored
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version FROM restored
+`
+
+func (q *Queries) RestorePatient(ctx context.Context, id int64) (Patient, error) {
+	row := q.db.QueryRow(ctx, restorePatient, id)
+	var i Patient
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Gender,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
+	)
+	return i, err
+}
+
+const softDeletePatient = `-- name: SoftDeletePatient :exec
+WITH deleted AS (
+    UPDATE patients
+    SET deleted_at = NOW()
+    WHERE id = $1
+      AND deleted_at IS NULL
+    RETURNING user_id
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT user_id FROM deleted
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+)
+SELECT 1
+`
+
+func (q *Queries) SoftDeletePatient(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, softDeletePatient, id)
+	return err
+}
+
 const updatePatient = `-- name: UpdatePatient :one
 UPDATE patients
 SET
-    name = COALESCE($2, name),
-    gender = COALESCE($3, gender),
-    dob = COALESCE($4, dob)
+    name = $2,
+    gender = $3,
+    dob = $4,
+    birth_year = $5,
+    name_index = $6,
+    key_version = $7
 WHERE id = $1
-RETURNING id, user_id, name, gender, dob, created_at
+  AND deleted_at IS NULL
+RETURNING id, user_id, name, gender, dob, created_at, deleted_at, birth_year, name_index, key_version
 `
 
 type UpdatePatientParams struct {
-	ID     int64       `json:"id"`
-	Name   string      `json:"name"`
-	Gender int16       `json:"gender"`
-	Dob    pgtype.Date `json:"dob"`
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Gender     int16    `json:"gender"`
+	Dob        string   `json:"dob"`
+	BirthYear  *int16   `json:"birth_year"`
+	NameIndex  []string `json:"name_index"`
+	KeyVersion int32    `json:"key_version"`
 }
 
 func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (Patient, error) {
@@ -234,6 +639,9 @@ func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (P
 		arg.Name,
 		arg.Gender,
 		arg.Dob,
+		arg.BirthYear,
+		arg.NameIndex,
+		arg.KeyVersion,
 	)
 	var i Patient
 	err := row.Scan(
@@ -243,6 +651,52 @@ func (q *Queries) UpdatePatient(ctx context.Context, arg UpdatePatientParams) (P
 		&i.Gender,
 		&i.Dob,
 		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.BirthYear,
+		&i.NameIndex,
+		&i.KeyVersion,
 	)
 	return i, err
 }
+
+const updatePatientEncryption = `-- name: UpdatePatientEncryption :execrows
+UPDATE patients
+SET
+    name = $1,
+    dob = $2,
+    birth_year = $3,
+    name_index = $4,
+    key_version = $5
+WHERE id = $6
+  AND name = $7
+  AND dob = $8
+`
+
+type UpdatePatientEncryptionParams struct {
+	Name       string   `json:"name"`
+	Dob        string   `json:"dob"`
+	BirthYear  *int16   `json:"birth_year"`
+	NameIndex  []string `json:"name_index"`
+	KeyVersion int32    `json:"key_version"`
+	ID         int64    `json:"id"`
+	OldName    string   `json:"old_name"`
+	OldDob     string   `json:"old_dob"`
+}
+
+// So khớp ciphertext cũ để không ghi đè thay đổi đồng thời từ API.
+func (q *Queries) UpdatePatientEncryption(ctx context.Context, arg UpdatePatientEncryptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updatePatientEncryption,
+		arg.Name,
+		arg.Dob,
+		arg.BirthYear,
+		arg.NameIndex,
+		arg.KeyVersion,
+		arg.ID,
+		arg.OldName,
+		arg.OldDob,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countLatestRiskByUser = `-- name: CountLatestRiskByUser :many
@@ -14,19 +16,12 @@ WITH pa AS (
     SELECT id
     FROM patients
     WHERE user_id = $1
-),
-latest AS (
-    SELECT DISTINCT ON (pr.patient_id)
-        pr.patient_id,
-        pr.risk_label
-    FROM predictions pr
-    JOIN pa ON pa.id = pr.patient_id
-    ORDER BY pr.patient_id, pr.created_at DESC
+      AND deleted_at IS NULL
 )
-SELECT COALESCE(latest.risk_label, 'none') AS risk_label, COUNT(*) AS count
+SELECT COALESCE(l.risk_label, 'none') AS risk_label, COUNT(*) AS count
 FROM pa p
-LEFT JOIN latest ON latest.patient_id = p.id
-GROUP BY COALESCE(latest.risk_label, 'none')
+LEFT JOIN patient_latest_prediction l ON l.patient_id = p.id
+GROUP BY COALESCE(l.risk_label, 'none')
 `
 
 type CountLatestRiskByUserRow struct {
@@ -55,25 +50,59 @@ func (q *Queries) CountLatestRiskByUser(ctx context.Context, userID string) ([]C
 }
 
 const createPrediction = `-- name: CreatePrediction :one
-INSERT INTO predictions (
-    patient_id,
-    probability,
-    risk_label,
-    raw_features,
-    factors
+WITH inserted AS (
+    INSERT INTO predictions (
+        patient_id,
+        probability,
+        risk_label,
+        raw_features,
+        factors,
+        explanation,
+        model_name,
+        model_version,
+        feature_schema_hash,
+        warnings,
+        features_key_version
+    )
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    RETURNING id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version
+),
+latest AS (
+    INSERT INTO patient_latest_prediction (patient_id, prediction_id, probability, risk_label, created_at)
+    SELECT patient_id, id, probability, risk_label, created_at FROM inserted
+    ON CONFLICT (patient_id) DO UPDATE
+    SET
+        prediction_id = EXCLUDED.prediction_id,
+        probability = EXCLUDED.probability,
+        risk_label = EXCLUDED.risk_label,
+        created_at = EXCLUDED.created_at,
+        updated_at = NOW()
+    WHERE (patient_latest_prediction.created_at, patient_latest_prediction.prediction_id)
+        <= (EXCLUDED.created_at, EXCLUDED.prediction_id)
+),
+dirty AS (
+    INSERT INTO stats_dirty_users (user_id)
+    SELECT pa.user_id FROM patients pa JOIN inserted i ON i.patient_id = pa.id
+    ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
 )
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, patient_id, probability, risk_label, raw_features, created_at, factors
+SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version FROM inserted
 `
 
 type CreatePredictionParams struct {
-	PatientID   int64   `json:"patient_id"`
-	Probability float64 `json:"probability"`
-	RiskLabel   string  `json:"risk_label"`
-	RawFeatures []byte  `json:"raw_features"`
-	Factors     []byte  `json:"factors"`
+	PatientID          int64   `json:"patient_id"`
+	Probability        float64 `json:"probability"`
+	RiskLabel          string  `json:"risk_label"`
+	RawFeatures        []byte  `json:"raw_features"`
+	Factors            []byte  `json:"factors"`
+	Explanation        []byte  `json:"explanation"`
+	ModelName          string  `json:"model_name"`
+	ModelVersion       string  `json:"model_version"`
+	FeatureSchemaHash  string  `json:"feature_schema_hash"`
+	Warnings           []byte  `json:"warnings"`
+	FeaturesKeyVersion int32   `json:"features_key_version"`
 }
 
+// Ghi prediction, cập nhật patient_latest_prediction và đánh dấu rollup stats của user cần làm mới.
 func (q *Queries) CreatePrediction(ctx context.Context, arg CreatePredictionParams) (Prediction, error) {
 	row := q.db.QueryRow(ctx, createPrediction,
 		arg.PatientID,
@@ -81,6 +110,12 @@ func (q *Queries) CreatePrediction(ctx context.Context, arg CreatePredictionPara
 		arg.RiskLabel,
 		arg.RawFeatures,
 		arg.Factors,
+		arg.Explanation,
+		arg.ModelName,
+		arg.ModelVersion,
+		arg.FeatureSchemaHash,
+		arg.Warnings,
+		arg.FeaturesKeyVersion,
 	)
 	var i Prediction
 	err := row.Scan(
@@ -91,12 +126,71 @@ func (q *Queries) CreatePrediction(ctx context.Context, arg CreatePredictionPara
 		&i.RawFeatures,
 		&i.CreatedAt,
 		&i.Factors,
+		&i.Explanation,
+		&i.ModelName,
+		&i.ModelVersion,
+		&i.FeatureSchemaHash,
+		&i.Warnings,
+		&i.FeaturesKeyVersion,
+	)
+	return i, err
+}
+
+const createPredictionShadow = `-- name: CreatePredictionShadow :one
+INSERT INTO prediction_shadows (
+    prediction_id,
+    model_name,
+    model_version,
+    feature_schema_hash,
+    probability,
+    risk_label,
+    latency_ms,
+    error
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, prediction_id, model_name, model_version, feature_schema_hash, probability, risk_label, latency_ms, error, created_at
+`
+
+type CreatePredictionShadowParams struct {
+	PredictionID      int64    `json:"prediction_id"`
+	ModelName         string   `json:"model_name"`
+	ModelVersion      string   `json:"model_version"`
+	FeatureSchemaHash string   `json:"feature_schema_hash"`
+	Probability       *float64 `json:"probability"`
+	RiskLabel         string   `json:"risk_label"`
+	LatencyMs         int32    `json:"latency_ms"`
+	Error             string   `json:"error"`
+}
+
+func (q *Queries) CreatePredictionShadow(ctx context.Context, arg CreatePredictionShadowParams) (PredictionShadow, error) {
+	row := q.db.QueryRow(ctx, createPredictionShadow,
+		arg.PredictionID,
+		arg.ModelName,
+		arg.ModelVersion,
+		arg.FeatureSchemaHash,
+		arg.Probability,
+		arg.RiskLabel,
+		arg.LatencyMs,
+		arg.Error,
+	)
+	var i PredictionShadow
+	err := row.Scan(
+		&i.ID,
+		&i.PredictionID,
+		&i.ModelName,
+		&i.ModelVersion,
+		&i.FeatureSchemaHash,
+		&i.Probability,
+		&i.RiskLabel,
+		&i.LatencyMs,
+		&i.Error,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getLatestPredictionByPatient = `-- name: GetLatestPredictionByPatient :one
-SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors FROM predictions
+SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version FROM predictions
 WHERE patient_id = $1
 ORDER BY created_at DESC
 LIMIT 1
@@ -113,12 +207,18 @@ func (q *Queries) GetLatestPredictionByPatient(ctx context.Context, patientID in
 		&i.RawFeatures,
 		&i.CreatedAt,
 		&i.Factors,
+		&i.Explanation,
+		&i.ModelName,
+		&i.ModelVersion,
+		&i.FeatureSchemaHash,
+		&i.Warnings,
+		&i.FeaturesKeyVersion,
 	)
 	return i, err
 }
 
 const getPredictionByID = `-- name: GetPredictionByID :one
-SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors FROM predictions
+SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version FROM predictions
 WHERE id = $1
 LIMIT 1
 `
@@ -134,25 +234,159 @@ func (q *Queries) GetPredictionByID(ctx context.Context, id int64) (Prediction,
 		&i.RawFeatures,
 		&i.CreatedAt,
 		&i.Factors,
+		&i.Explanation,
+		&i.ModelName,
+		&i.ModelVersion,
+		&i.FeatureSchemaHash,
+		&i.Warnings,
+		&i.FeaturesKeyVersion,
 	)
 	return i, err
 }
 
-const listPredictionsByPatient = `-- name: ListPredictionsByPatient :many
-SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors FROM predictions
+const getShadowComparisonSummary = `-- name: GetShadowComparisonSummary :one
+SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE s.error <> '') AS errors,
+    COUNT(*) FILTER (WHERE s.error = '') AS compared,
+    COUNT(*) FILTER (WHERE s.error = '' AND s.risk_label = pr.risk_label) AS agreed,
+    COALESCE(AVG(s.probability - pr.probability) FILTER (WHERE s.error = ''), 0)::float8 AS mean_diff,
+    COALESCE(AVG(ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''), 0)::float8 AS mean_abs_diff,
+    COALESCE(MAX(ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''), 0)::float8 AS max_abs_diff,
+    COALESCE(
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY ABS(s.probability - pr.probability)) FILTER (WHERE s.error = ''),
+        0
+    )::float8 AS p95_abs_diff,
+    COALESCE(AVG(s.latency_ms), 0)::float8 AS mean_latency_ms
+FROM prediction_shadows s
+JOIN predictions pr ON pr.id = s.prediction_id
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = $1
+  AND pa.deleted_at IS NULL
+  AND s.created_at >= $2
+  AND s.created_at < $3
+  AND ($4::text IS NULL OR s.model_version = $4::text)
+`
+
+type GetShadowComparisonSummaryParams struct {
+	UserID       string             `json:"user_id"`
+	FromTime     pgtype.Timestamptz `json:"from_time"`
+	ToTime       pgtype.Timestamptz `json:"to_time"`
+	ModelVersion *string            `json:"model_version"`
+}
+
+type GetShadowComparisonSummaryRow struct {
+	Total         int64   `json:"total"`
+	Errors        int64   `json:"errors"`
+	Compared      int64   `json:"compared"`
+	Agreed        int64   `json:"agreed"`
+	MeanDiff      float64 `json:"mean_diff"`
+	MeanAbsDiff   float64 `json:"mean_abs_diff"`
+	MaxAbsDiff    float64 `json:"max_abs_diff"`
+	P95AbsDiff    float64 `json:"p95_abs_diff"`
+	MeanLatencyMs float64 `json:"mean_latency_ms"`
+}
+
+func (q *Queries) GetShadowComparisonSummary(ctx context.Context, arg GetShadowComparisonSummaryParams) (GetShadowComparisonSummaryRow, error) {
+	row := q.db.QueryRow(ctx, getShadowComparisonSummary,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.ModelVersion,
+	)
+	var i GetShadowComparisonSummaryRow
+	err := row.Scan(
+		&i.Total,
+		&i.Errors,
+		&i.Compared,
+		&i.Agreed,
+		&i.MeanDiff,
+		&i.MeanAbsDiff,
+		&i.MaxAbsDiff,
+		&i.P95AbsDiff,
+		&i.MeanLatencyMs,
+	)
+	return i, err
+}
+
+const listPredictionsByPatientAsc = `-- name: ListPredictionsByPatientAsc :many
+SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version FROM predictions
 WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListPredictionsByPatientAscParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListPredictionsByPatientAsc(ctx context.Context, arg ListPredictionsByPatientAscParams) ([]Prediction, error) {
+	rows, err := q.db.Query(ctx, listPredictionsByPatientAsc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Prediction
+	for rows.Next() {
+		var i Prediction
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.Probability,
+			&i.RiskLabel,
+			&i.RawFeatures,
+			&i.CreatedAt,
+			&i.Factors,
+			&i.Explanation,
+			&i.ModelName,
+			&i.ModelVersion,
+			&i.FeatureSchemaHash,
+			&i.Warnings,
+			&i.FeaturesKeyVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPredictionsByPatientDesc = `-- name: ListPredictionsByPatientDesc :many
+SELECT id, patient_id, probability, risk_label, raw_features, created_at, factors, explanation, model_name, model_version, feature_schema_hash, warnings, features_key_version FROM predictions
+WHERE patient_id = $1
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-type ListPredictionsByPatientParams struct {
-	PatientID int64 `json:"patient_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+type ListPredictionsByPatientDescParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
 }
 
-func (q *Queries) ListPredictionsByPatient(ctx context.Context, arg ListPredictionsByPatientParams) ([]Prediction, error) {
-	rows, err := q.db.Query(ctx, listPredictionsByPatient, arg.PatientID, arg.Limit, arg.Offset)
+// Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListPredictionsByPatientDesc(ctx context.Context, arg ListPredictionsByPatientDescParams) ([]Prediction, error) {
+	rows, err := q.db.Query(ctx, listPredictionsByPatientDesc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +402,12 @@ func (q *Queries) ListPredictionsByPatient(ctx context.Context, arg ListPredicti
 			&i.RawFeatures,
 			&i.CreatedAt,
 			&i.Factors,
+			&i.Explanation,
+			&i.ModelName,
+			&i.ModelVersion,
+			&i.FeatureSchemaHash,
+			&i.Warnings,
+			&i.FeaturesKeyVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -178,3 +418,268 @@ func (q *Queries) ListPredictionsByPatient(ctx context.Context, arg ListPredicti
 	}
 	return items, nil
 }
+
+const listPredictionsForReencrypt = `-- name: ListPredictionsForReencrypt :many
+SELECT id, raw_features FROM predictions
+WHERE features_key_version <> $1
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListPredictionsForReencryptParams struct {
+	KeyVersion int32 `json:"key_version"`
+	AfterID    int64 `json:"after_id"`
+	Limit      int32 `json:"limit"`
+}
+
+type ListPredictionsForReencryptRow struct {
+	ID          int64  `json:"id"`
+	RawFeatures []byte `json:"raw_features"`
+}
+
+func (q *Queries) ListPredictionsForReencrypt(ctx context.Context, arg ListPredictionsForReencryptParams) ([]ListPredictionsForReencryptRow, error) {
+	rows, err := q.db.Query(ctx, listPredictionsForReencrypt, arg.KeyVersion, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPredictionsForReencryptRow
+	for rows.Next() {
+		var i ListPredictionsForReencryptRow
+		if err := rows.Scan(&i.ID, &i.RawFeatures); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPredictionsForRescore = `-- name: ListPredictionsForRescore :many
+SELECT pr.id, pr.patient_id, pr.probability, pr.risk_label, pr.raw_features, pr.created_at, pr.factors, pr.explanation, pr.model_name, pr.model_version, pr.feature_schema_hash, pr.warnings, pr.features_key_version FROM predictions pr
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = $1
+  AND pa.deleted_at IS NULL
+  AND ($2::bigint IS NULL OR pr.patient_id = $2::bigint)
+  AND NOT (pr.model_name = $3 AND pr.model_version = $4)
+  AND NOT EXISTS (
+    SELECT 1 FROM prediction_rescores rs
+    WHERE rs.prediction_id = pr.id
+      AND rs.model_name = $3
+      AND rs.model_version = $4
+  )
+ORDER BY pr.created_at DESC
+LIMIT $5
+`
+
+type ListPredictionsForRescoreParams struct {
+	UserID       string `json:"user_id"`
+	PatientID    *int64 `json:"patient_id"`
+	ModelName    string `json:"model_name"`
+	ModelVersion string `json:"model_version"`
+	Limit        int32  `json:"limit"`
+}
+
+func (q *Queries) ListPredictionsForRescore(ctx context.Context, arg ListPredictionsForRescoreParams) ([]Prediction, error) {
+	rows, err := q.db.Query(ctx, listPredictionsForRescore,
+		arg.UserID,
+		arg.PatientID,
+		arg.ModelName,
+		arg.ModelVersion,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Prediction
+	for rows.Next() {
+		var i Prediction
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.Probability,
+			&i.RiskLabel,
+			&i.RawFeatures,
+			&i.CreatedAt,
+			&i.Factors,
+			&i.Explanation,
+			&i.ModelName,
+			&i.ModelVersion,
+			&i.FeatureSchemaHash,
+			&i.Warnings,
+			&i.FeaturesKeyVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRescoresByPrediction = `-- name: ListRescoresByPrediction :many
+SELECT id, prediction_id, model_name, model_version, feature_schema_hash, probability, risk_label, created_at FROM prediction_rescores
+WHERE prediction_id = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListRescoresByPrediction(ctx context.Context, predictionID int64) ([]PredictionRescore, error) {
+	rows, err := q.db.Query(ctx, listRescoresByPrediction, predictionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PredictionRescore
+	for rows.Next() {
+		var i PredictionRescore
+		if err := rows.Scan(
+			&i.ID,
+			&i.PredictionID,
+			&i.ModelName,
+			&i.ModelVersion,
+			&i.FeatureSchemaHash,
+			&i.Probability,
+			&i.RiskLabel,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShadowConfusion = `-- name: ListShadowConfusion :many
+SELECT
+    pr.risk_label AS primary_label,
+    s.risk_label AS shadow_label,
+    COUNT(*) AS count
+FROM prediction_shadows s
+JOIN predictions pr ON pr.id = s.prediction_id
+JOIN patients pa ON pa.id = pr.patient_id
+WHERE pa.user_id = $1
+  AND pa.deleted_at IS NULL
+  AND s.created_at >= $2
+  AND s.created_at < $3
+  AND ($4::text IS NULL OR s.model_version = $4::text)
+  AND s.error = ''
+GROUP BY pr.risk_label, s.risk_label
+ORDER BY pr.risk_label, s.risk_label
+`
+
+type ListShadowConfusionParams struct {
+	UserID       string             `json:"user_id"`
+	FromTime     pgtype.Timestamptz `json:"from_time"`
+	ToTime       pgtype.Timestamptz `json:"to_time"`
+	ModelVersion *string            `json:"model_version"`
+}
+
+type ListShadowConfusionRow struct {
+	PrimaryLabel string `json:"primary_label"`
+	ShadowLabel  string `json:"shadow_label"`
+	Count        int64  `json:"count"`
+}
+
+func (q *Queries) ListShadowConfusion(ctx context.Context, arg ListShadowConfusionParams) ([]ListShadowConfusionRow, error) {
+	rows, err := q.db.Query(ctx, listShadowConfusion,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.ModelVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListShadowConfusionRow
+	for rows.Next() {
+		var i ListShadowConfusionRow
+		if err := rows.Scan(&i.PrimaryLabel, &i.ShadowLabel, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePredictionFeatures = `-- name: UpdatePredictionFeatures :exec
+UPDATE predictions
+SET
+    raw_features = $2,
+    features_key_version = $3
+WHERE id = $1
+`
+
+type UpdatePredictionFeaturesParams struct {
+	ID                 int64  `json:"id"`
+	RawFeatures        []byte `json:"raw_features"`
+	FeaturesKeyVersion int32  `json:"features_key_version"`
+}
+
+func (q *Queries) UpdatePredictionFeatures(ctx context.Context, arg UpdatePredictionFeaturesParams) error {
+	_, err := q.db.Exec(ctx, updatePredictionFeatures, arg.ID, arg.RawFeatures, arg.FeaturesKeyVersion)
+	return err
+}
+
+const upsertPredictionRescore = `-- name: UpsertPredictionRescore :one
+INSERT INTO prediction_rescores (
+    prediction_id,
+    model_name,
+    model_version,
+    feature_schema_hash,
+    probability,
+    risk_label
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (prediction_id, model_name, model_version) DO UPDATE
+SET
+    feature_schema_hash = EXCLUDED.feature_schema_hash,
+    probability = EXCLUDED.probability,
+    risk_label = EXCLUDED.risk_label,
+    created_at = NOW()
+RETURNING id, prediction_id, model_name, model_version, feature_schema_hash, probability, risk_label, created_at
+`
+
+type UpsertPredictionRescoreParams struct {
+	PredictionID      int64   `json:"prediction_id"`
+	ModelName         string  `json:"model_name"`
+	ModelVersion      string  `json:"model_version"`
+	FeatureSchemaHash string  `json:"feature_schema_hash"`
+	Probability       float64 `json:"probability"`
+	RiskLabel         string  `json:"risk_label"`
+}
+
+func (q *Queries) UpsertPredictionRescore(ctx context.Context, arg UpsertPredictionRescoreParams) (PredictionRescore, error) {
+	row := q.db.QueryRow(ctx, upsertPredictionRescore,
+		arg.PredictionID,
+		arg.ModelName,
+		arg.ModelVersion,
+		arg.FeatureSchemaHash,
+		arg.Probability,
+		arg.RiskLabel,
+	)
+	var i PredictionRescore
+	err := row.Scan(
+		&i.ID,
+		&i.PredictionID,
+		&i.ModelName,
+		&i.ModelVersion,
+		&i.FeatureSchemaHash,
+		&i.Probability,
+		&i.RiskLabel,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const countReportsByPatient = `-- name: CountReportsByPatient :one
@@ -83,21 +85,102 @@ func (q *Queries) GetReportByID(ctx context.Context, id int64) (Report, error) {
 	return i, err
 }
 
-const listReportsByPatient = `-- name: ListReportsByPatient :many
+const listReportFilesByPatient = `-- name: ListReportFilesByPatient :many
+SELECT file_url FROM reports
+WHERE patient_id = $1
+`
+
+func (q *Queries) ListReportFilesByPatient(ctx context.Context, patientID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, listReportFilesByPatient, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var file_url string
+		if err := rows.Scan(&file_url); err != nil {
+			return nil, err
+		}
+		items = append(items, file_url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReportsByPatientAsc = `-- name: ListReportsByPatientAsc :many
 SELECT id, patient_id, filename, file_url, recipients, created_at FROM reports
 WHERE patient_id = $1
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $3
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
 `
 
-type ListReportsByPatientParams struct {
-	PatientID int64 `json:"patient_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+type ListReportsByPatientAscParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
 }
 
-func (q *Queries) ListReportsByPatient(ctx context.Context, arg ListReportsByPatientParams) ([]Report, error) {
-	rows, err := q.db.Query(ctx, listReportsByPatient, arg.PatientID, arg.Limit, arg.Offset)
+// Keyset tăng dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListReportsByPatientAsc(ctx context.Context, arg ListReportsByPatientAscParams) ([]Report, error) {
+	rows, err := q.db.Query(ctx, listReportsByPatientAsc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Report
+	for rows.Next() {
+		var i Report
+		if err := rows.Scan(
+			&i.ID,
+			&i.PatientID,
+			&i.Filename,
+			&i.FileUrl,
+			&i.Recipients,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReportsByPatientDesc = `-- name: ListReportsByPatientDesc :many
+SELECT id, patient_id, filename, file_url, recipients, created_at FROM reports
+WHERE patient_id = $1
+  AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListReportsByPatientDescParams struct {
+	PatientID       int64              `json:"patient_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        int64              `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+}
+
+// Keyset giảm dần theo (created_at, id); trang đầu truyền mốc vô cực của pagination.Request (CursorTime/CursorID).
+func (q *Queries) ListReportsByPatientDesc(ctx context.Context, arg ListReportsByPatientDescParams) ([]Report, error) {
+	rows, err := q.db.Query(ctx, listReportsByPatientDesc,
+		arg.PatientID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
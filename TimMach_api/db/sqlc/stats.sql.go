@@ -7,38 +7,87 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const getRiskDistribution = `-- name: GetRiskDistribution :many
-WITH latest_predictions AS (
-    SELECT DISTINCT ON (p.patient_id) p.risk_label
-    FROM predictions p
-    JOIN patients pa ON p.patient_id = pa.id
-    WHERE pa.user_id = $1
-    ORDER BY p.patient_id, p.created_at DESC
+const countLatestPredictionDrift = `-- name: CountLatestPredictionDrift :one
+WITH expected AS (
+    SELECT DISTINCT ON (patient_id) patient_id, id, probability, risk_label, created_at
+    FROM predictions
+    ORDER BY patient_id, created_at DESC, id DESC
 )
-SELECT 
-    risk_label,
-    COUNT(*) AS count
-FROM latest_predictions
-GROUP BY risk_label
+SELECT
+    COUNT(*) FILTER (WHERE l.patient_id IS NULL) AS missing,
+    COUNT(*) FILTER (WHERE e.patient_id IS NULL) AS orphaned,
+    COUNT(*) FILTER (
+        WHERE e.patient_id IS NOT NULL
+          AND l.patient_id IS NOT NULL
+          AND (l.prediction_id <> e.id
+               OR l.probability <> e.probability
+               OR l.risk_label <> e.risk_label
+               OR l.created_at <> e.created_at)
+    ) AS stale
+FROM expected e
+FULL OUTER JOIN patient_latest_prediction l ON l.patient_id = e.patient_id
+`
+
+type CountLatestPredictionDriftRow struct {
+	Missing  int64 `json:"missing"`
+	Orphaned int64 `json:"orphaned"`
+	Stale    int64 `json:"stale"`
+}
+
+// So projection với prediction mới nhất tính trực tiếp từ predictions.
+func (q *Queries) CountLatestPredictionDrift(ctx context.Context) (CountLatestPredictionDriftRow, error) {
+	row := q.db.QueryRow(ctx, countLatestPredictionDrift)
+	var i CountLatestPredictionDriftRow
+	err := row.Scan(&i.Missing, &i.Orphaned, &i.Stale)
+	return i, err
+}
+
+const getNewPatientsTimeseries = `-- name: GetNewPatientsTimeseries :many
+SELECT
+    date_trunc($1::text, created_at, $2::text)::timestamptz AS bucket_start,
+    COUNT(*) AS new_patients
+FROM patients
+WHERE user_id = $3
+  AND deleted_at IS NULL
+  AND created_at >= $4::timestamptz
+  AND created_at < $5::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start
 `
 
-type GetRiskDistributionRow struct {
-	RiskLabel string `json:"risk_label"`
-	Count     int64  `json:"count"`
+type GetNewPatientsTimeseriesParams struct {
+	Bucket   string             `json:"bucket"`
+	Tz       string             `json:"tz"`
+	UserID   string             `json:"user_id"`
+	FromTime pgtype.Timestamptz `json:"from_time"`
+	ToTime   pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetNewPatientsTimeseriesRow struct {
+	BucketStart pgtype.Timestamptz `json:"bucket_start"`
+	NewPatients int64              `json:"new_patients"`
 }
 
-func (q *Queries) GetRiskDistribution(ctx context.Context, userID string) ([]GetRiskDistributionRow, error) {
-	rows, err := q.db.Query(ctx, getRiskDistribution, userID)
+func (q *Queries) GetNewPatientsTimeseries(ctx context.Context, arg GetNewPatientsTimeseriesParams) ([]GetNewPatientsTimeseriesRow, error) {
+	rows, err := q.db.Query(ctx, getNewPatientsTimeseries,
+		arg.Bucket,
+		arg.Tz,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetRiskDistributionRow
+	var items []GetNewPatientsTimeseriesRow
 	for rows.Next() {
-		var i GetRiskDistributionRow
-		if err := rows.Scan(&i.RiskLabel, &i.Count); err != nil {
+		var i GetNewPatientsTimeseriesRow
+		if err := rows.Scan(&i.BucketStart, &i.NewPatients); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -49,28 +98,355 @@ func (q *Queries) GetRiskDistribution(ctx context.Context, userID string) ([]Get
 	return items, nil
 }
 
-const getTotalPatients = `-- name: GetTotalPatients :one
-SELECT COUNT(*) FROM patients
-WHERE user_id = $1
+const getPredictionsTimeseries = `-- name: GetPredictionsTimeseries :many
+SELECT
+    date_trunc($1::text, p.created_at, $2::text)::timestamptz AS bucket_start,
+    COUNT(*) AS predictions,
+    AVG(p.probability)::float8 AS avg_probability
+FROM predictions p
+JOIN patients pa ON p.patient_id = pa.id
+WHERE pa.user_id = $3
+  AND pa.deleted_at IS NULL
+  AND p.created_at >= $4::timestamptz
+  AND p.created_at < $5::timestamptz
+GROUP BY bucket_start
+ORDER BY bucket_start
 `
 
-func (q *Queries) GetTotalPatients(ctx context.Context, userID string) (int64, error) {
-	row := q.db.QueryRow(ctx, getTotalPatients, userID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type GetPredictionsTimeseriesParams struct {
+	Bucket   string             `json:"bucket"`
+	Tz       string             `json:"tz"`
+	UserID   string             `json:"user_id"`
+	FromTime pgtype.Timestamptz `json:"from_time"`
+	ToTime   pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetPredictionsTimeseriesRow struct {
+	BucketStart    pgtype.Timestamptz `json:"bucket_start"`
+	Predictions    int64              `json:"predictions"`
+	AvgProbability float64            `json:"avg_probability"`
 }
 
-const getTotalPredictions = `-- name: GetTotalPredictions :one
-SELECT COUNT(*) 
+func (q *Queries) GetPredictionsTimeseries(ctx context.Context, arg GetPredictionsTimeseriesParams) ([]GetPredictionsTimeseriesRow, error) {
+	rows, err := q.db.Query(ctx, getPredictionsTimeseries,
+		arg.Bucket,
+		arg.Tz,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPredictionsTimeseriesRow
+	for rows.Next() {
+		var i GetPredictionsTimeseriesRow
+		if err := rows.Scan(&i.BucketStart, &i.Predictions, &i.AvgProbability); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRiskTimeseries = `-- name: GetRiskTimeseries :many
+SELECT
+    date_trunc($1::text, p.created_at, $2::text)::timestamptz AS bucket_start,
+    p.risk_label,
+    COUNT(*) AS count
 FROM predictions p
 JOIN patients pa ON p.patient_id = pa.id
-WHERE pa.user_id = $1
+WHERE pa.user_id = $3
+  AND pa.deleted_at IS NULL
+  AND p.created_at >= $4::timestamptz
+  AND p.created_at < $5::timestamptz
+GROUP BY bucket_start, p.risk_label
+ORDER BY bucket_start, p.risk_label
+`
+
+type GetRiskTimeseriesParams struct {
+	Bucket   string             `json:"bucket"`
+	Tz       string             `json:"tz"`
+	UserID   string             `json:"user_id"`
+	FromTime pgtype.Timestamptz `json:"from_time"`
+	ToTime   pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetRiskTimeseriesRow struct {
+	BucketStart pgtype.Timestamptz `json:"bucket_start"`
+	RiskLabel   string             `json:"risk_label"`
+	Count       int64              `json:"count"`
+}
+
+func (q *Queries) GetRiskTimeseries(ctx context.Context, arg GetRiskTimeseriesParams) ([]GetRiskTimeseriesRow, error) {
+	rows, err := q.db.Query(ctx, getRiskTimeseries,
+		arg.Bucket,
+		arg.Tz,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRiskTimeseriesRow
+	for rows.Next() {
+		var i GetRiskTimeseriesRow
+		if err := rows.Scan(&i.BucketStart, &i.RiskLabel, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserStatsRollup = `-- name: GetUserStatsRollup :one
+SELECT
+    r.user_id,
+    r.total_patients,
+    r.total_predictions,
+    r.high_risk,
+    r.medium_risk,
+    r.low_risk,
+    r.refreshed_at,
+    EXISTS (SELECT 1 FROM stats_dirty_users d WHERE d.user_id = r.user_id) AS dirty
+FROM user_stats_rollups r
+WHERE r.user_id = $1
+`
+
+type GetUserStatsRollupRow struct {
+	UserID           string             `json:"user_id"`
+	TotalPatients    int64              `json:"total_patients"`
+	TotalPredictions int64              `json:"total_predictions"`
+	HighRisk         int64              `json:"high_risk"`
+	MediumRisk       int64              `json:"medium_risk"`
+	LowRisk          int64              `json:"low_risk"`
+	RefreshedAt      pgtype.Timestamptz `json:"refreshed_at"`
+	Dirty            bool               `json:"dirty"`
+}
+
+func (q *Queries) GetUserStatsRollup(ctx context.Context, userID string) (GetUserStatsRollupRow, error) {
+	row := q.db.QueryRow(ctx, getUserStatsRollup, userID)
+	var i GetUserStatsRollupRow
+	err := row.Scan(
+		&i.UserID,
+		&i.TotalPatients,
+		&i.TotalPredictions,
+		&i.HighRisk,
+		&i.MediumRisk,
+		&i.LowRisk,
+		&i.RefreshedAt,
+		&i.Dirty,
+	)
+	return i, err
+}
+
+const listDirtyStatsUsers = `-- name: ListDirtyStatsUsers :many
+SELECT user_id FROM stats_dirty_users
+ORDER BY marked_at
+LIMIT $1
+`
+
+func (q *Queries) ListDirtyStatsUsers(ctx context.Context, limit int32) ([]string, error) {
+	rows, err := q.db.Query(ctx, listDirtyStatsUsers, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var user_id string
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestPredictionFactors = `-- name: ListLatestPredictionFactors :many
+WITH latest AS (
+    SELECT
+        l.patient_id,
+        l.probability,
+        p.factors,
+        l.created_at,
+        pa.gender,
+        pa.birth_year
+    FROM patient_latest_prediction l
+    JOIN patients pa ON pa.id = l.patient_id
+    JOIN predictions p ON p.id = l.prediction_id
+    WHERE pa.user_id = $1
+      AND pa.deleted_at IS NULL
+)
+SELECT
+    l.patient_id,
+    l.probability,
+    l.created_at,
+    l.gender,
+    l.birth_year,
+    COALESCE(
+        (SELECT array_agg(DISTINCT f->>'field' ORDER BY f->>'field')
+         FROM jsonb_array_elements(
+             CASE WHEN jsonb_typeof(l.factors) = 'array' THEN l.factors ELSE '[]'::jsonb END
+         ) f
+         WHERE f->>'field' IS NOT NULL),
+        '{}'
+    )::text[] AS factor_fields
+FROM latest l
+ORDER BY l.patient_id
+`
+
+type ListLatestPredictionFactorsRow struct {
+	PatientID    int64              `json:"patient_id"`
+	Probability  float64            `json:"probability"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Gender       int16              `json:"gender"`
+	BirthYear    *int16             `json:"birth_year"`
+	FactorFields []string           `json:"factor_fields"`
+}
+
+// Prediction mới nhất của từng bệnh nhân kèm danh sách field trong factors (không trùng, đã sắp xếp).
+func (q *Queries) ListLatestPredictionFactors(ctx context.Context, userID string) ([]ListLatestPredictionFactorsRow, error) {
+	rows, err := q.db.Query(ctx, listLatestPredictionFactors, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLatestPredictionFactorsRow
+	for rows.Next() {
+		var i ListLatestPredictionFactorsRow
+		if err := rows.Scan(
+			&i.PatientID,
+			&i.Probability,
+			&i.CreatedAt,
+			&i.Gender,
+			&i.BirthYear,
+			&i.FactorFields,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllStatsDirty = `-- name: MarkAllStatsDirty :execrows
+INSERT INTO stats_dirty_users (user_id)
+SELECT id FROM users
+ON CONFLICT (user_id) DO UPDATE SET marked_at = NOW()
+`
+
+func (q *Queries) MarkAllStatsDirty(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, markAllStatsDirty)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rebuildPatientLatestPrediction = `-- name: RebuildPatientLatestPrediction :execrows
+WITH expected AS (
+    SELECT DISTINCT ON (patient_id) patient_id, id, probability, risk_label, created_at
+    FROM predictions
+    ORDER BY patient_id, created_at DESC, id DESC
+),
+removed AS (
+    DELETE FROM patient_latest_prediction l
+    WHERE NOT EXISTS (SELECT 1 FROM expected e WHERE e.patient_id = l.patient_id)
+)
+INSERT INTO patient_latest_prediction (patient_id, prediction_id, probability, risk_label, created_at)
+SELECT patient_id, id, probability, risk_label, created_at FROM expected
+ON CONFLICT (patient_id) DO UPDATE
+SET
+    prediction_id = EXCLUDED.prediction_id,
+    probability = EXCLUDED.probability,
+    risk_label = EXCLUDED.risk_label,
+    created_at = EXCLUDED.created_at,
+    updated_at = NOW()
+WHERE patient_latest_prediction.prediction_id <> EXCLUDED.prediction_id
+   OR patient_latest_prediction.probability <> EXCLUDED.probability
+   OR patient_latest_prediction.risk_label <> EXCLUDED.risk_label
+   OR patient_latest_prediction.created_at <> EXCLUDED.created_at
+`
+
+// Ghi lại các row thiếu/sai của projection; row không còn prediction tương ứng bị xoá.
+func (q *Queries) RebuildPatientLatestPrediction(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, rebuildPatientLatestPrediction)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const refreshUserStatsRollup = `-- name: RefreshUserStatsRollup :one
+WITH cleared AS (
+    DELETE FROM stats_dirty_users
+    WHERE user_id = $1::text
+      AND marked_at <= NOW()
+),
+pa AS (
+    SELECT id
+    FROM patients
+    WHERE user_id = $1::text
+      AND deleted_at IS NULL
+)
+INSERT INTO user_stats_rollups (
+    user_id,
+    total_patients,
+    total_predictions,
+    high_risk,
+    medium_risk,
+    low_risk,
+    refreshed_at
+)
+SELECT
+    $1::text,
+    (SELECT COUNT(*) FROM pa),
+    (SELECT COUNT(*) FROM predictions pr JOIN pa ON pa.id = pr.patient_id),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'high'),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'medium'),
+    COUNT(*) FILTER (WHERE lower(l.risk_label) = 'low'),
+    NOW()
+FROM pa
+JOIN patient_latest_prediction l ON l.patient_id = pa.id
+ON CONFLICT (user_id) DO UPDATE
+SET
+    total_patients = EXCLUDED.total_patients,
+    total_predictions = EXCLUDED.total_predictions,
+    high_risk = EXCLUDED.high_risk,
+    medium_risk = EXCLUDED.medium_risk,
+    low_risk = EXCLUDED.low_risk,
+    refreshed_at = EXCLUDED.refreshed_at
+RETURNING user_id, total_patients, total_predictions, high_risk, medium_risk, low_risk, refreshed_at
 `
 
-func (q *Queries) GetTotalPredictions(ctx context.Context, userID string) (int64, error) {
-	row := q.db.QueryRow(ctx, getTotalPredictions, userID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+// Tính lại rollup của user từ patient_latest_prediction rồi gỡ đánh dấu có trước câu lệnh này.
+func (q *Queries) RefreshUserStatsRollup(ctx context.Context, userID string) (UserStatsRollup, error) {
+	row := q.db.QueryRow(ctx, refreshUserStatsRollup, userID)
+	var i UserStatsRollup
+	err := row.Scan(
+		&i.UserID,
+		&i.TotalPatients,
+		&i.TotalPredictions,
+		&i.HighRisk,
+		&i.MediumRisk,
+		&i.LowRisk,
+		&i.RefreshedAt,
+	)
+	return i, err
 }
@@ -13,7 +13,7 @@ const attachKeycloakID = `-- name: AttachKeycloakID :one
 UPDATE users
 SET keycloak_id = $2
 WHERE id = $1
-RETURNING id, email, created_at, keycloak_id
+RETURNING id, email, created_at, keycloak_id, clinic
 `
 
 type AttachKeycloakIDParams struct {
@@ -29,6 +29,7 @@ func (q *Queries) AttachKeycloakID(ctx context.Context, arg AttachKeycloakIDPara
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
@@ -36,7 +37,7 @@ func (q *Queries) AttachKeycloakID(ctx context.Context, arg AttachKeycloakIDPara
 const createKeycloakUser = `-- name: CreateKeycloakUser :one
 INSERT INTO users (id, email, keycloak_id)
 VALUES ($1, $2, $3)
-RETURNING id, email, created_at, keycloak_id
+RETURNING id, email, created_at, keycloak_id, clinic
 `
 
 type CreateKeycloakUserParams struct {
@@ -53,6 +54,7 @@ func (q *Queries) CreateKeycloakUser(ctx context.Context, arg CreateKeycloakUser
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
@@ -60,7 +62,7 @@ func (q *Queries) CreateKeycloakUser(ctx context.Context, arg CreateKeycloakUser
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, email, keycloak_id)
 VALUES ($1, $2, $3)
-RETURNING id, email, created_at, keycloak_id
+RETURNING id, email, created_at, keycloak_id, clinic
 `
 
 type CreateUserParams struct {
@@ -77,12 +79,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, created_at, keycloak_id FROM users
+SELECT id, email, created_at, keycloak_id, clinic FROM users
 WHERE email = $1
 LIMIT 1
 `
@@ -95,12 +98,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, created_at, keycloak_id FROM users
+SELECT id, email, created_at, keycloak_id, clinic FROM users
 WHERE id = $1
 LIMIT 1
 `
@@ -113,12 +117,13 @@ func (q *Queries) GetUserByID(ctx context.Context, id string) (User, error) {
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
 
 const getUserByKeycloakID = `-- name: GetUserByKeycloakID :one
-SELECT id, email, created_at, keycloak_id FROM users
+SELECT id, email, created_at, keycloak_id, clinic FROM users
 WHERE keycloak_id = $1
 LIMIT 1
 `
@@ -131,12 +136,13 @@ func (q *Queries) GetUserByKeycloakID(ctx context.Context, keycloakID *string) (
 		&i.Email,
 		&i.CreatedAt,
 		&i.KeycloakID,
+		&i.Clinic,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, created_at, keycloak_id FROM users
+SELECT id, email, created_at, keycloak_id, clinic FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -160,6 +166,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Email,
 			&i.CreatedAt,
 			&i.KeycloakID,
+			&i.Clinic,
 		); err != nil {
 			return nil, err
 		}
@@ -181,3 +188,28 @@ func (q *Queries) NextUserSeq(ctx context.Context) (int64, error) {
 	err := row.Scan(&column_1)
 	return column_1, err
 }
+
+const setUserClinic = `-- name: SetUserClinic :one
+UPDATE users
+SET clinic = $2
+WHERE id = $1
+RETURNING id, email, created_at, keycloak_id, clinic
+`
+
+type SetUserClinicParams struct {
+	ID     string `json:"id"`
+	Clinic string `json:"clinic"`
+}
+
+func (q *Queries) SetUserClinic(ctx context.Context, arg SetUserClinicParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserClinic, arg.ID, arg.Clinic)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.CreatedAt,
+		&i.KeycloakID,
+		&i.Clinic,
+	)
+	return i, err
+}
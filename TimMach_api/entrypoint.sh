@@ -9,5 +9,12 @@ fi
 echo "Running migrations..."
 goose -dir /app/db/migrations postgres "$DB_URL" up
 
+# Nạp catalog bài tập mặc định; template đã có (cùng slug) được giữ nguyên.
+# Seed lỗi không chặn API khởi động: catalog có thể import lại qua POST /exercise-templates/import.
+echo "Seeding exercise catalog..."
+if ! /app/seed -file /app/db/seeds/exercise_catalog.yaml; then
+  echo "Seeding exercise catalog failed, starting API anyway" >&2
+fi
+
 echo "Starting API..."
 exec "$@"
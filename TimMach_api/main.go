@@ -7,15 +7,23 @@ import (
 	"chidinh/config"
 	db "chidinh/db/sqlc"
 	"chidinh/middleware"
+	"chidinh/modules/analytics"
+	"chidinh/modules/cohorts"
+	"chidinh/modules/datarequests"
+	"chidinh/modules/emails"
 	"chidinh/modules/exercises"
+	"chidinh/modules/outcomes"
 	"chidinh/modules/patients"
 	"chidinh/modules/predictions"
 	"chidinh/modules/reports"
+	"chidinh/modules/schedules"
 	"chidinh/modules/stats"
 	"chidinh/modules/users"
 	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
 	"chidinh/utils/httpclient"
 	"chidinh/utils/mailer"
+	"chidinh/utils/mlscorer"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,6 +42,15 @@ func main() {
 		logger.Fatalw("cannot parse env", "error", err)
 	}
 
+	keyring, err := cfg.FieldKeyring()
+	if err != nil {
+		logger.Fatalw("cannot load field encryption keys", "error", err)
+	}
+	if !keyring.Enabled() {
+		logger.Warnw("FIELD_ENCRYPTION_KEYS not set, patient PII is stored in plaintext")
+	}
+	fieldcrypt.SetDefault(keyring)
+
 	// Init DB
 	pool, err := pgxpool.New(context.Background(), cfg.DBURL)
 	if err != nil {
@@ -44,15 +61,57 @@ func main() {
 
 	// Services
 	mlHTTPClient := httpclient.NewRestyClient(cfg.MLBaseURL, 10*time.Second)
+	var mlScorer *mlscorer.Model
+	if err := cfg.ValidateMLScorer(); err != nil {
+		logger.Fatalw("invalid ml scorer config", "error", err)
+	}
+	if cfg.MLModelPath != "" {
+		mlScorer, err = mlscorer.Load(cfg.MLModelPath)
+		if err != nil && cfg.MLScorerMode == predictions.ScorerLocal {
+			logger.Fatalw("cannot load in-process ml model", "error", err, "path", cfg.MLModelPath)
+		}
+		if err != nil {
+			logger.Warnw("cannot load in-process ml model, fallback disabled", "error", err, "path", cfg.MLModelPath)
+		}
+	}
+	var mlShadow *predictions.Shadow
+	if cfg.MLShadowBaseURL != "" {
+		mlShadow = predictions.NewShadow(httpclient.NewRestyClient(cfg.MLShadowBaseURL, 10*time.Second), cfg.MLShadowPercent)
+	}
 	mailerSvc := mailer.New(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.SMTPFrom)
+	brandings := emails.NewBrandings(queries, cfg.ClinicName)
+	outbox := emails.NewOutbox(queries, cfg.EmailMaxAttempts)
 
 	// Handlers
 	userController := users.NewController(queries)
 	patientController := patients.NewController(queries)
-	reportController := reports.NewController(queries, mailerSvc)
-	predictionController := predictions.NewController(queries, mlHTTPClient)
-	exerciseController := exercises.NewController(queries)
+	reportController := reports.NewController(queries, mailerSvc, brandings, outbox)
+	predictionController := predictions.NewController(queries, mlHTTPClient, mlScorer, cfg.MLScorerMode, mlShadow)
+	exerciseController := exercises.NewController(pool, queries)
 	statsController := stats.NewController(queries)
+	dataRequestController := datarequests.NewController(queries)
+	outcomeController := outcomes.NewController(queries)
+	analyticsController := analytics.NewController(queries, cfg.AnalyticsPseudonymSalt)
+	cohortController := cohorts.NewController(queries, brandings)
+	emailController := emails.NewController(queries, brandings)
+	scheduleController := schedules.NewController(queries)
+
+	// Background jobs
+	go patients.BackfillNameIndex(context.Background(), queries)
+	if cfg.PatientRetentionDays > 0 {
+		purger := patients.NewPurger(queries, time.Duration(cfg.PatientRetentionDays)*24*time.Hour)
+		go purger.Run(context.Background(), cfg.RetentionInterval)
+	}
+	if cfg.StatsRefreshInterval > 0 {
+		go stats.NewRefresher(queries).Run(context.Background(), cfg.StatsRefreshInterval)
+	}
+	if cfg.EmailSendInterval > 0 && mailerSvc.Enabled() {
+		go emails.NewSender(queries, mailerSvc, cfg.EmailRatePerMinute).Run(context.Background(), cfg.EmailSendInterval)
+	}
+	if cfg.ScheduleInterval > 0 && mailerSvc.Enabled() {
+		scheduler := schedules.NewScheduler(pool, queries, reportController, outbox, brandings)
+		go scheduler.Run(context.Background(), cfg.ScheduleInterval)
+	}
 
 	// Router
 	router := gin.Default()
@@ -66,6 +125,15 @@ func main() {
 	exercises.RegisterExerciseRoutes(api, exerciseController)
 	reports.RegisterReportRoutes(api, reportController)
 	stats.RegisterStatsRoutes(api, statsController)
+	datarequests.RegisterDataRequestRoutes(api, dataRequestController)
+	outcomes.RegisterOutcomeRoutes(api, outcomeController)
+	cohorts.RegisterCohortRoutes(api, cohortController)
+	emails.RegisterEmailRoutes(api, emailController)
+	schedules.RegisterScheduleRoutes(api, scheduleController)
+
+	admin := api.Group("", middleware.RequireRole(cfg.AdminRoleList()...))
+	analytics.RegisterAnalyticsRoutes(admin, analyticsController)
+	emails.RegisterBrandingRoutes(admin, emailController)
 
 	if err := router.Run(":" + cfg.Port); err != nil {
 		logger.Fatalw("server exited", "error", err)
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -50,12 +51,14 @@ func DevKeycloakMapper(queries *db.Queries) gin.HandlerFunc {
 			return
 		}
 		fmt.Println("Subject:", sub)
+		c.Set("keycloak_roles", realmRoles(claims))
 
 		ctx := c.Request.Context()
 
 		user, err := queries.GetUserByKeycloakID(ctx, &sub)
 		switch {
 		case err == nil:
+			syncClinic(ctx, queries, user, claims)
 			c.Set("userID", user.ID)
 			c.Set("userEmail", user.Email)
 			c.Next()
@@ -89,6 +92,7 @@ func DevKeycloakMapper(queries *db.Queries) gin.HandlerFunc {
 					c.Abort()
 					return
 				}
+				syncClinic(ctx, queries, user, claims)
 				c.Set("userID", user.ID)
 				c.Set("userEmail", user.Email)
 			case errors.Is(err, pgx.ErrNoRows):
@@ -109,6 +113,7 @@ func DevKeycloakMapper(queries *db.Queries) gin.HandlerFunc {
 					c.Abort()
 					return
 				}
+				syncClinic(ctx, queries, user, claims)
 				c.Set("userID", user.ID)
 				c.Set("userEmail", user.Email)
 			default:
@@ -125,3 +130,29 @@ func DevKeycloakMapper(queries *db.Queries) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// realmRoles đọc realm_access.roles của Keycloak (thiếu claim thì trả rỗng).
+func realmRoles(claims jwt.MapClaims) []string {
+	access, _ := claims["realm_access"].(map[string]any)
+	raw, _ := access["roles"].([]any)
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok && s != "" {
+			roles = append(roles, s)
+		}
+	}
+	return roles
+}
+
+// syncClinic cập nhật users.clinic theo claim "clinic" (user attribute mapper của Keycloak);
+// thiếu claim thì giữ nguyên giá trị đang có.
+func syncClinic(ctx context.Context, queries *db.Queries, user db.User, claims jwt.MapClaims) {
+	clinic, _ := claims["clinic"].(string)
+	clinic = strings.TrimSpace(clinic)
+	if clinic == "" || clinic == user.Clinic {
+		return
+	}
+	if _, err := queries.SetUserClinic(ctx, db.SetUserClinicParams{ID: user.ID, Clinic: clinic}); err != nil {
+		utils.L().Warnw("cannot sync user clinic", "error", err, "user_id", user.ID)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"chidinh/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole chỉ cho request đi tiếp khi user có ít nhất một trong các realm role cho phép.
+// Phải đặt sau middleware auth (DevKeycloakMapper) để đã có keycloak_roles trong context.
+func RequireRole(allowed ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, role := range utils.RolesFromContext(c) {
+			if slices.Contains(allowed, role) {
+				c.Next()
+				return
+			}
+		}
+		utils.RespondError(c, http.StatusForbidden, "insufficient role")
+	}
+}
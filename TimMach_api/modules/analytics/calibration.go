@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/outcomes"
+)
+
+// scored là một prediction đã gắn nhãn (label nil = chưa có kết cục).
+type scored struct {
+	probability float64
+	label       *int
+}
+
+// BuildCalibration gắn nhãn predictions bằng outcomes rồi tính AUC, Brier và reliability
+// cho toàn bộ, theo model_version và theo phòng khám (users.clinic).
+func BuildCalibration(ctx context.Context, q *db.Queries, from, to *time.Time, horizonDays, bins int) (CalibrationResponse, error) {
+	resp := CalibrationResponse{HorizonDays: horizonDays}
+
+	events, err := outcomes.LoadEvents(ctx, q)
+	if err != nil {
+		return resp, err
+	}
+
+	var all []scored
+	byModel := make(map[string][]scored)
+	byClinic := make(map[string][]scored)
+	err = forEachPrediction(ctx, q, from, to, func(p db.ListPredictionsForAnalyticsRow) {
+		s := scored{
+			probability: p.Probability,
+			label:       outcomes.Label(events[p.PatientID], p.CreatedAt.Time, horizonDays),
+		}
+		all = append(all, s)
+		byModel[groupKey(p.ModelVersion)] = append(byModel[groupKey(p.ModelVersion)], s)
+		byClinic[groupKey(p.Clinic)] = append(byClinic[groupKey(p.Clinic)], s)
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Overall = computeMetrics("", all, bins)
+	resp.ByModelVersion = groupMetrics(byModel, bins)
+	resp.ByClinic = groupMetrics(byClinic, bins)
+	return resp, nil
+}
+
+func groupKey(v string) string {
+	if v == "" {
+		return unassignedGroup
+	}
+	return v
+}
+
+func groupMetrics(groups map[string][]scored, bins int) []ModelMetrics {
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]ModelMetrics, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, computeMetrics(k, groups[k], bins))
+	}
+	return out
+}
+
+func computeMetrics(group string, items []scored, bins int) ModelMetrics {
+	m := ModelMetrics{Group: group, Predictions: len(items)}
+
+	var probs []float64
+	var labels []int
+	for _, s := range items {
+		if s.label == nil {
+			continue
+		}
+		probs = append(probs, s.probability)
+		labels = append(labels, *s.label)
+	}
+	m.Labeled = len(probs)
+	m.Reliability = reliability(probs, labels, bins)
+	if m.Labeled == 0 {
+		return m
+	}
+
+	var sumP, sumSq float64
+	for i, p := range probs {
+		m.Positives += labels[i]
+		sumP += p
+		sumSq += (p - float64(labels[i])) * (p - float64(labels[i]))
+	}
+	n := float64(m.Labeled)
+	m.Prevalence = ratio(float64(m.Positives), n)
+	m.MeanPredicted = ratio(sumP, n)
+	m.Brier = ratio(sumSq, n)
+	m.AUC = auc(probs, labels)
+	return m
+}
+
+// auc tính diện tích dưới ROC theo thống kê Mann-Whitney (rank trung bình cho các giá trị bằng nhau).
+func auc(probs []float64, labels []int) *float64 {
+	idx := make([]int, len(probs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return probs[idx[a]] < probs[idx[b]] })
+
+	var pos, neg, rankSum float64
+	for i := 0; i < len(idx); {
+		j := i
+		for j < len(idx) && probs[idx[j]] == probs[idx[i]] {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2 // rank bắt đầu từ 1
+		for k := i; k < j; k++ {
+			if labels[idx[k]] == 1 {
+				pos++
+				rankSum += avgRank
+			} else {
+				neg++
+			}
+		}
+		i = j
+	}
+	if pos == 0 || neg == 0 {
+		return nil
+	}
+	return round4((rankSum - pos*(pos+1)/2) / (pos * neg))
+}
+
+// reliability chia [0, 1] thành bins khoảng đều nhau; xác suất 1.0 rơi vào khoảng cuối.
+func reliability(probs []float64, labels []int, bins int) []ReliabilityBin {
+	if bins <= 0 {
+		bins = defaultBins
+	}
+	out := make([]ReliabilityBin, bins)
+	sums := make([]float64, bins)
+	positives := make([]int, bins)
+	for i := range out {
+		out[i].Lower = round(float64(i) / float64(bins))
+		out[i].Upper = round(float64(i+1) / float64(bins))
+	}
+	for i, p := range probs {
+		b := int(math.Floor(p * float64(bins)))
+		b = min(max(b, 0), bins-1)
+		out[b].Count++
+		sums[b] += p
+		positives[b] += labels[i]
+	}
+	for i := range out {
+		if out[i].Count == 0 {
+			continue
+		}
+		out[i].MeanPredicted = ratio(sums[i], float64(out[i].Count))
+		out[i].ObservedRate = ratio(float64(positives[i]), float64(out[i].Count))
+	}
+	return out
+}
+
+func ratio(a, b float64) *float64 {
+	return round4(a / b)
+}
+
+func round4(v float64) *float64 {
+	r := round(v)
+	return &r
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
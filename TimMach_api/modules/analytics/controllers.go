@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/outcomes"
+	"chidinh/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Controller gom dependency cho module analytics (chỉ dành cho admin).
+type Controller struct {
+	Queries *db.Queries
+	Salt    string
+}
+
+func NewController(queries *db.Queries, salt string) *Controller {
+	return &Controller{Queries: queries, Salt: salt}
+}
+
+// GET /analytics/training-export
+func (h *Controller) TrainingExport(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req TrainingExportParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	opts, err := ParseExportOptions(req, h.Salt)
+	if errors.Is(err, errNoSalt) {
+		utils.RespondError(c, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	export, err := BuildTrainingExport(c, h.Queries, opts)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot build training export")
+		return
+	}
+
+	utils.L().Infow("analytics training export",
+		"user_id", userID,
+		"rows", len(export.Rows),
+		"labeled", export.Labeled,
+		"suppressed", export.Suppressed,
+		"skipped", export.Skipped,
+		"k", export.K,
+	)
+
+	filename := fmt.Sprintf("training_export_%s.csv", time.Now().Format("20060102"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("X-Export-Rows", strconv.Itoa(len(export.Rows)))
+	c.Header("X-Export-Suppressed", strconv.Itoa(export.Suppressed))
+	c.Header("X-Export-Labeled", strconv.Itoa(export.Labeled))
+	c.Status(http.StatusOK)
+	if err := export.WriteCSV(c.Writer); err != nil {
+		utils.L().Warnw("analytics export: write csv failed", "error", err)
+	}
+}
+
+// GET /analytics/calibration
+func (h *Controller) Calibration(c *gin.Context) {
+	var req CalibrationParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	from, to, err := parseRange(req.From, req.To)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	horizon := outcomes.DefaultHorizonDays
+	if req.HorizonDays != nil {
+		horizon = *req.HorizonDays
+	}
+	bins := defaultBins
+	if req.Bins != nil {
+		bins = *req.Bins
+	}
+
+	resp, err := BuildCalibration(c, h.Queries, from, to, horizon, bins)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot compute calibration")
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"chidinh/modules/outcomes"
+	"chidinh/modules/predictions"
+)
+
+var errNoSalt = errors.New("ANALYTICS_PSEUDONYM_SALT is not configured")
+
+// ParseExportOptions kiểm tra params (từ query hoặc flag của cmd/analyticsexport); to được cộng 1 ngày để tính cả ngày cuối.
+func ParseExportOptions(req TrainingExportParams, salt string) (ExportOptions, error) {
+	opts := ExportOptions{K: defaultK, HorizonDays: outcomes.DefaultHorizonDays, Salt: salt}
+	if salt == "" {
+		return opts, errNoSalt
+	}
+	if req.K != nil {
+		opts.K = *req.K
+	}
+	if req.HorizonDays != nil {
+		opts.HorizonDays = *req.HorizonDays
+	}
+	var err error
+	opts.From, opts.To, err = parseRange(req.From, req.To)
+	return opts, err
+}
+
+// parseRange đọc from/to (YYYY-MM-DD); to trả về là exclusive (ngày cuối + 1).
+func parseRange(fromStr, toStr string) (from, to *time.Time, err error) {
+	if fromStr != "" {
+		t, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return nil, nil, errors.New("invalid from, expected YYYY-MM-DD")
+		}
+		from = &t
+	}
+	if toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return nil, nil, errors.New("invalid to, expected YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// pseudonym thay patient id bằng HMAC có salt bí mật: ổn định giữa các lần export
+// (gom được nhiều lần đo của cùng bệnh nhân) nhưng không đảo ngược được nếu không có salt.
+func pseudonym(salt string, patientID int64) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(strconv.FormatInt(patientID, 10)))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// ageBucket gom tuổi thành nhóm 5 năm; age trong CSV là điểm giữa nhóm tính bằng ngày
+// để pipeline huấn luyện (age / 365.25) dùng được như dataset gốc.
+func ageBucket(ageYears float64) (label string, days int) {
+	lo := int(math.Floor(ageYears/ageBucketYears)) * ageBucketYears
+	if lo < 0 {
+		lo = 0
+	}
+	if lo >= ageTopCode {
+		return fmt.Sprintf("%d+", ageTopCode), int(math.Round((ageTopCode + float64(ageBucketYears)/2) * 365.25))
+	}
+	label = fmt.Sprintf("%d-%d", lo, lo+ageBucketYears-1)
+	return label, int(math.Round((float64(lo) + float64(ageBucketYears)/2) * 365.25))
+}
+
+func toTrainingRow(patient string, f predictions.MLRequest) TrainingRow {
+	group, days := ageBucket(f.AgeYears)
+	return TrainingRow{
+		Patient:     patient,
+		AgeGroup:    group,
+		AgeDays:     days,
+		Gender:      f.Gender,
+		Height:      f.Height,
+		Weight:      f.Weight,
+		APHi:        f.APHi,
+		APLo:        f.APLo,
+		Cholesterol: f.Cholesterol,
+		Gluc:        f.Gluc,
+		Smoke:       f.Smoke,
+		Alco:        f.Alco,
+		Active:      f.Active,
+	}
+}
+
+func toCSVRecord(index int, r TrainingRow) []string {
+	cardio := ""
+	if r.Cardio != nil {
+		cardio = strconv.Itoa(*r.Cardio)
+	}
+	return []string{
+		strconv.Itoa(index),
+		strconv.Itoa(r.AgeDays),
+		strconv.Itoa(r.Gender),
+		strconv.FormatFloat(r.Height, 'f', -1, 64),
+		strconv.FormatFloat(r.Weight, 'f', 1, 64),
+		strconv.Itoa(r.APHi),
+		strconv.Itoa(r.APLo),
+		strconv.Itoa(r.Cholesterol),
+		strconv.Itoa(r.Gluc),
+		strconv.Itoa(r.Smoke),
+		strconv.Itoa(r.Alco),
+		strconv.Itoa(r.Active),
+		cardio,
+		r.Patient,
+		r.AgeGroup,
+	}
+}
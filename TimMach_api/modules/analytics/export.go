@@ -0,0 +1,129 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/outcomes"
+	"chidinh/modules/predictions"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const exportBatchSize = 500
+
+// forEachPrediction duyệt prediction của bệnh nhân chưa xoá trong [from, to) theo batch keyset.
+func forEachPrediction(ctx context.Context, q *db.Queries, from, to *time.Time, fn func(db.ListPredictionsForAnalyticsRow)) error {
+	params := db.ListPredictionsForAnalyticsParams{Limit: exportBatchSize}
+	if from != nil {
+		params.FromTime = pgtype.Timestamptz{Time: *from, Valid: true}
+	}
+	if to != nil {
+		params.ToTime = pgtype.Timestamptz{Time: *to, Valid: true}
+	}
+	for {
+		batch, err := q.ListPredictionsForAnalytics(ctx, params)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		for _, p := range batch {
+			params.AfterID = p.ID
+			fn(p)
+		}
+	}
+}
+
+// BuildTrainingExport đọc prediction theo batch, giải mã raw_features, gắn nhãn cardio từ outcomes,
+// thay danh tính bằng pseudonym, gom nhóm tuổi rồi loại các nhóm (nhóm tuổi, giới tính) có ít hơn K bệnh nhân.
+// Dùng chung cho endpoint admin và cmd/analyticsexport.
+func BuildTrainingExport(ctx context.Context, q *db.Queries, opts ExportOptions) (TrainingExport, error) {
+	out := TrainingExport{K: opts.K}
+	if opts.Salt == "" {
+		return out, errNoSalt
+	}
+	if out.K < minK {
+		out.K = minK
+	}
+	if opts.HorizonDays <= 0 {
+		opts.HorizonDays = outcomes.DefaultHorizonDays
+	}
+
+	events, err := outcomes.LoadEvents(ctx, q)
+	if err != nil {
+		return out, err
+	}
+
+	var rows []TrainingRow
+	err = forEachPrediction(ctx, q, opts.From, opts.To, func(p db.ListPredictionsForAnalyticsRow) {
+		out.Total++
+		features, err := predictions.DecodeStoredFeatures(p.RawFeatures)
+		if err != nil {
+			utils.L().Warnw("analytics export: cannot decode raw_features", "error", err, "prediction_id", p.ID)
+			out.Skipped++
+			return
+		}
+		row := toTrainingRow(pseudonym(opts.Salt, p.PatientID), features)
+		row.Cardio = outcomes.Label(events[p.PatientID], p.CreatedAt.Time, opts.HorizonDays)
+		rows = append(rows, row)
+	})
+	if err != nil {
+		return out, err
+	}
+
+	out.Rows, out.Suppressed = enforceKAnonymity(rows, out.K)
+	for _, r := range out.Rows {
+		if r.Cardio != nil {
+			out.Labeled++
+		}
+	}
+	return out, nil
+}
+
+type quasiID struct {
+	ageGroup string
+	gender   int
+}
+
+// enforceKAnonymity xoá (suppression) mọi dòng thuộc nhóm quasi-identifier có ít hơn k bệnh nhân.
+// Đếm theo pseudonym chứ không theo dòng, vì một bệnh nhân có thể có nhiều prediction.
+func enforceKAnonymity(rows []TrainingRow, k int) ([]TrainingRow, int) {
+	patients := make(map[quasiID]map[string]struct{})
+	for _, r := range rows {
+		key := quasiID{r.AgeGroup, r.Gender}
+		if patients[key] == nil {
+			patients[key] = make(map[string]struct{})
+		}
+		patients[key][r.Patient] = struct{}{}
+	}
+
+	kept := make([]TrainingRow, 0, len(rows))
+	for _, r := range rows {
+		if len(patients[quasiID{r.AgeGroup, r.Gender}]) >= k {
+			kept = append(kept, r)
+		}
+	}
+	return kept, len(rows) - len(kept)
+}
+
+// WriteCSV ghi export theo định dạng cardio_train.csv (phân cách ";"); id là số thứ tự dòng.
+func (e TrainingExport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	if err := cw.Write(trainingColumns); err != nil {
+		return err
+	}
+	for i, r := range e.Rows {
+		if err := cw.Write(toCSVRecord(i, r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,10 @@
+package analytics
+
+import "github.com/gin-gonic/gin"
+
+// RegisterAnalyticsRoutes gắn endpoint phân tích; group truyền vào phải đã có middleware kiểm role admin.
+func RegisterAnalyticsRoutes(r *gin.RouterGroup, h *Controller) {
+	group := r.Group("/analytics")
+	group.GET("/training-export", h.TrainingExport)
+	group.GET("/calibration", h.Calibration)
+}
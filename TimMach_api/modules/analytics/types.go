@@ -0,0 +1,109 @@
+package analytics
+
+import "time"
+
+const (
+	// Tuổi được gom nhóm 5 năm; từ 90 tuổi trở lên chung một nhóm.
+	ageBucketYears = 5
+	ageTopCode     = 90
+
+	defaultK = 5
+	minK     = 2
+
+	defaultBins     = 10
+	unassignedGroup = "unassigned"
+)
+
+// TrainingExportParams: from/to (YYYY-MM-DD, tính cả hai đầu) lọc theo ngày tạo prediction,
+// k là số bệnh nhân tối thiểu trong mỗi nhóm quasi-identifier (tuổi nhóm + giới tính),
+// horizon_days là cửa sổ theo dõi khi gắn nhãn cardio từ outcomes (mặc định 365).
+type TrainingExportParams struct {
+	From        string `form:"from"`
+	To          string `form:"to"`
+	K           *int   `form:"k" binding:"omitempty,min=2,max=100"`
+	HorizonDays *int   `form:"horizon_days" binding:"omitempty,min=30,max=3650"`
+}
+
+// ExportOptions là tuỳ chọn đã kiểm tra của một lần export.
+type ExportOptions struct {
+	From        *time.Time
+	To          *time.Time // exclusive
+	K           int
+	HorizonDays int
+	Salt        string
+}
+
+// TrainingRow là một dòng theo layout cardio_train.csv, thêm pseudonym bệnh nhân và nhóm tuổi ở cuối.
+type TrainingRow struct {
+	Patient     string
+	AgeGroup    string
+	AgeDays     int
+	Gender      int
+	Height      float64
+	Weight      float64
+	APHi        int
+	APLo        int
+	Cholesterol int
+	Gluc        int
+	Smoke       int
+	Alco        int
+	Active      int
+	// Cardio là nhãn từ outcomes (0/1, xem outcomes.Label); nil khi chưa đủ dữ liệu theo dõi, để trống trong CSV.
+	Cardio *int
+}
+
+// TrainingExport là kết quả sau khi đã ẩn danh và áp k-anonymity.
+type TrainingExport struct {
+	Rows       []TrainingRow
+	K          int
+	Total      int // số prediction đọc được
+	Labeled    int // số dòng có nhãn cardio
+	Skipped    int // raw_features không đọc được
+	Suppressed int // bị loại vì nhóm quasi-identifier có ít hơn K bệnh nhân
+}
+
+// trainingColumns giữ đúng thứ tự cột của cardio_train.csv (age tính bằng ngày), cột thêm nằm sau cardio.
+var trainingColumns = []string{
+	"id", "age", "gender", "height", "weight", "ap_hi", "ap_lo",
+	"cholesterol", "gluc", "smoke", "alco", "active", "cardio",
+	"patient", "age_group",
+}
+
+// CalibrationParams: from/to lọc theo ngày tạo prediction, horizon_days là cửa sổ theo dõi khi gắn nhãn,
+// bins là số khoảng xác suất của biểu đồ reliability.
+type CalibrationParams struct {
+	From        string `form:"from"`
+	To          string `form:"to"`
+	HorizonDays *int   `form:"horizon_days" binding:"omitempty,min=30,max=3650"`
+	Bins        *int   `form:"bins" binding:"omitempty,min=2,max=20"`
+}
+
+type CalibrationResponse struct {
+	HorizonDays    int            `json:"horizon_days"`
+	Overall        ModelMetrics   `json:"overall"`
+	ByModelVersion []ModelMetrics `json:"by_model_version"`
+	ByClinic       []ModelMetrics `json:"by_clinic"`
+}
+
+// ModelMetrics là độ phân biệt (AUC) và calibration (Brier, reliability) của một nhóm prediction.
+// AUC/Brier là null khi nhóm chưa đủ nhãn (AUC cần cả ca dương và âm).
+type ModelMetrics struct {
+	Group         string           `json:"group,omitempty"`
+	Predictions   int              `json:"predictions"`
+	Labeled       int              `json:"labeled"`
+	Positives     int              `json:"positives"`
+	Prevalence    *float64         `json:"prevalence"`
+	MeanPredicted *float64         `json:"mean_predicted"`
+	AUC           *float64         `json:"auc"`
+	Brier         *float64         `json:"brier"`
+	Reliability   []ReliabilityBin `json:"reliability"`
+}
+
+// ReliabilityBin: trong khoảng xác suất [lower, upper), so xác suất dự đoán trung bình với tỉ lệ mắc thực tế.
+type ReliabilityBin struct {
+	Lower         float64  `json:"lower"`
+	Upper         float64  `json:"upper"`
+	Count         int      `json:"count"`
+	MeanPredicted *float64 `json:"mean_predicted"`
+	ObservedRate  *float64 `json:"observed_rate"`
+}
@@ -0,0 +1,403 @@
+package cohorts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/emails"
+	"chidinh/modules/reports"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/sync/errgroup"
+)
+
+// Controller gom dependencies cho module cohort.
+type Controller struct {
+	Queries   *db.Queries
+	Brandings *emails.Brandings
+}
+
+func NewController(q *db.Queries, brandings *emails.Brandings) *Controller {
+	return &Controller{Queries: q, Brandings: brandings}
+}
+
+// POST /cohorts
+func (h *Controller) CreateCohort(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	req, filter, ok := bindCohortRequest(c)
+	if !ok {
+		return
+	}
+
+	cohort, err := h.Queries.CreateCohort(c, db.CreateCohortParams{
+		UserID:      userID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Filter:      filter,
+	})
+	if err != nil {
+		respondWriteError(c, err, "cannot create cohort")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toCohortResponse(cohort))
+}
+
+// GET /cohorts
+func (h *Controller) ListCohorts(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ListCohortsParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListCohortsByUserDescParams{
+		UserID:          userID,
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.Cohort
+	if pager.Desc {
+		items, err = h.Queries.ListCohortsByUserDesc(c, params)
+	} else {
+		items, err = h.Queries.ListCohortsByUserAsc(c, db.ListCohortsByUserAscParams(params))
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list cohorts")
+		return
+	}
+
+	items, meta := pagination.Trim(items, pager, cohortCursor)
+	resp := ListCohortsResponse{Cohorts: make([]CohortResponse, 0, len(items)), Meta: meta}
+	for _, item := range items {
+		resp.Cohorts = append(resp.Cohorts, toCohortResponse(item))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /cohorts/:id
+func (h *Controller) GetCohort(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	cohort, ok := h.ownedCohort(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toCohortResponse(cohort))
+}
+
+// PUT /cohorts/:id
+func (h *Controller) UpdateCohort(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	cohort, ok := h.ownedCohort(c, userID)
+	if !ok {
+		return
+	}
+
+	req, filter, ok := bindCohortRequest(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.Queries.UpdateCohort(c, db.UpdateCohortParams{
+		ID:          cohort.ID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		Filter:      filter,
+	})
+	if err != nil {
+		respondWriteError(c, err, "cannot update cohort")
+		return
+	}
+
+	c.JSON(http.StatusOK, toCohortResponse(updated))
+}
+
+// DELETE /cohorts/:id
+func (h *Controller) DeleteCohort(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	cohort, ok := h.ownedCohort(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.Queries.DeleteCohort(c, cohort.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot delete cohort")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /cohorts/compare?ids=1,2&from=&to=
+// So sánh 2-4 cohort: phân bố risk, xác suất trung bình, thay đổi xác suất và mức tuân thủ tập luyện.
+func (h *Controller) Compare(c *gin.Context) {
+	resp, ok := h.compare(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /cohorts/compare.pdf?ids=1,2&from=&to=
+// Cùng số liệu với /cohorts/compare, render qua pipeline PDF (chromedp) của module reports.
+func (h *Controller) ComparePDF(c *gin.Context) {
+	resp, ok := h.compare(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := utils.UserIDFromContext(c)
+	branding, err := h.Brandings.ForUser(c, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot load clinic branding")
+		return
+	}
+
+	pdfBytes, err := reports.RenderPDF(c, "cohort_comparison.html", buildComparisonView(resp, branding.Name, time.Now()))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("cohort_comparison_%s.pdf", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
+// compare tính CompareResponse cho request hiện tại; đã trả lỗi nếu ok = false.
+func (h *Controller) compare(c *gin.Context) (CompareResponse, bool) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return CompareResponse{}, false
+	}
+
+	var req CompareParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return CompareResponse{}, false
+	}
+	ids, err := parseCohortIDs(req.IDs)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return CompareResponse{}, false
+	}
+	now := time.Now()
+	from, to, err := parseWindow(req, now)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return CompareResponse{}, false
+	}
+
+	cohorts := make([]db.Cohort, 0, len(ids))
+	for _, id := range ids {
+		cohort, err := h.Queries.GetCohortByID(c, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			utils.RespondError(c, http.StatusNotFound, "cohort not found")
+			return CompareResponse{}, false
+		}
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "cannot fetch cohort")
+			return CompareResponse{}, false
+		}
+		if cohort.UserID != userID {
+			utils.RespondError(c, http.StatusForbidden, "cohort does not belong to user")
+			return CompareResponse{}, false
+		}
+		cohorts = append(cohorts, cohort)
+	}
+
+	candidates, err := h.Queries.ListCohortCandidates(c, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot load patients")
+		return CompareResponse{}, false
+	}
+
+	members := make([][]db.ListCohortCandidatesRow, len(cohorts))
+	seen := make(map[int64]struct{})
+	var patientIDs []int64
+	for i, cohort := range cohorts {
+		filter := toCohortResponse(cohort).Filter
+		for _, r := range candidates {
+			if !matches(filter, r, now) {
+				continue
+			}
+			members[i] = append(members[i], r)
+			if _, ok := seen[r.ID]; !ok {
+				seen[r.ID] = struct{}{}
+				patientIDs = append(patientIDs, r.ID)
+			}
+		}
+	}
+
+	data, err := h.loadWindow(c, patientIDs, from, to)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot compute cohort metrics")
+		return CompareResponse{}, false
+	}
+
+	resp := CompareResponse{From: from, To: to, Cohorts: make([]CohortMetrics, 0, len(cohorts))}
+	for i, cohort := range cohorts {
+		resp.Cohorts = append(resp.Cohorts, buildMetrics(cohort, members[i], data))
+	}
+	return resp, true
+}
+
+// loadWindow tải predictions, kế hoạch tập và số buổi tập trong khoảng của các bệnh nhân song song.
+func (h *Controller) loadWindow(ctx context.Context, patientIDs []int64, from, to time.Time) (windowData, error) {
+	var (
+		points    []db.ListPredictionPointsByPatientsRow
+		plans     []db.ListLatestRecommendationPlansRow
+		sessions  []db.CountExerciseSessionsByPatientsRow
+		templates []db.ExerciseTemplate
+	)
+	if len(patientIDs) == 0 {
+		return newWindowData(from, to, points, plans, sessions, templates), nil
+	}
+
+	fromTime := pgtype.Timestamptz{Time: from, Valid: true}
+	toTime := pgtype.Timestamptz{Time: to, Valid: true}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		var err error
+		points, err = h.Queries.ListPredictionPointsByPatients(ctx, db.ListPredictionPointsByPatientsParams{
+			PatientIds: patientIDs, FromTime: fromTime, ToTime: toTime,
+		})
+		return err
+	})
+
+	g.Go(func() error {
+		var err error
+		plans, err = h.Queries.ListLatestRecommendationPlans(ctx, db.ListLatestRecommendationPlansParams{
+			PatientIds: patientIDs, ToTime: toTime,
+		})
+		return err
+	})
+
+	g.Go(func() error {
+		var err error
+		sessions, err = h.Queries.CountExerciseSessionsByPatients(ctx, db.CountExerciseSessionsByPatientsParams{
+			PatientIds: patientIDs,
+			FromDate:   pgtype.Date{Time: from, Valid: true},
+			ToDate:     pgtype.Date{Time: to, Valid: true},
+		})
+		return err
+	})
+
+	g.Go(func() error {
+		var err error
+		templates, err = h.Queries.ListExerciseTemplates(ctx)
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return windowData{}, err
+	}
+	return newWindowData(from, to, points, plans, sessions, templates), nil
+}
+
+// bindCohortRequest đọc body và chuẩn hoá filter; đã trả lỗi nếu ok = false.
+func bindCohortRequest(c *gin.Context) (CohortRequest, []byte, bool) {
+	var req CohortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return req, nil, false
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		utils.RespondError(c, http.StatusBadRequest, "name is required")
+		return req, nil, false
+	}
+
+	filter, err := normalizeFilter(req.Filter)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return req, nil, false
+	}
+	blob, err := json.Marshal(filter)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot encode filter")
+		return req, nil, false
+	}
+	return req, blob, true
+}
+
+func respondWriteError(c *gin.Context, err error, msg string) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		utils.RespondError(c, http.StatusConflict, "cohort name already exists")
+		return
+	}
+	utils.RespondError(c, http.StatusInternalServerError, msg)
+}
+
+// ownedCohort đọc :id và kiểm tra cohort thuộc user; đã trả lỗi nếu ok = false.
+func (h *Controller) ownedCohort(c *gin.Context, userID string) (db.Cohort, bool) {
+	cohortID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid cohort id")
+		return db.Cohort{}, false
+	}
+
+	cohort, err := h.Queries.GetCohortByID(c, cohortID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "cohort not found")
+		return cohort, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch cohort")
+		return cohort, false
+	}
+	if cohort.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "cohort does not belong to user")
+		return cohort, false
+	}
+	return cohort, true
+}
@@ -0,0 +1,217 @@
+package cohorts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/stats"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+)
+
+const dateLayout = "2006-01-02"
+
+var riskLabels = []string{"low", "medium", "high", "none"}
+
+func toCohortResponse(c db.Cohort) CohortResponse {
+	var f Filter
+	if len(c.Filter) > 0 {
+		_ = json.Unmarshal(c.Filter, &f)
+	}
+	return CohortResponse{
+		ID:          strconv.FormatInt(c.ID, 10),
+		Name:        c.Name,
+		Description: c.Description,
+		Filter:      f,
+		CreatedAt:   c.CreatedAt.Time,
+		UpdatedAt:   c.UpdatedAt.Time,
+	}
+}
+
+func cohortCursor(c db.Cohort) pagination.Cursor {
+	return pagination.Cursor{Time: c.CreatedAt.Time, ID: c.ID}
+}
+
+// normalizeFilter chuẩn hoá (lowercase, loại trùng) và kiểm tra filter trước khi lưu.
+func normalizeFilter(f Filter) (Filter, error) {
+	if f.Gender != nil {
+		switch *f.Gender {
+		case utils.GenderOther, utils.GenderMale, utils.GenderFemale:
+		default:
+			return f, errors.New("filter.gender must be 0, 1 or 2")
+		}
+	}
+	if (f.MinAge != nil && *f.MinAge < 0) || (f.MaxAge != nil && *f.MaxAge < 0) {
+		return f, errors.New("filter age must not be negative")
+	}
+	if f.MinAge != nil && f.MaxAge != nil && *f.MinAge > *f.MaxAge {
+		return f, errors.New("filter.min_age must not exceed filter.max_age")
+	}
+	for _, p := range []*float64{f.MinProbability, f.MaxProbability} {
+		if p != nil && (*p < 0 || *p > 1) {
+			return f, errors.New("filter probability must be between 0 and 1")
+		}
+	}
+	if f.MinProbability != nil && f.MaxProbability != nil && *f.MinProbability > *f.MaxProbability {
+		return f, errors.New("filter.min_probability must not exceed filter.max_probability")
+	}
+
+	var err error
+	if f.RiskLabels, err = normalizeSet(f.RiskLabels, riskLabels, "filter.risk_labels"); err != nil {
+		return f, err
+	}
+	if f.Factors, err = normalizeSet(f.Factors, stats.KnownFactors, "filter.factors"); err != nil {
+		return f, err
+	}
+
+	from, to, err := createdRange(f)
+	if err != nil {
+		return f, err
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return f, errors.New("filter.created_from must not be after filter.created_to")
+	}
+	return f, nil
+}
+
+func normalizeSet(values, allowed []string, field string) ([]string, error) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || slices.Contains(out, v) {
+			continue
+		}
+		if !slices.Contains(allowed, v) {
+			return nil, fmt.Errorf("%s must be one of %s", field, strings.Join(allowed, ", "))
+		}
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// createdRange trả về [from, to) theo ngày tạo hồ sơ; time zero nếu không giới hạn.
+func createdRange(f Filter) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if f.CreatedFrom != "" {
+		if from, err = time.Parse(dateLayout, f.CreatedFrom); err != nil {
+			return from, to, errors.New("filter.created_from must be YYYY-MM-DD")
+		}
+	}
+	if f.CreatedTo != "" {
+		if to, err = time.Parse(dateLayout, f.CreatedTo); err != nil {
+			return from, to, errors.New("filter.created_to must be YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// matches áp filter lên một bệnh nhân; tuổi tính theo năm hiện tại - birth_year.
+func matches(f Filter, r db.ListCohortCandidatesRow, now time.Time) bool {
+	if f.Gender != nil && r.Gender != *f.Gender {
+		return false
+	}
+	if f.MinAge != nil || f.MaxAge != nil {
+		if r.BirthYear == nil {
+			return false
+		}
+		age := now.Year() - int(*r.BirthYear)
+		if (f.MinAge != nil && age < *f.MinAge) || (f.MaxAge != nil && age > *f.MaxAge) {
+			return false
+		}
+	}
+	if len(f.RiskLabels) > 0 && !slices.Contains(f.RiskLabels, latestRisk(r)) {
+		return false
+	}
+	if f.MinProbability != nil || f.MaxProbability != nil {
+		if r.LatestProbability == nil {
+			return false
+		}
+		p := *r.LatestProbability
+		if (f.MinProbability != nil && p < *f.MinProbability) || (f.MaxProbability != nil && p > *f.MaxProbability) {
+			return false
+		}
+	}
+	for _, factor := range f.Factors {
+		if !slices.Contains(r.LatestFactorFields, factor) {
+			return false
+		}
+	}
+	if f.ExerciseProgram != nil && (r.ExerciseSessions > 0) != *f.ExerciseProgram {
+		return false
+	}
+	if f.CreatedFrom != "" || f.CreatedTo != "" {
+		from, to, _ := createdRange(f)
+		created := r.CreatedAt.Time
+		if (!from.IsZero() && created.Before(from)) || (!to.IsZero() && !created.Before(to)) {
+			return false
+		}
+	}
+	return true
+}
+
+func latestRisk(r db.ListCohortCandidatesRow) string {
+	if r.LatestRiskLabel == nil {
+		return "none"
+	}
+	return strings.ToLower(*r.LatestRiskLabel)
+}
+
+// parseCohortIDs đọc danh sách id "1,2,3"; yêu cầu 2-4 id khác nhau.
+func parseCohortIDs(input string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.New("ids must be comma separated cohort ids")
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < minCompareCohorts || len(ids) > maxCompareCohorts {
+		return nil, fmt.Errorf("ids must contain %d-%d distinct cohorts", minCompareCohorts, maxCompareCohorts)
+	}
+	return ids, nil
+}
+
+// parseWindow trả về [from, to) (UTC, theo ngày); to mặc định là hết hôm nay, from mặc định to - 90 ngày.
+func parseWindow(req CompareParams, now time.Time) (time.Time, time.Time, error) {
+	to := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if req.To != "" {
+		day, err := time.Parse(dateLayout, req.To)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultCompareDays)
+	if req.From != "" {
+		day, err := time.Parse(dateLayout, req.From)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+		}
+		from = day
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	if to.Sub(from) > maxCompareDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("window must not exceed %d days", maxCompareDays)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,221 @@
+package cohorts
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/predictions"
+)
+
+// windowData là dữ liệu trong khoảng [From, To) của mọi bệnh nhân thuộc các cohort đang so sánh,
+// tải một lần rồi chia theo cohort.
+type windowData struct {
+	From     time.Time
+	To       time.Time
+	Points   map[int64][]db.ListPredictionPointsByPatientsRow
+	Plans    map[int64]db.ListLatestRecommendationPlansRow
+	Sessions map[int64]int64
+	// TemplateFreq: freq_per_week theo template id, để tính số buổi của plan chỉ lưu template_ids.
+	TemplateFreq map[int64]int32
+}
+
+func newWindowData(
+	from, to time.Time,
+	points []db.ListPredictionPointsByPatientsRow,
+	plans []db.ListLatestRecommendationPlansRow,
+	sessions []db.CountExerciseSessionsByPatientsRow,
+	templates []db.ExerciseTemplate,
+) windowData {
+	d := windowData{
+		From:         from,
+		To:           to,
+		Points:       make(map[int64][]db.ListPredictionPointsByPatientsRow),
+		Plans:        make(map[int64]db.ListLatestRecommendationPlansRow, len(plans)),
+		Sessions:     make(map[int64]int64, len(sessions)),
+		TemplateFreq: make(map[int64]int32, len(templates)),
+	}
+	for _, p := range points {
+		d.Points[p.PatientID] = append(d.Points[p.PatientID], p)
+	}
+	for _, p := range plans {
+		d.Plans[p.PatientID] = p
+	}
+	for _, s := range sessions {
+		d.Sessions[s.PatientID] = s.Sessions
+	}
+	for _, t := range templates {
+		d.TemplateFreq[t.ID] = t.FreqPerWeek
+	}
+	return d
+}
+
+func buildMetrics(c db.Cohort, members []db.ListCohortCandidatesRow, d windowData) CohortMetrics {
+	m := CohortMetrics{
+		Cohort:     toCohortResponse(c),
+		Patients:   len(members),
+		RiskCounts: riskShares(members),
+	}
+
+	var latest mean
+	for _, r := range members {
+		if r.LatestProbability != nil {
+			latest.add(*r.LatestProbability)
+		}
+	}
+	m.MeanProbability = latest.value()
+	m.ProbabilityChange = probabilityChange(members, d)
+	m.Trend = monthlyTrend(members, d)
+	m.Adherence = adherence(members, d)
+	return m
+}
+
+// riskShares đếm theo risk của prediction mới nhất; "none" là bệnh nhân chưa có prediction.
+func riskShares(members []db.ListCohortCandidatesRow) []RiskShare {
+	counts := make(map[string]int, len(riskLabels))
+	for _, r := range members {
+		counts[latestRisk(r)]++
+	}
+	out := make([]RiskShare, 0, len(riskLabels))
+	for _, label := range []string{"high", "medium", "low", "none"} {
+		out = append(out, RiskShare{
+			RiskLabel: label,
+			Count:     counts[label],
+			Share:     share(counts[label], len(members)),
+		})
+	}
+	return out
+}
+
+func probabilityChange(members []db.ListCohortCandidatesRow, d windowData) ProbabilityChange {
+	var out ProbabilityChange
+	var change mean
+	for _, r := range members {
+		points := d.Points[r.ID]
+		if len(points) < 2 {
+			continue
+		}
+		delta := points[len(points)-1].Probability - points[0].Probability
+		out.Patients++
+		change.add(delta)
+		switch {
+		case delta < 0:
+			out.Improved++
+		case delta > 0:
+			out.Worsened++
+		}
+	}
+	out.MeanChange = change.value()
+	return out
+}
+
+// monthlyTrend: xác suất trung bình của các prediction trong từng tháng (UTC) của khoảng so sánh.
+func monthlyTrend(members []db.ListCohortCandidatesRow, d windowData) []TrendPoint {
+	byMonth := make(map[string]*mean)
+	for _, r := range members {
+		for _, p := range d.Points[r.ID] {
+			key := p.CreatedAt.Time.UTC().Format("2006-01")
+			if byMonth[key] == nil {
+				byMonth[key] = &mean{}
+			}
+			byMonth[key].add(p.Probability)
+		}
+	}
+
+	var out []TrendPoint
+	start := time.Date(d.From.Year(), d.From.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for month := start; month.Before(d.To); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		point := TrendPoint{Month: key}
+		if agg := byMonth[key]; agg != nil {
+			point.Predictions = agg.n
+			point.MeanProbability = agg.value()
+		}
+		out = append(out, point)
+	}
+	return out
+}
+
+// adherence so sánh số buổi tập ghi nhận trong khoảng với số buổi kế hoạch gần nhất giao
+// (freq_per_week × số tuần kể từ max(from, ngày tạo kế hoạch)).
+func adherence(members []db.ListCohortCandidatesRow, d windowData) Adherence {
+	var out Adherence
+	var ratio mean
+	for _, r := range members {
+		sessions := d.Sessions[r.ID]
+		out.Sessions += sessions
+		if sessions > 0 {
+			out.PatientsLogging++
+		}
+
+		plan, ok := d.Plans[r.ID]
+		if !ok {
+			continue
+		}
+		start := d.From
+		if plan.CreatedAt.Valid && plan.CreatedAt.Time.After(start) {
+			start = plan.CreatedAt.Time
+		}
+		expected := d.weeklySessions(plan.Plan) * d.To.Sub(start).Hours() / (24 * 7)
+		if expected <= 0 {
+			continue
+		}
+		out.PatientsWithPlan++
+		value := math.Min(float64(sessions)/expected, 1)
+		ratio.add(value)
+		if value >= adherentThreshold {
+			out.Adherent++
+		}
+	}
+	out.MeanAdherence = ratio.value()
+	return out
+}
+
+// weeklySessions: tổng freq_per_week của plan; plan lưu trong DB thường chỉ có template_ids nên tra theo template.
+func (d windowData) weeklySessions(raw []byte) float64 {
+	var plan predictions.RecommendationPlan
+	if len(raw) == 0 || json.Unmarshal(raw, &plan) != nil {
+		return 0
+	}
+	total := 0
+	if len(plan.Items) > 0 {
+		for _, item := range plan.Items {
+			total += item.FreqPerWeek
+		}
+		return float64(total)
+	}
+	for _, id := range plan.TemplateIDs {
+		total += int(d.TemplateFreq[id])
+	}
+	return float64(total)
+}
+
+type mean struct {
+	sum float64
+	n   int
+}
+
+func (m *mean) add(v float64) {
+	m.sum += v
+	m.n++
+}
+
+func (m mean) value() *float64 {
+	if m.n == 0 {
+		return nil
+	}
+	v := round4(m.sum / float64(m.n))
+	return &v
+}
+
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round4(float64(n) / float64(total))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
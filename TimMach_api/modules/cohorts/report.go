@@ -0,0 +1,94 @@
+package cohorts
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// comparisonView là view model của templates/cohort_comparison.html: mỗi hàng là một chỉ số,
+// mỗi cột (Values) là một cohort theo thứ tự ids.
+type comparisonView struct {
+	ClinicName  string
+	GeneratedAt string
+	Period      string
+	Cohorts     []cohortHeaderView
+	Summary     []comparisonRowView
+	Trend       []comparisonRowView
+}
+
+type cohortHeaderView struct {
+	Name        string
+	Description string
+}
+
+type comparisonRowView struct {
+	Label  string
+	Values []string
+}
+
+func buildComparisonView(resp CompareResponse, clinicName string, now time.Time) comparisonView {
+	vm := comparisonView{
+		ClinicName:  clinicName,
+		GeneratedAt: now.Format("2006-01-02 15:04"),
+		Period:      fmt.Sprintf("%s → %s", resp.From.Format(dateLayout), resp.To.AddDate(0, 0, -1).Format(dateLayout)),
+	}
+
+	row := func(label string, value func(CohortMetrics) string) {
+		r := comparisonRowView{Label: label}
+		for _, m := range resp.Cohorts {
+			r.Values = append(r.Values, value(m))
+		}
+		vm.Summary = append(vm.Summary, r)
+	}
+
+	for _, m := range resp.Cohorts {
+		vm.Cohorts = append(vm.Cohorts, cohortHeaderView{Name: m.Cohort.Name, Description: m.Cohort.Description})
+	}
+	row("Số bệnh nhân", func(m CohortMetrics) string { return strconv.Itoa(m.Patients) })
+	for i, label := range []string{"Nguy cơ cao", "Nguy cơ trung bình", "Nguy cơ thấp", "Chưa dự đoán"} {
+		row(label, func(m CohortMetrics) string {
+			rc := m.RiskCounts[i]
+			return fmt.Sprintf("%d (%s)", rc.Count, formatPct(rc.Share))
+		})
+	}
+	row("Xác suất trung bình (mới nhất)", func(m CohortMetrics) string { return formatOptionalPct(m.MeanProbability) })
+	row("Bệnh nhân có >= 2 dự đoán", func(m CohortMetrics) string { return strconv.Itoa(m.ProbabilityChange.Patients) })
+	row("Thay đổi xác suất trung bình", func(m CohortMetrics) string {
+		if m.ProbabilityChange.MeanChange == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%+.1f điểm %%", *m.ProbabilityChange.MeanChange*100)
+	})
+	row("Cải thiện / xấu đi", func(m CohortMetrics) string {
+		return fmt.Sprintf("%d / %d", m.ProbabilityChange.Improved, m.ProbabilityChange.Worsened)
+	})
+	row("Có kế hoạch tập", func(m CohortMetrics) string { return strconv.Itoa(m.Adherence.PatientsWithPlan) })
+	row("Có ghi nhận buổi tập", func(m CohortMetrics) string { return strconv.Itoa(m.Adherence.PatientsLogging) })
+	row("Tổng số buổi tập", func(m CohortMetrics) string { return strconv.FormatInt(m.Adherence.Sessions, 10) })
+	row("Mức tuân thủ trung bình", func(m CohortMetrics) string { return formatOptionalPct(m.Adherence.MeanAdherence) })
+	row("Tuân thủ >= 80%", func(m CohortMetrics) string { return strconv.Itoa(m.Adherence.Adherent) })
+
+	if len(resp.Cohorts) > 0 {
+		for i, point := range resp.Cohorts[0].Trend {
+			r := comparisonRowView{Label: point.Month}
+			for _, m := range resp.Cohorts {
+				p := m.Trend[i]
+				r.Values = append(r.Values, fmt.Sprintf("%s (n=%d)", formatOptionalPct(p.MeanProbability), p.Predictions))
+			}
+			vm.Trend = append(vm.Trend, r)
+		}
+	}
+	return vm
+}
+
+func formatPct(v float64) string {
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+func formatOptionalPct(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return formatPct(*v)
+}
@@ -0,0 +1,15 @@
+package cohorts
+
+import "github.com/gin-gonic/gin"
+
+// RegisterCohortRoutes gắn endpoint cohort đã lưu và báo cáo so sánh cohort (cần auth).
+func RegisterCohortRoutes(r *gin.RouterGroup, h *Controller) {
+	group := r.Group("/cohorts")
+	group.GET("", h.ListCohorts)
+	group.POST("", h.CreateCohort)
+	group.GET("/compare", h.Compare)
+	group.GET("/compare.pdf", h.ComparePDF)
+	group.GET("/:id", h.GetCohort)
+	group.PUT("/:id", h.UpdateCohort)
+	group.DELETE("/:id", h.DeleteCohort)
+}
@@ -0,0 +1,110 @@
+package cohorts
+
+import (
+	"time"
+
+	"chidinh/utils/pagination"
+)
+
+const (
+	minCompareCohorts  = 2
+	maxCompareCohorts  = 4
+	defaultCompareDays = 90
+	maxCompareDays     = 730
+	// adherentThreshold: bệnh nhân đạt >= 80% số buổi được giao được tính là tuân thủ.
+	adherentThreshold = 0.8
+)
+
+// Filter là bộ lọc đã lưu của cohort, lưu nguyên dạng JSON trong cohorts.filter.
+// Các điều kiện kết hợp bằng AND; trường bỏ trống nghĩa là không lọc.
+// Chỉ lọc trên dữ liệu không mã hoá (birth_year, gender, prediction mới nhất, factors).
+type Filter struct {
+	Gender          *int16   `json:"gender,omitempty"`
+	MinAge          *int     `json:"min_age,omitempty"`
+	MaxAge          *int     `json:"max_age,omitempty"`
+	RiskLabels      []string `json:"risk_labels,omitempty"` // low/medium/high/none (chưa có prediction)
+	MinProbability  *float64 `json:"min_probability,omitempty"`
+	MaxProbability  *float64 `json:"max_probability,omitempty"`
+	Factors         []string `json:"factors,omitempty"`          // prediction mới nhất phải có đủ các factor
+	ExerciseProgram *bool    `json:"exercise_program,omitempty"` // đã/chưa có buổi tập được ghi nhận
+	CreatedFrom     string   `json:"created_from,omitempty"`     // yyyy-mm-dd, ngày tạo hồ sơ bệnh nhân
+	CreatedTo       string   `json:"created_to,omitempty"`       // yyyy-mm-dd, tính cả ngày này
+}
+
+type CohortRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Filter      Filter `json:"filter"`
+}
+
+type CohortResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Filter      Filter    `json:"filter"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListCohortsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
+type ListCohortsParams struct {
+	pagination.Params
+}
+
+type ListCohortsResponse struct {
+	Cohorts []CohortResponse `json:"cohorts"`
+	pagination.Meta
+}
+
+// CompareParams: ids là 2-4 cohort id phân tách bằng dấu phẩy; khoảng [from, to) theo ngày (yyyy-mm-dd),
+// mặc định 90 ngày gần nhất.
+type CompareParams struct {
+	IDs  string `form:"ids" binding:"required"`
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+type RiskShare struct {
+	RiskLabel string  `json:"risk_label"`
+	Count     int     `json:"count"`
+	Share     float64 `json:"share"`
+}
+
+// ProbabilityChange so sánh prediction đầu và cuối trong khoảng của từng bệnh nhân có >= 2 prediction.
+type ProbabilityChange struct {
+	Patients   int      `json:"patients"`
+	MeanChange *float64 `json:"mean_change"`
+	Improved   int      `json:"improved"`
+	Worsened   int      `json:"worsened"`
+}
+
+type TrendPoint struct {
+	Month           string   `json:"month"` // yyyy-mm (UTC)
+	Predictions     int      `json:"predictions"`
+	MeanProbability *float64 `json:"mean_probability"`
+}
+
+// Adherence = số buổi tập ghi nhận / số buổi được giao theo kế hoạch gần nhất (tối đa 1).
+type Adherence struct {
+	PatientsWithPlan int      `json:"patients_with_plan"`
+	PatientsLogging  int      `json:"patients_logging"`
+	Sessions         int64    `json:"sessions"`
+	MeanAdherence    *float64 `json:"mean_adherence"`
+	Adherent         int      `json:"adherent"`
+}
+
+type CohortMetrics struct {
+	Cohort            CohortResponse    `json:"cohort"`
+	Patients          int               `json:"patients"`
+	RiskCounts        []RiskShare       `json:"risk_counts"`
+	MeanProbability   *float64          `json:"mean_probability"`
+	ProbabilityChange ProbabilityChange `json:"probability_change"`
+	Trend             []TrendPoint      `json:"trend"`
+	Adherence         Adherence         `json:"adherence"`
+}
+
+type CompareResponse struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Cohorts []CohortMetrics `json:"cohorts"`
+}
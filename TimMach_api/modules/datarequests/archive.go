@@ -0,0 +1,156 @@
+package datarequests
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	db "chidinh/db/sqlc"
+)
+
+const exportStorageDir = "tmp/exports"
+
+// buildExportArchive gom toàn bộ dữ liệu của bệnh nhân vào một file zip:
+// profile.json, predictions.json (kèm raw_features, rescores, shadows), outcomes.json, recommendations.json,
+// exercise_sessions.json, reports.json (kèm lịch sử gửi email) và các file PDF báo cáo trong reports/.
+// Trả về đường dẫn file zip trên disk.
+func (h *Controller) buildExportArchive(ctx context.Context, requestID int64, patient db.Patient) (string, error) {
+	counts, err := h.Queries.CountPatientData(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	preds, err := h.Queries.ExportPredictionsByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	rescores, err := h.Queries.ExportPredictionRescoresByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	shadows, err := h.Queries.ExportPredictionShadowsByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	outcomeRows, err := h.Queries.ExportOutcomesByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	recs, err := h.Queries.ExportRecommendationsByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	sessions, err := h.Queries.ExportExerciseSessionsByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+	reportRows, err := h.Queries.ExportReportsByPatient(ctx, patient.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(exportStorageDir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(exportStorageDir, fmt.Sprintf("dsr-%d.zip", requestID))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	manifest := exportManifest{
+		RequestID:    requestID,
+		PatientID:    patient.ID,
+		GeneratedAt:  time.Now().UTC(),
+		Counts:       toDataCounts(counts),
+		Files:        []string{},
+		MissingFiles: []string{},
+	}
+	zw := zip.NewWriter(f)
+	err = func() error {
+		reports := make([]exportReport, 0, len(reportRows))
+		for _, r := range reportRows {
+			item := exportReport{
+				ID:         r.ID,
+				Filename:   r.Filename,
+				CreatedAt:  r.CreatedAt.Time,
+				Recipients: rawJSON(r.Recipients),
+			}
+			name := fmt.Sprintf("reports/%d-%s", r.ID, filepath.Base(r.Filename))
+			switch err := addFile(zw, name, r.FileUrl); {
+			case errors.Is(err, os.ErrNotExist):
+				manifest.MissingFiles = append(manifest.MissingFiles, name)
+			case err != nil:
+				return err
+			default:
+				item.File = name
+				manifest.Files = append(manifest.Files, name)
+			}
+			reports = append(reports, item)
+		}
+
+		profile, err := toExportProfile(patient)
+		if err != nil {
+			return err
+		}
+		docs := []struct {
+			name string
+			v    any
+		}{
+			{"profile.json", profile},
+			{"predictions.json", toExportPredictions(preds, rescores, shadows)},
+			{"outcomes.json", toExportOutcomes(outcomeRows)},
+			{"recommendations.json", toExportRecommendations(recs)},
+			{"exercise_sessions.json", toExportExerciseSessions(sessions)},
+			{"reports.json", reports},
+		}
+		for _, d := range docs {
+			if err := addJSON(zw, d.name, d.v); err != nil {
+				return err
+			}
+			manifest.Files = append(manifest.Files, d.name)
+		}
+		if err := addJSON(zw, "manifest.json", manifest); err != nil {
+			return err
+		}
+		return zw.Close()
+	}()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func addJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// addFile chép file trên disk vào archive; trả về os.ErrNotExist nếu file đã mất.
+func addFile(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
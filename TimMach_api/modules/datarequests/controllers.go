@@ -0,0 +1,265 @@
+package datarequests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Controller xử lý yêu cầu của chủ thể dữ liệu (export / erasure) cho bệnh nhân.
+type Controller struct {
+	Queries *db.Queries
+}
+
+func NewController(queries *db.Queries) *Controller {
+	return &Controller{Queries: queries}
+}
+
+// POST /patients/:id/data-requests
+// type=export: tạo archive zip toàn bộ dữ liệu bệnh nhân, tải qua download_url.
+// type=erasure: xoá hẳn dữ liệu + file, chỉ giữ tombstone ẩn danh. Áp dụng cả với bệnh nhân đã xoá mềm.
+func (h *Controller) CreateDataRequest(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid patient id")
+		return
+	}
+
+	var req CreateDataRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "type must be export or erasure")
+		return
+	}
+
+	patient, err := h.Queries.GetAnyPatientByID(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "patient not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	if req.Type == TypeErasure {
+		name, _, err := utils.OpenPatient(patient.Name, patient.Dob)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+			return
+		}
+		if !strings.EqualFold(strings.TrimSpace(req.Confirm), strings.TrimSpace(name)) {
+			utils.RespondError(c, http.StatusUnprocessableEntity, "confirm must match the patient name")
+			return
+		}
+	}
+
+	record, err := h.Queries.CreateDataRequest(c, db.CreateDataRequestParams{
+		UserID:      userID,
+		PatientID:   patient.ID,
+		RequestType: req.Type,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot create data request")
+		return
+	}
+
+	done := db.CompleteDataRequestParams{ID: record.ID, Status: StatusCompleted}
+	switch req.Type {
+	case TypeExport:
+		path, err := h.buildExportArchive(c, record.ID, patient)
+		if err != nil {
+			utils.L().Warnw("build data export failed", "error", err, "request_id", record.ID)
+			done.Status = StatusFailed
+			done.Error = "cannot build export archive"
+		}
+		done.ArchivePath = path
+	case TypeErasure:
+		tombstone, err := h.erasePatient(c, patient.ID)
+		if err != nil {
+			utils.L().Warnw("patient erasure failed", "error", err, "request_id", record.ID)
+			done.Status = StatusFailed
+			done.Error = "erasure did not complete, retry the request"
+			break
+		}
+		if !tombstone.Verified {
+			done.Status = StatusFailed
+			done.Error = "erasure verification found remaining data"
+		}
+		done.Tombstone, _ = json.Marshal(tombstone)
+	}
+
+	record, err = h.Queries.CompleteDataRequest(c, done)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot update data request")
+		return
+	}
+
+	status := http.StatusCreated
+	if record.Status == StatusFailed {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, toDataRequestResponse(record))
+}
+
+// GET /data-requests
+func (h *Controller) ListDataRequests(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ListDataRequestsParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListDataRequestsByUserDescParams{
+		UserID:          userID,
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.DataSubjectRequest
+	if pager.Desc {
+		items, err = h.Queries.ListDataRequestsByUserDesc(c, params)
+	} else {
+		items, err = h.Queries.ListDataRequestsByUserAsc(c, db.ListDataRequestsByUserAscParams(params))
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list data requests")
+		return
+	}
+
+	items, meta := pagination.Trim(items, pager, dataRequestCursor)
+	resp := ListDataRequestsResponse{Requests: make([]DataRequestResponse, 0, len(items)), Meta: meta}
+	for _, r := range items {
+		resp.Requests = append(resp.Requests, toDataRequestResponse(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /data-requests/:id
+func (h *Controller) GetDataRequest(c *gin.Context) {
+	record, ok := h.ownedRequest(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, toDataRequestResponse(record))
+}
+
+// GET /data-requests/:id/download
+func (h *Controller) DownloadDataExport(c *gin.Context) {
+	record, ok := h.ownedRequest(c)
+	if !ok {
+		return
+	}
+	if record.RequestType != TypeExport || record.Status != StatusCompleted || record.ArchivePath == "" {
+		utils.RespondError(c, http.StatusNotFound, "export archive not available")
+		return
+	}
+
+	content, err := os.ReadFile(record.ArchivePath)
+	if errors.Is(err, os.ErrNotExist) {
+		utils.RespondError(c, http.StatusNotFound, "export archive not available")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot read export archive")
+		return
+	}
+
+	filename := fmt.Sprintf("patient_%d_data_%s.zip", record.PatientID, record.CreatedAt.Time.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, "application/zip", content)
+}
+
+// GET /data-requests/:id/verify
+// Kiểm tra lại tại thời điểm gọi rằng không còn row/file nào của bệnh nhân đã xoá.
+func (h *Controller) VerifyErasure(c *gin.Context) {
+	record, ok := h.ownedRequest(c)
+	if !ok {
+		return
+	}
+	if record.RequestType != TypeErasure || !record.CompletedAt.Valid {
+		utils.RespondError(c, http.StatusBadRequest, "request is not a completed erasure")
+		return
+	}
+
+	archives, err := h.Queries.ListDataRequestArchivesByPatient(c, record.PatientID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot verify erasure")
+		return
+	}
+	remaining, filesRemaining, err := h.checkErased(c, record.PatientID, archives)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot verify erasure")
+		return
+	}
+
+	c.JSON(http.StatusOK, VerifyErasureResponse{
+		RequestID:      record.ID,
+		CheckedAt:      time.Now().UTC(),
+		Remaining:      remaining,
+		FilesRemaining: filesRemaining,
+		Verified:       remaining.empty() && filesRemaining == 0,
+	})
+}
+
+// ownedRequest parse :id, load data request và kiểm tra thuộc user; đã trả lỗi nếu ok = false.
+func (h *Controller) ownedRequest(c *gin.Context) (db.DataSubjectRequest, bool) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return db.DataSubjectRequest{}, false
+	}
+
+	requestID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid data request id")
+		return db.DataSubjectRequest{}, false
+	}
+
+	record, err := h.Queries.GetDataRequestByID(c, int64(requestID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "data request not found")
+		return record, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch data request")
+		return record, false
+	}
+	if record.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "data request does not belong to user")
+		return record, false
+	}
+	return record, true
+}
@@ -0,0 +1,177 @@
+package datarequests
+
+import (
+	"encoding/json"
+	"fmt"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+)
+
+func toDataRequestResponse(r db.DataSubjectRequest) DataRequestResponse {
+	resp := DataRequestResponse{
+		ID:        r.ID,
+		PatientID: r.PatientID,
+		Type:      r.RequestType,
+		Status:    r.Status,
+		Error:     r.Error,
+		CreatedAt: r.CreatedAt.Time,
+	}
+	if r.CompletedAt.Valid {
+		t := r.CompletedAt.Time
+		resp.CompletedAt = &t
+	}
+	if r.RequestType == TypeExport && r.ArchivePath != "" {
+		resp.DownloadURL = fmt.Sprintf("/data-requests/%d/download", r.ID)
+	}
+	if len(r.Tombstone) > 0 {
+		var t Tombstone
+		if err := json.Unmarshal(r.Tombstone, &t); err == nil {
+			resp.Tombstone = &t
+		}
+	}
+	return resp
+}
+
+func dataRequestCursor(r db.DataSubjectRequest) pagination.Cursor {
+	return pagination.Cursor{Time: r.CreatedAt.Time, ID: r.ID}
+}
+
+func toDataCounts(row db.CountPatientDataRow) DataCounts {
+	return DataCounts{
+		Patients:         row.Patients,
+		Predictions:      row.Predictions,
+		Rescores:         row.Rescores,
+		Shadows:          row.Shadows,
+		Recommendations:  row.Recommendations,
+		Reports:          row.Reports,
+		Outcomes:         row.Outcomes,
+		ExerciseSessions: row.ExerciseSessions,
+		Emails:           row.Emails,
+		Schedules:        row.Schedules,
+	}
+}
+
+// toExportProfile giải mã name/dob: archive export là bản dữ liệu đọc được của bệnh nhân.
+func toExportProfile(p db.Patient) (exportProfile, error) {
+	name, dob, err := utils.OpenPatient(p.Name, p.Dob)
+	if err != nil {
+		return exportProfile{}, err
+	}
+	out := exportProfile{
+		ID:        p.ID,
+		Name:      name,
+		Gender:    p.Gender,
+		GenderStr: utils.GenderLabel(p.Gender),
+		Dob:       dob.Format("2006-01-02"),
+		CreatedAt: p.CreatedAt.Time,
+	}
+	if p.DeletedAt.Valid {
+		t := p.DeletedAt.Time
+		out.DeletedAt = &t
+	}
+	return out, nil
+}
+
+func toExportPredictions(preds []db.Prediction, rescores []db.PredictionRescore, shadows []db.PredictionShadow) []exportPrediction {
+	rescoresByPred := make(map[int64][]exportRescore)
+	for _, rs := range rescores {
+		rescoresByPred[rs.PredictionID] = append(rescoresByPred[rs.PredictionID], exportRescore{
+			ModelName:    rs.ModelName,
+			ModelVersion: rs.ModelVersion,
+			Probability:  rs.Probability,
+			RiskLabel:    rs.RiskLabel,
+			CreatedAt:    rs.CreatedAt.Time,
+		})
+	}
+	shadowsByPred := make(map[int64][]exportShadow)
+	for _, s := range shadows {
+		shadowsByPred[s.PredictionID] = append(shadowsByPred[s.PredictionID], exportShadow{
+			ModelName:    s.ModelName,
+			ModelVersion: s.ModelVersion,
+			Probability:  s.Probability,
+			RiskLabel:    s.RiskLabel,
+			Error:        s.Error,
+			CreatedAt:    s.CreatedAt.Time,
+		})
+	}
+
+	out := make([]exportPrediction, 0, len(preds))
+	for _, p := range preds {
+		out = append(out, exportPrediction{
+			ID:                p.ID,
+			CreatedAt:         p.CreatedAt.Time,
+			Probability:       p.Probability,
+			RiskLabel:         p.RiskLabel,
+			ModelName:         p.ModelName,
+			ModelVersion:      p.ModelVersion,
+			FeatureSchemaHash: p.FeatureSchemaHash,
+			RawFeatures:       rawJSON(utils.OpenFeatures(p.RawFeatures)),
+			Factors:           rawJSON(p.Factors),
+			Explanation:       rawJSON(p.Explanation),
+			Warnings:          rawJSON(p.Warnings),
+			Rescores:          nonNil(rescoresByPred[p.ID]),
+			Shadows:           nonNil(shadowsByPred[p.ID]),
+		})
+	}
+	return out
+}
+
+func toExportRecommendations(recs []db.ExerciseRecommendation) []exportRecommendation {
+	out := make([]exportRecommendation, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, exportRecommendation{
+			ID:           r.ID,
+			PredictionID: r.PredictionID,
+			CreatedAt:    r.CreatedAt.Time,
+			Plan:         rawJSON(r.Plan),
+		})
+	}
+	return out
+}
+
+func toExportOutcomes(rows []db.PatientOutcome) []exportOutcome {
+	out := make([]exportOutcome, 0, len(rows))
+	for _, o := range rows {
+		out = append(out, exportOutcome{
+			ID:         o.ID,
+			EventType:  o.EventType,
+			Condition:  o.Condition,
+			OccurredOn: o.OccurredOn.Time.Format("2006-01-02"),
+			Source:     o.Source,
+			CreatedAt:  o.CreatedAt.Time,
+		})
+	}
+	return out
+}
+
+func toExportExerciseSessions(rows []db.ExerciseSession) []exportExerciseSession {
+	out := make([]exportExerciseSession, 0, len(rows))
+	for _, s := range rows {
+		out = append(out, exportExerciseSession{
+			ID:               s.ID,
+			RecommendationID: s.RecommendationID,
+			PerformedOn:      s.PerformedOn.Time.Format("2006-01-02"),
+			DurationMin:      s.DurationMin,
+			Notes:            s.Notes,
+			CreatedAt:        s.CreatedAt.Time,
+		})
+	}
+	return out
+}
+
+// rawJSON giữ nguyên JSONB từ DB; cột rỗng thành null (RawMessage rỗng không marshal được).
+func rawJSON(b []byte) json.RawMessage {
+	if len(b) == 0 {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(b)
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,83 @@
+package datarequests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"chidinh/modules/reports"
+)
+
+// erasePatient xoá hẳn bệnh nhân: file PDF báo cáo, archive export cũ, rồi row patients
+// (predictions, rescores, shadows, recommendations, reports xoá theo ON DELETE CASCADE).
+// Sau đó đếm lại để kiểm chứng và trả về tombstone ẩn danh.
+func (h *Controller) erasePatient(ctx context.Context, patientID int64) (Tombstone, error) {
+	before, err := h.Queries.CountPatientData(ctx, patientID)
+	if err != nil {
+		return Tombstone{}, err
+	}
+	files, err := h.Queries.ListReportFilesByPatient(ctx, patientID)
+	if err != nil {
+		return Tombstone{}, err
+	}
+	archives, err := h.Queries.ListDataRequestArchivesByPatient(ctx, patientID)
+	if err != nil {
+		return Tombstone{}, err
+	}
+	files = append(files, archives...)
+
+	// Xoá file trước: lỗi giữa chừng thì row vẫn còn và có thể chạy lại yêu cầu.
+	deleted := 0
+	for _, path := range files {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Tombstone{}, err
+		}
+		if err == nil {
+			deleted++
+		}
+	}
+	if err := os.RemoveAll(reports.PatientStorageDir(patientID)); err != nil {
+		return Tombstone{}, err
+	}
+
+	if err := h.Queries.ErasePatient(ctx, patientID); err != nil {
+		return Tombstone{}, err
+	}
+	if err := h.Queries.ClearDataRequestArchivesByPatient(ctx, patientID); err != nil {
+		return Tombstone{}, err
+	}
+
+	remaining, filesRemaining, err := h.checkErased(ctx, patientID, files)
+	if err != nil {
+		return Tombstone{}, err
+	}
+	return Tombstone{
+		ErasedAt:       time.Now().UTC(),
+		Deleted:        toDataCounts(before),
+		FilesDeleted:   deleted,
+		Remaining:      remaining,
+		FilesRemaining: filesRemaining,
+		Verified:       remaining.empty() && filesRemaining == 0,
+	}, nil
+}
+
+// checkErased đếm số row còn gắn với patientID và số file còn trên disk
+// (các path đã biết + thư mục báo cáo của bệnh nhân).
+func (h *Controller) checkErased(ctx context.Context, patientID int64, paths []string) (DataCounts, int, error) {
+	row, err := h.Queries.CountPatientData(ctx, patientID)
+	if err != nil {
+		return DataCounts{}, 0, err
+	}
+	remaining := 0
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			remaining++
+		}
+	}
+	if entries, err := os.ReadDir(reports.PatientStorageDir(patientID)); err == nil {
+		remaining += len(entries)
+	}
+	return toDataCounts(row), remaining, nil
+}
@@ -0,0 +1,14 @@
+package datarequests
+
+import "github.com/gin-gonic/gin"
+
+// RegisterDataRequestRoutes gắn endpoint export/erasure dữ liệu bệnh nhân (cần auth).
+func RegisterDataRequestRoutes(r *gin.RouterGroup, h *Controller) {
+	r.POST("/patients/:id/data-requests", h.CreateDataRequest)
+
+	group := r.Group("/data-requests")
+	group.GET("", h.ListDataRequests)
+	group.GET("/:id", h.GetDataRequest)
+	group.GET("/:id/download", h.DownloadDataExport)
+	group.GET("/:id/verify", h.VerifyErasure)
+}
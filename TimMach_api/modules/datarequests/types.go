@@ -0,0 +1,172 @@
+package datarequests
+
+import (
+	"encoding/json"
+	"time"
+
+	"chidinh/utils/pagination"
+)
+
+const (
+	TypeExport  = "export"
+	TypeErasure = "erasure"
+
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// CreateDataRequestRequest: erasure bắt buộc confirm = tên bệnh nhân để tránh xoá nhầm.
+type CreateDataRequestRequest struct {
+	Type    string `json:"type" binding:"required,oneof=export erasure"`
+	Confirm string `json:"confirm"`
+}
+
+// ListDataRequestsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
+type ListDataRequestsParams struct {
+	pagination.Params
+}
+
+type DataRequestResponse struct {
+	ID          int64      `json:"id"`
+	PatientID   int64      `json:"patient_id"`
+	Type        string     `json:"type"`
+	Status      string     `json:"status"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	Tombstone   *Tombstone `json:"tombstone,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type ListDataRequestsResponse struct {
+	Requests []DataRequestResponse `json:"requests"`
+	pagination.Meta
+}
+
+// DataCounts đếm số bản ghi gắn với một bệnh nhân theo từng bảng.
+type DataCounts struct {
+	Patients         int64 `json:"patients"`
+	Predictions      int64 `json:"predictions"`
+	Rescores         int64 `json:"rescores"`
+	Shadows          int64 `json:"shadows"`
+	Recommendations  int64 `json:"recommendations"`
+	Reports          int64 `json:"reports"`
+	Outcomes         int64 `json:"outcomes"`
+	ExerciseSessions int64 `json:"exercise_sessions"`
+	Emails           int64 `json:"emails"`    // email_outbox (nội dung email + file đính kèm)
+	Schedules        int64 `json:"schedules"` // report_schedules (địa chỉ email người nhận)
+}
+
+func (d DataCounts) empty() bool {
+	return d == DataCounts{}
+}
+
+// Tombstone là dấu vết ẩn danh còn lại sau khi xoá: chỉ số lượng đã xoá và kết quả kiểm tra,
+// không chứa tên, ngày sinh, chỉ số sức khoẻ hay email.
+type Tombstone struct {
+	ErasedAt       time.Time  `json:"erased_at"`
+	Deleted        DataCounts `json:"deleted"`
+	FilesDeleted   int        `json:"files_deleted"`
+	Remaining      DataCounts `json:"remaining"`
+	FilesRemaining int        `json:"files_remaining"`
+	Verified       bool       `json:"verified"`
+}
+
+// VerifyErasureResponse là kết quả kiểm tra lại (tại thời điểm gọi) rằng dữ liệu đã bị xoá hết.
+type VerifyErasureResponse struct {
+	RequestID      int64      `json:"request_id"`
+	CheckedAt      time.Time  `json:"checked_at"`
+	Remaining      DataCounts `json:"remaining"`
+	FilesRemaining int        `json:"files_remaining"`
+	Verified       bool       `json:"verified"`
+}
+
+// Nội dung file JSON trong archive export.
+
+type exportManifest struct {
+	RequestID    int64      `json:"request_id"`
+	PatientID    int64      `json:"patient_id"`
+	GeneratedAt  time.Time  `json:"generated_at"`
+	Counts       DataCounts `json:"counts"`
+	Files        []string   `json:"files"`
+	MissingFiles []string   `json:"missing_files"`
+}
+
+type exportProfile struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Gender    int16      `json:"gender"`
+	GenderStr string     `json:"gender_label"`
+	Dob       string     `json:"dob"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type exportPrediction struct {
+	ID                int64           `json:"id"`
+	CreatedAt         time.Time       `json:"created_at"`
+	Probability       float64         `json:"probability"`
+	RiskLabel         string          `json:"risk_label"`
+	ModelName         string          `json:"model_name"`
+	ModelVersion      string          `json:"model_version"`
+	FeatureSchemaHash string          `json:"feature_schema_hash"`
+	RawFeatures       json.RawMessage `json:"raw_features"`
+	Factors           json.RawMessage `json:"factors"`
+	Explanation       json.RawMessage `json:"explanation"`
+	Warnings          json.RawMessage `json:"warnings"`
+	Rescores          []exportRescore `json:"rescores"`
+	Shadows           []exportShadow  `json:"shadows"`
+}
+
+type exportRescore struct {
+	ModelName    string    `json:"model_name"`
+	ModelVersion string    `json:"model_version"`
+	Probability  float64   `json:"probability"`
+	RiskLabel    string    `json:"risk_label"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type exportShadow struct {
+	ModelName    string    `json:"model_name"`
+	ModelVersion string    `json:"model_version"`
+	Probability  *float64  `json:"probability"`
+	RiskLabel    string    `json:"risk_label"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type exportRecommendation struct {
+	ID           int64           `json:"id"`
+	PredictionID int64           `json:"prediction_id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	Plan         json.RawMessage `json:"plan"`
+}
+
+// recorded_by (user id của bác sĩ) không xuất ra.
+type exportOutcome struct {
+	ID         int64     `json:"id"`
+	EventType  string    `json:"event_type"`
+	Condition  string    `json:"condition,omitempty"`
+	OccurredOn string    `json:"occurred_on"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// recorded_by (user id của bác sĩ) không xuất ra.
+type exportExerciseSession struct {
+	ID               int64     `json:"id"`
+	RecommendationID *int64    `json:"recommendation_id,omitempty"`
+	PerformedOn      string    `json:"performed_on"`
+	DurationMin      int32     `json:"duration_min"`
+	Notes            string    `json:"notes,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type exportReport struct {
+	ID         int64           `json:"id"`
+	Filename   string          `json:"filename"`
+	File       string          `json:"file,omitempty"` // đường dẫn PDF trong archive
+	CreatedAt  time.Time       `json:"created_at"`
+	Recipients json.RawMessage `json:"recipients"`
+}
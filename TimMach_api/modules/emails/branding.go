@@ -0,0 +1,79 @@
+package emails
+
+import (
+	"context"
+	"errors"
+
+	db "chidinh/db/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultPrimaryColor = "#dc2626"
+
+// Cách bảo vệ file PDF báo cáo gửi qua email (clinic_brandings.pdf_protection, email_outbox.pdf_protection).
+const (
+	ProtectionNone = "none"
+	ProtectionDOB  = "dob"  // mật khẩu là ngày sinh bệnh nhân DDMMYYYY
+	ProtectionCode = "code" // mã dùng một lần, bác sĩ gửi cho bệnh nhân qua kênh riêng
+)
+
+// Branding là thông tin hiển thị của phòng khám trên email và báo cáo PDF.
+type Branding struct {
+	Clinic        string
+	Name          string
+	PrimaryColor  string
+	Footer        string
+	DefaultLocale string
+	Logo          []byte
+	LogoMime      string
+	PDFProtection string
+}
+
+// Brandings tra branding theo phòng khám của user (users.clinic → clinic_brandings);
+// user chưa gắn phòng khám hoặc phòng khám chưa cấu hình dùng DefaultName.
+type Brandings struct {
+	Queries     *db.Queries
+	DefaultName string
+}
+
+func NewBrandings(queries *db.Queries, defaultName string) *Brandings {
+	return &Brandings{Queries: queries, DefaultName: defaultName}
+}
+
+// Default là branding dùng khi không tìm thấy cấu hình của phòng khám.
+func (b *Brandings) Default() Branding {
+	name := "HeartCare Clinic"
+	if b != nil && b.DefaultName != "" {
+		name = b.DefaultName
+	}
+	return Branding{Name: name, PrimaryColor: defaultPrimaryColor, DefaultLocale: DefaultLocale, PDFProtection: ProtectionNone}
+}
+
+// ForUser trả branding phòng khám của userID.
+func (b *Brandings) ForUser(ctx context.Context, userID string) (Branding, error) {
+	if b == nil || b.Queries == nil {
+		return b.Default(), nil
+	}
+	row, err := b.Queries.GetClinicBrandingByUser(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return b.Default(), nil
+	}
+	if err != nil {
+		return b.Default(), err
+	}
+	return toBranding(row), nil
+}
+
+func toBranding(r db.ClinicBranding) Branding {
+	return Branding{
+		Clinic:        r.Clinic,
+		Name:          r.DisplayName,
+		PrimaryColor:  r.PrimaryColor,
+		Footer:        r.Footer,
+		DefaultLocale: r.DefaultLocale,
+		Logo:          r.Logo,
+		LogoMime:      r.LogoMime,
+		PDFProtection: r.PdfProtection,
+	}
+}
@@ -0,0 +1,199 @@
+package emails
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Controller gom dependencies cho template email và branding phòng khám.
+type Controller struct {
+	Queries   *db.Queries
+	Brandings *Brandings
+}
+
+func NewController(q *db.Queries, brandings *Brandings) *Controller {
+	return &Controller{Queries: q, Brandings: brandings}
+}
+
+// GET /email-templates
+func (h *Controller) ListTemplates(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	names := TemplateNames()
+	resp := ListTemplatesResponse{Templates: make([]TemplateInfo, 0, len(names))}
+	for _, name := range names {
+		resp.Templates = append(resp.Templates, TemplateInfo{Name: name, Locales: TemplateLocales(name)})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /email-templates/:name/preview?locale=vi|en
+// Render template với dữ liệu mẫu và branding phòng khám của user (logo nhúng dạng data URI).
+func (h *Controller) PreviewTemplate(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req PreviewParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	name := c.Param("name")
+	sample, ok := previewData[name]
+	if !ok {
+		utils.RespondError(c, http.StatusNotFound, "email template not found")
+		return
+	}
+
+	branding, err := h.Brandings.ForUser(c, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot load clinic branding")
+		return
+	}
+
+	email, err := RenderPreview(name, req.Locale, branding, sample())
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, PreviewResponse{
+		Name:    name,
+		Locale:  email.Locale,
+		Subject: email.Subject,
+		Text:    email.Text,
+		HTML:    email.HTML,
+	})
+}
+
+// GET /clinic-brandings (admin)
+func (h *Controller) ListBrandings(c *gin.Context) {
+	items, err := h.Queries.ListClinicBrandings(c)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list clinic brandings")
+		return
+	}
+
+	resp := ListBrandingsResponse{Brandings: make([]BrandingResponse, 0, len(items))}
+	for _, item := range items {
+		resp.Brandings = append(resp.Brandings, toBrandingResponse(item))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PUT /clinic-brandings/:clinic (admin)
+// :clinic khớp users.clinic (claim "clinic" của Keycloak).
+func (h *Controller) UpsertBranding(c *gin.Context) {
+	clinic := strings.TrimSpace(c.Param("clinic"))
+	if clinic == "" {
+		utils.RespondError(c, http.StatusBadRequest, "invalid clinic")
+		return
+	}
+
+	var req BrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var current *db.ClinicBranding
+	existing, err := h.Queries.GetClinicBranding(c, clinic)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch clinic branding")
+		return
+	}
+	if err == nil {
+		current = &existing
+	}
+
+	params, err := normalizeBranding(clinic, req, current)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	branding, err := h.Queries.UpsertClinicBranding(c, params)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot save clinic branding")
+		return
+	}
+
+	c.JSON(http.StatusOK, toBrandingResponse(branding))
+}
+
+// DELETE /clinic-brandings/:clinic (admin)
+// Phòng khám quay về branding mặc định (CLINIC_NAME).
+func (h *Controller) DeleteBranding(c *gin.Context) {
+	n, err := h.Queries.DeleteClinicBranding(c, c.Param("clinic"))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot delete clinic branding")
+		return
+	}
+	if n == 0 {
+		utils.RespondError(c, http.StatusNotFound, "clinic branding not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /email-outbox/:id
+// Trạng thái gửi hiện tại (queued | sending | sent | failed | bounced) và lịch sử các lần gửi.
+func (h *Controller) GetEmailStatus(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	email, ok := h.ownedEmail(c, userID)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.Queries.ListEmailAttempts(c, email.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list email attempts")
+		return
+	}
+
+	c.JSON(http.StatusOK, toEmailStatusResponse(email, attempts))
+}
+
+func (h *Controller) ownedEmail(c *gin.Context, userID string) (db.EmailOutbox, bool) {
+	emailID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid email id")
+		return db.EmailOutbox{}, false
+	}
+
+	email, err := h.Queries.GetEmailOutboxByID(c, emailID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "email not found")
+		return email, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch email")
+		return email, false
+	}
+	if email.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "email does not belong to user")
+		return email, false
+	}
+	return email, true
+}
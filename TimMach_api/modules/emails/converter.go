@@ -0,0 +1,136 @@
+package emails
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// previewData là dữ liệu mẫu cho từng template khi xem trước.
+var previewData = map[string]func() any{
+	"report": func() any {
+		return ReportEmailData{
+			PatientName: "Nguyễn Văn A",
+			ReportDate:  time.Now().Format("2006-01-02"),
+			Message:     "Tiếp tục duy trì chế độ tập luyện và tái khám sau 3 tháng.",
+		}
+	},
+	"reminder": func() any {
+		return ReminderEmailData{
+			InactiveMonths: 3,
+			Patients: []ReminderPatient{
+				{Name: "Nguyễn Văn A", LastPredictionAt: time.Now().AddDate(0, -4, 0).Format("2006-01-02")},
+				{Name: "Trần Thị B"},
+			},
+		}
+	},
+}
+
+func toBrandingResponse(r db.ClinicBranding) BrandingResponse {
+	return BrandingResponse{
+		Clinic:        r.Clinic,
+		DisplayName:   r.DisplayName,
+		HasLogo:       len(r.Logo) > 0,
+		LogoMime:      r.LogoMime,
+		PrimaryColor:  r.PrimaryColor,
+		DefaultLocale: r.DefaultLocale,
+		Footer:        r.Footer,
+		PDFProtection: r.PdfProtection,
+		UpdatedAt:     r.UpdatedAt.Time,
+	}
+}
+
+// normalizeBranding kiểm tra request và tạo params upsert; current là branding đang lưu (nếu có)
+// để giữ logo/pdf_protection khi request không gửi các trường này.
+func normalizeBranding(clinic string, req BrandingRequest, current *db.ClinicBranding) (db.UpsertClinicBrandingParams, error) {
+	params := db.UpsertClinicBrandingParams{
+		Clinic:        clinic,
+		DisplayName:   strings.TrimSpace(req.DisplayName),
+		PrimaryColor:  strings.ToLower(strings.TrimSpace(req.PrimaryColor)),
+		DefaultLocale: ResolveLocale(req.DefaultLocale, DefaultLocale),
+		Footer:        strings.TrimSpace(req.Footer),
+		PdfProtection: ProtectionNone,
+	}
+	switch {
+	case req.PDFProtection != nil:
+		params.PdfProtection = *req.PDFProtection
+	case current != nil:
+		params.PdfProtection = current.PdfProtection
+	}
+	if params.DisplayName == "" {
+		return params, errors.New("display_name is required")
+	}
+	if params.PrimaryColor == "" {
+		params.PrimaryColor = defaultPrimaryColor
+	}
+	if !colorPattern.MatchString(params.PrimaryColor) {
+		return params, errors.New("primary_color must be #rrggbb")
+	}
+
+	switch {
+	case req.LogoBase64 == nil:
+		if current != nil {
+			params.Logo = current.Logo
+			params.LogoMime = current.LogoMime
+		}
+	case *req.LogoBase64 == "":
+	default:
+		logo, err := base64.StdEncoding.DecodeString(*req.LogoBase64)
+		if err != nil {
+			return params, errors.New("logo_base64 must be base64 encoded")
+		}
+		if len(logo) > maxLogoSize {
+			return params, errors.New("logo must not exceed 256KB")
+		}
+		mime := http.DetectContentType(logo)
+		if logoExtension(mime) == "" {
+			return params, errors.New("logo must be PNG, JPEG or GIF")
+		}
+		params.Logo = logo
+		params.LogoMime = mime
+	}
+	return params, nil
+}
+
+func toEmailStatusResponse(e db.EmailOutbox, attempts []db.EmailAttempt) EmailStatusResponse {
+	resp := EmailStatusResponse{
+		ID:            e.ID,
+		To:            e.ToAddress,
+		Role:          e.RecipientRole,
+		PDFProtection: e.PdfProtection,
+		Subject:       e.Subject,
+		PatientID:     e.PatientID,
+		ReportID:      e.ReportID,
+		Status:        e.Status,
+		Attempts:      e.Attempts,
+		MaxAttempts:   e.MaxAttempts,
+		LastError:     e.LastError,
+		CreatedAt:     e.CreatedAt.Time,
+		History:       make([]EmailAttemptResponse, 0, len(attempts)),
+	}
+	if e.Status == StatusQueued {
+		t := e.NextAttemptAt.Time
+		resp.NextAttemptAt = &t
+	}
+	if e.SentAt.Valid {
+		t := e.SentAt.Time
+		resp.SentAt = &t
+	}
+	for _, a := range attempts {
+		resp.History = append(resp.History, EmailAttemptResponse{
+			Attempt:    a.Attempt,
+			Status:     a.Status,
+			Error:      a.Error,
+			StartedAt:  a.StartedAt.Time,
+			FinishedAt: a.FinishedAt.Time,
+		})
+	}
+	return resp
+}
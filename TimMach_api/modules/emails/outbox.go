@@ -0,0 +1,137 @@
+package emails
+
+import (
+	"context"
+	"encoding/json"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils/fieldcrypt"
+	"chidinh/utils/mailer"
+)
+
+// Trạng thái email trong outbox (email_outbox.status).
+const (
+	StatusQueued  = "queued"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	StatusBounced = "bounced"
+)
+
+// Vai trò người nhận (email_outbox.recipient_role).
+const (
+	RoleTo  = "to"
+	RoleCc  = "cc"
+	RoleBcc = "bcc"
+)
+
+const defaultMaxAttempts = 5
+
+// Outbox ghi email vào email_outbox để Sender gửi nền; request không chờ SMTP.
+type Outbox struct {
+	Queries     *db.Queries
+	MaxAttempts int
+}
+
+func NewOutbox(queries *db.Queries, maxAttempts int) *Outbox {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &Outbox{Queries: queries, MaxAttempts: maxAttempts}
+}
+
+// EnqueueOptions gắn email với user gửi và (tuỳ chọn) bệnh nhân/báo cáo liên quan.
+// ReportID khác nil thì recipient được thêm vào reports.recipients và cập nhật theo trạng thái gửi.
+// PDFProtection ghi lại cách file PDF đính kèm đã được mã hoá (mặc định ProtectionNone).
+type EnqueueOptions struct {
+	UserID        string
+	PatientID     *int64
+	ReportID      *int64
+	PDFProtection string
+}
+
+// Enqueue lưu msg vào outbox với trạng thái queued, mỗi địa chỉ trong To/Cc/Bcc một dòng
+// (theo thứ tự đó) để theo dõi trạng thái gửi của từng người nhận.
+func (o *Outbox) Enqueue(ctx context.Context, msg mailer.Message, opts EnqueueOptions) ([]db.EmailOutbox, error) {
+	var addresses, roles []string
+	for _, group := range []struct {
+		role  string
+		addrs []string
+	}{{RoleTo, msg.To}, {RoleCc, msg.Cc}, {RoleBcc, msg.Bcc}} {
+		for _, addr := range group.addrs {
+			addresses = append(addresses, addr)
+			roles = append(roles, group.role)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, mailer.ErrNoRecipients
+	}
+
+	protection := opts.PDFProtection
+	if protection == "" {
+		protection = ProtectionNone
+	}
+	attachments := msg.Attachments
+	if attachments == nil {
+		attachments = []mailer.Attachment{}
+	}
+	attachmentsJSON, err := json.Marshal(attachments)
+	if err != nil {
+		return nil, err
+	}
+	// File đính kèm (PDF báo cáo) được mã hoá như PII; FinishEmailAttempt xoá nó khi email đã xong.
+	if attachmentsJSON, err = fieldcrypt.Default().SealJSON(attachmentsJSON); err != nil {
+		return nil, err
+	}
+	return o.Queries.EnqueueEmails(ctx, db.EnqueueEmailsParams{
+		UserID:        opts.UserID,
+		PatientID:     opts.PatientID,
+		ReportID:      opts.ReportID,
+		HeaderTo:      nonNil(msg.To),
+		HeaderCc:      nonNil(msg.Cc),
+		Subject:       msg.Subject,
+		BodyText:      msg.Text,
+		BodyHtml:      msg.HTML,
+		Attachments:   attachmentsJSON,
+		PdfProtection: protection,
+		MaxAttempts:   int32(o.MaxAttempts),
+		ToAddresses:   addresses,
+		Roles:         roles,
+	})
+}
+
+// toMessage dựng lại mailer.Message từ một dòng outbox: header To/Cc chung của email,
+// nhưng chỉ gửi tới to_address của dòng này.
+func toMessage(e db.EmailOutbox) (mailer.Message, error) {
+	var attachments []mailer.Attachment
+	if len(e.Attachments) > 0 {
+		raw, err := fieldcrypt.Default().OpenJSON(e.Attachments)
+		if err != nil {
+			return mailer.Message{}, err
+		}
+		if err := json.Unmarshal(raw, &attachments); err != nil {
+			return mailer.Message{}, err
+		}
+	}
+	headerTo := e.HeaderTo
+	if len(headerTo) == 0 {
+		headerTo = []string{e.ToAddress}
+	}
+	return mailer.Message{
+		To:          headerTo,
+		Cc:          e.HeaderCc,
+		Rcpt:        []string{e.ToAddress},
+		Subject:     e.Subject,
+		Text:        e.BodyText,
+		HTML:        e.BodyHtml,
+		Attachments: attachments,
+	}, nil
+}
+
+// nonNil tránh ghi NULL vào cột TEXT[] NOT NULL.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
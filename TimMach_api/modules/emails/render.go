@@ -0,0 +1,214 @@
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"chidinh/utils/mailer"
+)
+
+// Mỗi email gồm templates/<locale>/<name>.txt (text/template, định nghĩa "subject" và "text")
+// và templates/<locale>/<name>.html (html/template, định nghĩa "subject", "content", "disclaimer")
+// được bọc bởi layout.html chung (header branding + footer).
+//
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	DefaultLocale = "vi"
+	// logoContentID là Content-ID của logo phòng khám đính kèm inline trong email HTML.
+	logoContentID = "clinic-logo"
+)
+
+// SupportedLocales là các ngôn ngữ có template; locale khác sẽ dùng DefaultLocale.
+var SupportedLocales = []string{"vi", "en"}
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates được parse một lần lúc khởi động, key là "<locale>/<name>".
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[string]emailTemplate {
+	layout := htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html"))
+	out := make(map[string]emailTemplate)
+	for _, locale := range SupportedLocales {
+		files, err := templateFS.ReadDir(path.Join("templates", locale))
+		if err != nil {
+			panic(err)
+		}
+		for _, f := range files {
+			name, ok := strings.CutSuffix(f.Name(), ".txt")
+			if !ok {
+				continue
+			}
+			base := path.Join("templates", locale, name)
+			html := htmltemplate.Must(htmltemplate.Must(layout.Clone()).ParseFS(templateFS, base+".html"))
+			text := texttemplate.Must(texttemplate.ParseFS(templateFS, base+".txt"))
+			out[locale+"/"+name] = emailTemplate{text: text, html: html}
+		}
+	}
+	return out
+}
+
+// Email là email đã render, chuyển sang mailer.Message bằng Message.
+type Email struct {
+	Locale  string
+	Subject string
+	Text    string
+	HTML    string
+	Inline  []mailer.Attachment
+}
+
+// Message tạo mailer.Message (chưa có người nhận); Inline (logo) được gửi kèm các attachment.
+func (e Email) Message(attachments ...mailer.Attachment) mailer.Message {
+	return mailer.Message{
+		Subject:     e.Subject,
+		Text:        e.Text,
+		HTML:        e.HTML,
+		Attachments: append(append([]mailer.Attachment(nil), e.Inline...), attachments...),
+	}
+}
+
+// clinicView là branding đưa vào template; LogoSrc là cid: khi gửi mail, data URI khi xem trước.
+type clinicView struct {
+	Name         string
+	PrimaryColor string
+	Footer       string
+	LogoSrc      htmltemplate.URL
+}
+
+type templateView struct {
+	Locale string
+	Clinic clinicView
+	Data   any
+}
+
+// Render render template name theo locale (fallback DefaultLocale) với branding b.
+// Logo (nếu có) được đính kèm inline và tham chiếu bằng cid:.
+func Render(name, locale string, b Branding, data any) (Email, error) {
+	return render(name, locale, b, data, false)
+}
+
+// RenderPreview giống Render nhưng nhúng logo dạng data URI để xem trực tiếp trên trình duyệt.
+func RenderPreview(name, locale string, b Branding, data any) (Email, error) {
+	return render(name, locale, b, data, true)
+}
+
+func render(name, locale string, b Branding, data any, preview bool) (Email, error) {
+	locale = ResolveLocale(locale, b.DefaultLocale)
+	tpl, ok := templates[locale+"/"+name]
+	if !ok {
+		tpl, ok = templates[DefaultLocale+"/"+name]
+		locale = DefaultLocale
+	}
+	if !ok {
+		return Email{}, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	email := Email{Locale: locale}
+	view := templateView{
+		Locale: locale,
+		Clinic: clinicView{Name: b.Name, PrimaryColor: b.PrimaryColor, Footer: b.Footer},
+		Data:   data,
+	}
+	if len(b.Logo) > 0 {
+		if preview {
+			view.Clinic.LogoSrc = htmltemplate.URL("data:" + b.LogoMime + ";base64," + base64.StdEncoding.EncodeToString(b.Logo))
+		} else {
+			view.Clinic.LogoSrc = htmltemplate.URL("cid:" + logoContentID)
+			email.Inline = []mailer.Attachment{{
+				Filename:  logoContentID + logoExtension(b.LogoMime),
+				MimeType:  b.LogoMime,
+				Content:   b.Logo,
+				ContentID: logoContentID,
+			}}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.text.ExecuteTemplate(&buf, "subject", view); err != nil {
+		return Email{}, fmt.Errorf("render subject: %w", err)
+	}
+	email.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tpl.text.ExecuteTemplate(&buf, "text", view); err != nil {
+		return Email{}, fmt.Errorf("render text: %w", err)
+	}
+	email.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	buf.Reset()
+	if err := tpl.html.ExecuteTemplate(&buf, "layout", view); err != nil {
+		return Email{}, fmt.Errorf("render html: %w", err)
+	}
+	email.HTML = buf.String()
+	return email, nil
+}
+
+// ResolveLocale chọn locale được hỗ trợ theo thứ tự: requested, fallback, DefaultLocale.
+// Chấp nhận dạng "en-US" / "en_US".
+func ResolveLocale(requested, fallback string) string {
+	for _, l := range []string{requested, fallback} {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if i := strings.IndexAny(l, "-_"); i > 0 {
+			l = l[:i]
+		}
+		if slices.Contains(SupportedLocales, l) {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// TemplateNames trả về tên các template email theo thứ tự alphabet.
+func TemplateNames() []string {
+	seen := make(map[string]struct{})
+	for key := range templates {
+		_, name, _ := strings.Cut(key, "/")
+		seen[name] = struct{}{}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TemplateLocales trả về các locale có template name.
+func TemplateLocales(name string) []string {
+	var out []string
+	for _, l := range SupportedLocales {
+		if _, ok := templates[l+"/"+name]; ok {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func logoExtension(mime string) string {
+	switch mime {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ""
+	}
+}
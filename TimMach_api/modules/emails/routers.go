@@ -0,0 +1,20 @@
+package emails
+
+import "github.com/gin-gonic/gin"
+
+// RegisterEmailRoutes gắn endpoint xem template email và trạng thái gửi email (cần auth).
+func RegisterEmailRoutes(r *gin.RouterGroup, h *Controller) {
+	group := r.Group("/email-templates")
+	group.GET("", h.ListTemplates)
+	group.GET("/:name/preview", h.PreviewTemplate)
+
+	r.GET("/email-outbox/:id", h.GetEmailStatus)
+}
+
+// RegisterBrandingRoutes gắn endpoint quản lý branding phòng khám; group truyền vào phải đã có middleware kiểm role admin.
+func RegisterBrandingRoutes(r *gin.RouterGroup, h *Controller) {
+	group := r.Group("/clinic-brandings")
+	group.GET("", h.ListBrandings)
+	group.PUT("/:clinic", h.UpsertBranding)
+	group.DELETE("/:clinic", h.DeleteBranding)
+}
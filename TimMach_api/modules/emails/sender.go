@@ -0,0 +1,167 @@
+package emails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/mailer"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	sendBatchSize = 20
+	// Retry sau retryBaseDelay, 2x, 4x... tối đa retryMaxDelay.
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+)
+
+// Sender gửi các email đến hạn trong email_outbox qua Mailer, giới hạn RatePerMinute email/phút trên mỗi replica
+// (lastSent nằm trong process, N replica gửi tối đa N*RatePerMinute).
+// Lỗi tạm thời được retry với backoff tới max_attempts (sau đó failed); SMTP từ chối 5xx là bounced.
+type Sender struct {
+	Queries       *db.Queries
+	Mailer        mailer.Sender
+	RatePerMinute int
+
+	lastSent time.Time
+}
+
+func NewSender(queries *db.Queries, m mailer.Sender, ratePerMinute int) *Sender {
+	return &Sender{Queries: queries, Mailer: m, RatePerMinute: ratePerMinute}
+}
+
+// Run chạy SendPending mỗi interval cho tới khi ctx bị huỷ.
+func (s *Sender) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.SendPending(ctx); err != nil {
+			utils.L().Warnw("send queued emails failed", "error", err, "processed", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendPending gửi mọi email đến hạn, trả về số email đã xử lý (gồm cả lần gửi lỗi).
+func (s *Sender) SendPending(ctx context.Context) (int, error) {
+	processed := 0
+	limit := s.claimLimit()
+	for {
+		batch, err := s.Queries.ClaimDueEmails(ctx, limit)
+		if err != nil {
+			return processed, err
+		}
+		for _, e := range batch {
+			if err := s.throttle(ctx); err != nil {
+				return processed, err
+			}
+			if err := s.deliver(ctx, e); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+		if len(batch) < int(limit) {
+			return processed, nil
+		}
+	}
+}
+
+// claimLimit là số email claim mỗi lượt: không quá số email gửi được trong một phút, để email đã claim
+// không phải chờ throttle tới lúc claim quá hạn và bị worker khác lấy lại.
+func (s *Sender) claimLimit() int32 {
+	if s.RatePerMinute > 0 && s.RatePerMinute < sendBatchSize {
+		return int32(s.RatePerMinute)
+	}
+	return sendBatchSize
+}
+
+// deliver gửi một email đã claim và ghi kết quả lần gửi. Email mà worker không còn giữ claim thì bỏ qua.
+func (s *Sender) deliver(ctx context.Context, e db.EmailOutbox) error {
+	claimedAt, err := s.Queries.RefreshEmailClaim(ctx, db.RefreshEmailClaimParams{ID: e.ID, LockedAt: e.LockedAt})
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.L().Warnw("email claim expired before sending, skipped", "email_id", e.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("refresh email %d claim: %w", e.ID, err)
+	}
+
+	startedAt := time.Now()
+	msg, err := toMessage(e)
+	if err == nil {
+		err = s.Mailer.SendMessage(msg)
+	}
+	s.lastSent = time.Now()
+
+	params := db.FinishEmailAttemptParams{
+		ID:        e.ID,
+		ClaimedAt: claimedAt,
+		StartedAt: pgtype.Timestamptz{Time: startedAt, Valid: true},
+	}
+	switch {
+	case err == nil:
+		params.Status, params.AttemptStatus = StatusSent, StatusSent
+	case errors.Is(err, mailer.ErrRejected):
+		params.Status, params.AttemptStatus = StatusBounced, StatusBounced
+	case e.Attempts >= e.MaxAttempts:
+		params.Status, params.AttemptStatus = StatusFailed, StatusFailed
+	default:
+		params.Status, params.AttemptStatus = StatusQueued, StatusFailed
+		params.NextAttemptAt = pgtype.Timestamptz{Time: time.Now().Add(retryDelay(e.Attempts)), Valid: true}
+	}
+	if err != nil {
+		params.LastError = err.Error()
+		utils.L().Warnw("send email failed", "error", err, "email_id", e.ID, "attempt", e.Attempts, "status", params.Status)
+	}
+
+	_, err = s.Queries.FinishEmailAttempt(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.L().Warnw("email claim expired before recording attempt", "email_id", e.ID, "status", params.Status)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("record email %d attempt: %w", e.ID, err)
+	}
+	return nil
+}
+
+// throttle chờ đủ khoảng cách giữa hai lần gửi theo RatePerMinute (<= 0 là không giới hạn).
+func (s *Sender) throttle(ctx context.Context) error {
+	if s.RatePerMinute <= 0 || s.lastSent.IsZero() {
+		return nil
+	}
+	wait := time.Until(s.lastSent.Add(time.Minute / time.Duration(s.RatePerMinute)))
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryDelay là thời gian chờ sau lần gửi lỗi thứ attempt (bắt đầu từ 1).
+func retryDelay(attempt int32) time.Duration {
+	delay := retryBaseDelay
+	for i := int32(1); i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, retryMaxDelay)
+}
@@ -0,0 +1,105 @@
+package emails
+
+import "time"
+
+// maxLogoSize giới hạn logo phòng khám (được gửi inline trong mọi email).
+const maxLogoSize = 256 << 10
+
+// ReportEmailData là dữ liệu của template "report" (gửi báo cáo PDF cho bệnh nhân).
+type ReportEmailData struct {
+	PatientName string
+	ReportDate  string
+	Message     string // lời nhắn thêm của bác sĩ, có thể rỗng
+	// PDFProtection (dob/code) thêm hướng dẫn mở file PDF có mật khẩu; mật khẩu không bao giờ nằm trong email.
+	PDFProtection string
+}
+
+// ReminderEmailData là dữ liệu của template "reminder" (nhắc tái khám khi bệnh nhân lâu chưa có prediction).
+type ReminderEmailData struct {
+	InactiveMonths int
+	Patients       []ReminderPatient
+	Truncated      bool   // còn bệnh nhân khác ngoài danh sách
+	Message        string // lời nhắn thêm của bác sĩ, có thể rỗng
+}
+
+// ReminderPatient: LastPredictionAt (yyyy-mm-dd) rỗng nghĩa là chưa có prediction nào.
+type ReminderPatient struct {
+	Name             string
+	LastPredictionAt string
+}
+
+type TemplateInfo struct {
+	Name    string   `json:"name"`
+	Locales []string `json:"locales"`
+}
+
+type ListTemplatesResponse struct {
+	Templates []TemplateInfo `json:"templates"`
+}
+
+type PreviewParams struct {
+	Locale string `form:"locale"`
+}
+
+// PreviewResponse là template render với dữ liệu mẫu và branding phòng khám của user.
+type PreviewResponse struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// BrandingRequest: LogoBase64 = nil giữ logo hiện tại, "" là bỏ logo; logo phải là PNG/JPEG/GIF.
+// PDFProtection (none | dob | code) là cách mã hoá PDF báo cáo gửi email mặc định của phòng khám; nil giữ giá trị hiện tại.
+type BrandingRequest struct {
+	DisplayName   string  `json:"display_name" binding:"required,max=200"`
+	LogoBase64    *string `json:"logo_base64"`
+	PrimaryColor  string  `json:"primary_color"` // #rrggbb, mặc định #dc2626
+	DefaultLocale string  `json:"default_locale"`
+	Footer        string  `json:"footer" binding:"max=500"`
+	PDFProtection *string `json:"pdf_protection" binding:"omitempty,oneof=none dob code"`
+}
+
+type BrandingResponse struct {
+	Clinic        string    `json:"clinic"`
+	DisplayName   string    `json:"display_name"`
+	HasLogo       bool      `json:"has_logo"`
+	LogoMime      string    `json:"logo_mime,omitempty"`
+	PrimaryColor  string    `json:"primary_color"`
+	DefaultLocale string    `json:"default_locale"`
+	Footer        string    `json:"footer"`
+	PDFProtection string    `json:"pdf_protection"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ListBrandingsResponse struct {
+	Brandings []BrandingResponse `json:"brandings"`
+}
+
+// EmailStatusResponse là trạng thái gửi của một email trong outbox kèm lịch sử từng lần gửi.
+type EmailStatusResponse struct {
+	ID            int64                  `json:"id"`
+	To            string                 `json:"to"`
+	Role          string                 `json:"role"`           // to | cc | bcc
+	PDFProtection string                 `json:"pdf_protection"` // none | dob | code
+	Subject       string                 `json:"subject"`
+	PatientID     *int64                 `json:"patient_id,omitempty"`
+	ReportID      *int64                 `json:"report_id,omitempty"`
+	Status        string                 `json:"status"` // queued | sending | sent | failed | bounced
+	Attempts      int32                  `json:"attempts"`
+	MaxAttempts   int32                  `json:"max_attempts"`
+	NextAttemptAt *time.Time             `json:"next_attempt_at,omitempty"` // chỉ có khi đang queued
+	LastError     string                 `json:"last_error,omitempty"`
+	SentAt        *time.Time             `json:"sent_at,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	History       []EmailAttemptResponse `json:"history"`
+}
+
+type EmailAttemptResponse struct {
+	Attempt    int32     `json:"attempt"`
+	Status     string    `json:"status"` // sent | failed | bounced
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
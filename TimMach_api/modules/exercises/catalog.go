@@ -0,0 +1,338 @@
+package exercises
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	catalogVersion = 1
+	maxCatalogSize = 5 << 20
+)
+
+// ErrInvalidCatalog được trả về khi tài liệu import có lỗi validate (chi tiết nằm trong ImportResult.Errors).
+var ErrInvalidCatalog = errors.New("invalid catalog")
+
+// ImportOptions điều khiển cách xử lý template đã tồn tại.
+// OnConflict: "skip" (giữ bản trong DB, báo conflict) hoặc "overwrite" (cập nhật theo file).
+type ImportOptions struct {
+	OnConflict string
+	DryRun     bool
+}
+
+// DecodeCatalog đọc tài liệu JSON hoặc YAML.
+func DecodeCatalog(format string, data []byte) (CatalogDocument, error) {
+	var doc CatalogDocument
+	var err error
+	switch format {
+	case "yaml":
+		err = yaml.Unmarshal(data, &doc)
+	case "json", "":
+		err = json.Unmarshal(data, &doc)
+	default:
+		return doc, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return doc, fmt.Errorf("cannot parse %s document: %w", format, err)
+	}
+	if doc.Version != 0 && doc.Version != catalogVersion {
+		return doc, fmt.Errorf("unsupported catalog version %d", doc.Version)
+	}
+	return doc, nil
+}
+
+// EncodeCatalog ghi tài liệu ra JSON hoặc YAML, trả kèm content type.
+func EncodeCatalog(format string, doc CatalogDocument) ([]byte, string, error) {
+	switch format {
+	case "yaml":
+		data, err := yaml.Marshal(doc)
+		return data, "application/yaml", err
+	default:
+		data, err := json.MarshalIndent(doc, "", "  ")
+		return data, "application/json", err
+	}
+}
+
+// ExportCatalog xuất toàn bộ tag + template hiện có.
+func ExportCatalog(ctx context.Context, q *db.Queries) (CatalogDocument, error) {
+	doc := CatalogDocument{Version: catalogVersion}
+
+	tags, err := q.ListExerciseTags(ctx)
+	if err != nil {
+		return doc, err
+	}
+	for _, t := range tags {
+		doc.Tags = append(doc.Tags, CatalogTag{Name: t.Name, Description: t.Description})
+	}
+
+	templates, err := q.ListExerciseTemplates(ctx)
+	if err != nil {
+		return doc, err
+	}
+	doc.Templates = make([]CatalogTemplate, 0, len(templates))
+	for _, t := range templates {
+		doc.Templates = append(doc.Templates, toCatalogTemplate(t))
+	}
+	return doc, nil
+}
+
+// ImportCatalog validate toàn bộ tài liệu rồi upsert template theo slug trong một transaction:
+// lỗi giữa chừng thì không template/tag nào được ghi. Nếu có lỗi validate thì không ghi gì và trả ErrInvalidCatalog.
+func ImportCatalog(ctx context.Context, pool *pgxpool.Pool, q *db.Queries, doc CatalogDocument, opts ImportOptions) (ImportResult, error) {
+	if opts.DryRun {
+		return importCatalog(ctx, q, doc, opts)
+	}
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := importCatalog(ctx, q.WithTx(tx), doc, opts)
+	if err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// importCatalog làm phần việc của ImportCatalog trên q (đã gắn transaction khi không phải dry run).
+// Template chưa có slug trùng nhưng trùng tên với template cũ mang slug backfill "<tên>-<id>"
+// (migration add_exercise_template_slug) được coi là cùng template và nhận slug của catalog.
+func importCatalog(ctx context.Context, q *db.Queries, doc CatalogDocument, opts ImportOptions) (ImportResult, error) {
+	result := ImportResult{
+		DryRun:    opts.DryRun,
+		Created:   []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+		Conflicts: []ImportConflict{},
+		Errors:    []ImportItemError{},
+	}
+
+	vocabulary, err := q.ListExerciseTags(ctx)
+	if err != nil {
+		return result, err
+	}
+	knownTags := make(map[string]struct{}, len(vocabulary)+len(doc.Tags))
+	for _, t := range vocabulary {
+		knownTags[t.Name] = struct{}{}
+	}
+	docTags := make([]CatalogTag, 0, len(doc.Tags))
+	for _, t := range doc.Tags {
+		names := normalizeTags([]string{t.Name})
+		if len(names) == 0 {
+			continue
+		}
+		knownTags[names[0]] = struct{}{}
+		docTags = append(docTags, CatalogTag{Name: names[0], Description: strings.TrimSpace(t.Description)})
+	}
+
+	items := make([]CatalogTemplate, 0, len(doc.Templates))
+	seen := make(map[string]int, len(doc.Templates))
+	for idx, raw := range doc.Templates {
+		item, err := normalizeCatalogTemplate(raw)
+		if err == nil {
+			if prev, dup := seen[item.Slug]; dup {
+				err = fmt.Errorf("duplicate slug (same as item %d)", prev)
+			}
+		}
+		if err == nil {
+			var unknown []string
+			for _, t := range item.Tags {
+				if _, ok := knownTags[t]; !ok {
+					unknown = append(unknown, t)
+				}
+			}
+			if len(unknown) > 0 {
+				err = fmt.Errorf("unknown tags: %s", strings.Join(unknown, ", "))
+			}
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, ImportItemError{Index: idx, Slug: item.Slug, Message: err.Error()})
+			continue
+		}
+		seen[item.Slug] = idx
+		items = append(items, item)
+	}
+	if len(result.Errors) > 0 {
+		return result, ErrInvalidCatalog
+	}
+
+	existing, err := q.ListExerciseTemplates(ctx)
+	if err != nil {
+		return result, err
+	}
+	bySlug := make(map[string]db.ExerciseTemplate, len(existing))
+	legacyByName := make(map[string]db.ExerciseTemplate)
+	for _, t := range existing {
+		bySlug[t.Slug] = t
+		if isLegacySlug(t) {
+			if _, dup := legacyByName[utils.Slugify(t.Name)]; !dup {
+				legacyByName[utils.Slugify(t.Name)] = t
+			}
+		}
+	}
+
+	if !opts.DryRun {
+		for _, t := range docTags {
+			if err := q.EnsureExerciseTag(ctx, db.EnsureExerciseTagParams{Name: t.Name, Description: t.Description}); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	for _, item := range items {
+		current, exists := bySlug[item.Slug]
+		if !exists {
+			nameKey := utils.Slugify(item.Name)
+			if legacy, ok := legacyByName[nameKey]; ok {
+				delete(legacyByName, nameKey)
+				if !opts.DryRun {
+					if err := q.UpdateExerciseTemplateSlug(ctx, db.UpdateExerciseTemplateSlugParams{ID: legacy.ID, Slug: item.Slug}); err != nil {
+						return result, fmt.Errorf("relink template %s: %w", item.Slug, err)
+					}
+				}
+				legacy.Slug = item.Slug
+				current, exists = legacy, true
+			}
+		}
+		if !exists {
+			if !opts.DryRun {
+				if _, err := q.CreateExerciseTemplate(ctx, db.CreateExerciseTemplateParams{
+					Slug:            item.Slug,
+					Name:            item.Name,
+					Intensity:       item.Intensity,
+					Description:     item.Description,
+					DurationMin:     item.DurationMin,
+					FreqPerWeek:     item.FreqPerWeek,
+					TargetRiskLevel: item.TargetRiskLevel,
+					Tags:            item.Tags,
+				}); err != nil {
+					return result, fmt.Errorf("create template %s: %w", item.Slug, err)
+				}
+			}
+			result.Created = append(result.Created, item.Slug)
+			continue
+		}
+
+		changed := diffCatalogTemplate(toCatalogTemplate(current), item)
+		if len(changed) == 0 {
+			result.Unchanged = append(result.Unchanged, item.Slug)
+			continue
+		}
+		if opts.OnConflict != "overwrite" {
+			result.Conflicts = append(result.Conflicts, ImportConflict{
+				Slug:    item.Slug,
+				Fields:  changed,
+				Message: "template already exists with different content",
+			})
+			continue
+		}
+		if !opts.DryRun {
+			if _, err := q.UpdateExerciseTemplateBySlug(ctx, db.UpdateExerciseTemplateBySlugParams{
+				Slug:            item.Slug,
+				Name:            item.Name,
+				Intensity:       item.Intensity,
+				Description:     item.Description,
+				DurationMin:     item.DurationMin,
+				FreqPerWeek:     item.FreqPerWeek,
+				TargetRiskLevel: item.TargetRiskLevel,
+				Tags:            item.Tags,
+			}); err != nil {
+				return result, fmt.Errorf("update template %s: %w", item.Slug, err)
+			}
+		}
+		result.Updated = append(result.Updated, item.Slug)
+	}
+
+	return result, nil
+}
+
+// legacySlugSeparator khớp regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g') của migration add_exercise_template_slug.
+var legacySlugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+
+// isLegacySlug nhận ra slug do migration add_exercise_template_slug backfill cho template tạo trước khi có slug:
+// đúng dạng slugify(name) || '-' || id của migration, không chỉ hậu tố "-<id>" (slug catalog như "squat-12" vẫn hợp lệ).
+func isLegacySlug(t db.ExerciseTemplate) bool {
+	base := strings.Trim(legacySlugSeparator.ReplaceAllString(strings.ToLower(t.Name), "-"), "-")
+	return t.Slug == base+"-"+strconv.FormatInt(t.ID, 10)
+}
+
+// normalizeCatalogTemplate áp dụng cùng quy tắc như CreateTemplate.
+func normalizeCatalogTemplate(t CatalogTemplate) (CatalogTemplate, error) {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Intensity = strings.TrimSpace(t.Intensity)
+	t.Description = strings.TrimSpace(t.Description)
+	t.Slug = utils.Slugify(t.Slug)
+	if t.Slug == "" {
+		t.Slug = utils.Slugify(t.Name)
+	}
+	t.Tags = normalizeTags(t.Tags)
+
+	if t.Name == "" {
+		return t, errors.New("name is required")
+	}
+	if t.Slug == "" {
+		return t, errors.New("cannot derive slug from name")
+	}
+	if t.Intensity == "" {
+		return t, errors.New("intensity is required")
+	}
+	if t.DurationMin <= 0 {
+		return t, errors.New("duration_min must be positive")
+	}
+	if t.FreqPerWeek <= 0 {
+		return t, errors.New("freq_per_week must be positive")
+	}
+	risk, ok := normalizeRiskLevel(t.TargetRiskLevel)
+	if !ok {
+		return t, errors.New("target_risk_level must be low/medium/high/none")
+	}
+	t.TargetRiskLevel = risk
+	return t, nil
+}
+
+func diffCatalogTemplate(current, incoming CatalogTemplate) []string {
+	var fields []string
+	if current.Name != incoming.Name {
+		fields = append(fields, "name")
+	}
+	if current.Intensity != incoming.Intensity {
+		fields = append(fields, "intensity")
+	}
+	if current.Description != incoming.Description {
+		fields = append(fields, "description")
+	}
+	if current.DurationMin != incoming.DurationMin {
+		fields = append(fields, "duration_min")
+	}
+	if current.FreqPerWeek != incoming.FreqPerWeek {
+		fields = append(fields, "freq_per_week")
+	}
+	if current.TargetRiskLevel != incoming.TargetRiskLevel {
+		fields = append(fields, "target_risk_level")
+	}
+	if !slices.Equal(current.Tags, incoming.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+func formatFromContentType(contentType string) string {
+	if strings.Contains(contentType, "yaml") {
+		return "yaml"
+	}
+	return "json"
+}
@@ -1,23 +1,33 @@
 package exercises
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	db "chidinh/db/sqlc"
 	"chidinh/utils"
+	"chidinh/utils/pagination"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Controller gom dependencies cho module exercise templates/recommendations.
 type Controller struct {
+	Pool    *pgxpool.Pool
 	Queries *db.Queries
 }
 
-func NewController(q *db.Queries) *Controller {
-	return &Controller{Queries: q}
+func NewController(pool *pgxpool.Pool, q *db.Queries) *Controller {
+	return &Controller{Pool: pool, Queries: q}
 }
 
 // POST /exercise-templates
@@ -33,22 +43,48 @@ func (h *Controller) CreateTemplate(c *gin.Context) {
 		return
 	}
 
-	risk := strings.ToLower(strings.TrimSpace(req.TargetRiskLevel))
-	if risk != "low" && risk != "medium" && risk != "high" && risk != "none" {
+	risk, ok := normalizeRiskLevel(req.TargetRiskLevel)
+	if !ok {
 		utils.RespondError(c, http.StatusBadRequest, "target_risk_level must be low/medium/high/none")
 		return
 	}
 
+	slug := utils.Slugify(req.Slug)
+	if slug == "" {
+		slug = utils.Slugify(req.Name)
+	}
+	if slug == "" {
+		utils.RespondError(c, http.StatusBadRequest, "cannot derive slug from name")
+		return
+	}
+
+	tags := normalizeTags(req.Tags)
+	unknown, err := h.unknownTags(c, tags)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot load tags")
+		return
+	}
+	if len(unknown) > 0 {
+		utils.RespondError(c, http.StatusBadRequest, "unknown tags: "+strings.Join(unknown, ", "))
+		return
+	}
+
 	template, err := h.Queries.CreateExerciseTemplate(c, db.CreateExerciseTemplateParams{
+		Slug:            slug,
 		Name:            req.Name,
 		Intensity:       req.Intensity,
 		Description:     req.Description,
 		DurationMin:     req.DurationMin,
 		FreqPerWeek:     req.FreqPerWeek,
 		TargetRiskLevel: risk,
-		Tags:            req.Tags,
+		Tags:            tags,
 	})
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			utils.RespondError(c, http.StatusConflict, "template slug already exists")
+			return
+		}
 		utils.RespondError(c, http.StatusInternalServerError, "cannot create template")
 		return
 	}
@@ -57,24 +93,237 @@ func (h *Controller) CreateTemplate(c *gin.Context) {
 }
 
 // GET /exercise-templates
+// Hỗ trợ lọc theo intensity, target_risk_level, tags (any/all), khoảng duration/freq,
+// full-text search (q) trên name/description và phân trang keyset theo id (sort=id|-id, mặc định id).
 func (h *Controller) ListTemplates(c *gin.Context) {
 	if _, ok := utils.UserIDFromContext(c); !ok {
 		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
 		return
 	}
 
-	items, err := h.Queries.ListExerciseTemplates(c)
+	var req ListTemplatesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"id"}, "id")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.SearchExerciseTemplatesDescParams{
+		Intensity:   optionalString(req.Intensity),
+		Query:       optionalString(req.Query),
+		MinDuration: req.MinDuration,
+		MaxDuration: req.MaxDuration,
+		MinFreq:     req.MinFreq,
+		MaxFreq:     req.MaxFreq,
+		CursorID:    pager.CursorID(),
+		Limit:       pager.FetchLimit(),
+	}
+
+	if risk := optionalString(req.TargetRiskLevel); risk != nil {
+		lowered := strings.ToLower(*risk)
+		params.TargetRiskLevel = &lowered
+	}
+
+	if tags := splitTags(req.Tags); len(tags) > 0 {
+		if req.TagsMode == "all" {
+			params.TagsAll = tags
+		} else {
+			params.TagsAny = tags
+		}
+	}
+
+	var items []db.ExerciseTemplate
+	if pager.Desc {
+		items, err = h.Queries.SearchExerciseTemplatesDesc(c, params)
+	} else {
+		items, err = h.Queries.SearchExerciseTemplatesAsc(c, db.SearchExerciseTemplatesAscParams(params))
+	}
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot list templates")
 		return
 	}
-	resp := ListTemplatesResponse{Templates: make([]TemplateResponse, 0, len(items))}
+
+	items, meta := pagination.Trim(items, pager, templateCursor)
+	resp := ListTemplatesResponse{Templates: make([]TemplateResponse, 0, len(items)), Meta: meta}
 	for _, t := range items {
 		resp.Templates = append(resp.Templates, toTemplateResponse(toTemplateDomain(t)))
 	}
 	c.JSON(http.StatusOK, resp)
 }
 
+// POST /exercise-templates/import?format=json|yaml&on_conflict=skip|overwrite&dry_run=true
+// Upsert template theo slug. Tài liệu lỗi validate -> 422 và không ghi gì vào DB.
+func (h *Controller) ImportTemplates(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ImportTemplatesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCatalogSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.RespondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("catalog must not exceed %d bytes", tooLarge.Limit))
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "cannot read request body")
+		return
+	}
+
+	doc, err := DecodeCatalog(format, body)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := ImportCatalog(c, h.Pool, h.Queries, doc, ImportOptions{
+		OnConflict: req.OnConflict,
+		DryRun:     req.DryRun,
+	})
+	if errors.Is(err, ErrInvalidCatalog) {
+		c.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot import templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GET /exercise-templates/export?format=json|yaml
+func (h *Controller) ExportTemplates(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ExportTemplatesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	doc, err := ExportCatalog(c, h.Queries)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot export templates")
+		return
+	}
+
+	data, contentType, err := EncodeCatalog(req.Format, doc)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot encode templates")
+		return
+	}
+
+	filename := fmt.Sprintf("exercise_templates_%s.%s", time.Now().Format("20060102"), req.Format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// GET /exercise-tags
+func (h *Controller) ListTags(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	items, err := h.Queries.ListExerciseTags(c)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list tags")
+		return
+	}
+	resp := ListTagsResponse{Tags: make([]TagResponse, 0, len(items))}
+	for _, t := range items {
+		resp.Tags = append(resp.Tags, toTagResponse(t))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /exercise-tags
+func (h *Controller) CreateTag(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	names := normalizeTags([]string{req.Name})
+	if len(names) == 0 {
+		utils.RespondError(c, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	tag, err := h.Queries.CreateExerciseTag(c, db.CreateExerciseTagParams{
+		Name:        names[0],
+		Description: strings.TrimSpace(req.Description),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			utils.RespondError(c, http.StatusConflict, "tag already exists")
+			return
+		}
+		utils.RespondError(c, http.StatusInternalServerError, "cannot create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTagResponse(tag))
+}
+
+// DELETE /exercise-tags/:name
+// Chỉ cho xóa tag chưa được template nào sử dụng.
+func (h *Controller) DeleteTag(c *gin.Context) {
+	if _, ok := utils.UserIDFromContext(c); !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	names := normalizeTags([]string{c.Param("name")})
+	if len(names) == 0 {
+		utils.RespondError(c, http.StatusBadRequest, "invalid tag name")
+		return
+	}
+
+	inUse, err := h.Queries.CountTemplatesByTag(c, names[0])
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot check tag usage")
+		return
+	}
+	if inUse > 0 {
+		utils.RespondError(c, http.StatusConflict, fmt.Sprintf("tag is used by %d templates", inUse))
+		return
+	}
+
+	if err := h.Queries.DeleteExerciseTag(c, names[0]); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot delete tag")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // GET /patients/:id/recommendations
 func (h *Controller) ListRecommendationsByPatient(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
@@ -104,6 +353,11 @@ func (h *Controller) ListRecommendationsByPatient(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
 		return
 	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	templates, err := h.Queries.ListExerciseTemplates(c)
 	if err != nil {
@@ -112,17 +366,25 @@ func (h *Controller) ListRecommendationsByPatient(c *gin.Context) {
 	}
 	tplByID := indexTemplates(templates)
 
-	items, err := h.Queries.ListExerciseRecommendationsByPatient(c, db.ListExerciseRecommendationsByPatientParams{
-		PatientID: int64(patientID),
-		Limit:     req.Limit,
-		Offset:    req.Offset,
-	})
+	params := db.ListExerciseRecommendationsByPatientDescParams{
+		PatientID:       int64(patientID),
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.ExerciseRecommendation
+	if pager.Desc {
+		items, err = h.Queries.ListExerciseRecommendationsByPatientDesc(c, params)
+	} else {
+		items, err = h.Queries.ListExerciseRecommendationsByPatientAsc(c, db.ListExerciseRecommendationsByPatientAscParams(params))
+	}
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot list recommendations")
 		return
 	}
 
-	resp := ListRecommendationsResponse{Recommendations: make([]RecommendationResponse, 0, len(items))}
+	items, meta := pagination.Trim(items, pager, recommendationCursor)
+	resp := ListRecommendationsResponse{Recommendations: make([]RecommendationResponse, 0, len(items)), Meta: meta}
 	for _, r := range items {
 		rec := toRecommendationDomain(r, tplByID)
 		resp.Recommendations = append(resp.Recommendations, toRecommendationResponse(rec))
@@ -130,3 +392,155 @@ func (h *Controller) ListRecommendationsByPatient(c *gin.Context) {
 
 	c.JSON(http.StatusOK, resp)
 }
+
+// unknownTags trả về các tag không nằm trong bộ từ vựng exercise_tags.
+func (h *Controller) unknownTags(ctx context.Context, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	known, err := h.Queries.ListExerciseTagsByNames(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	knownSet := make(map[string]struct{}, len(known))
+	for _, t := range known {
+		knownSet[t.Name] = struct{}{}
+	}
+	var unknown []string
+	for _, t := range tags {
+		if _, ok := knownSet[t]; !ok {
+			unknown = append(unknown, t)
+		}
+	}
+	return unknown, nil
+}
+
+// POST /patients/:id/exercise-sessions
+// Buổi tập được gắn với kế hoạch tập gần nhất của bệnh nhân (nếu có) để tính mức tuân thủ.
+func (h *Controller) CreateSession(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patient, ok := h.ownedPatient(c, userID)
+	if !ok {
+		return
+	}
+
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	performedOn, err := parseSessionDate(req.PerformedOn, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	latest, err := h.Queries.ListExerciseRecommendationsByPatientDesc(c, db.ListExerciseRecommendationsByPatientDescParams{
+		PatientID:       patient.ID,
+		CursorCreatedAt: pagination.Newest.CursorTime(),
+		CursorID:        pagination.Newest.CursorID(),
+		Limit:           1,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot load recommendations")
+		return
+	}
+	var recommendationID *int64
+	if len(latest) > 0 {
+		recommendationID = &latest[0].ID
+	}
+
+	session, err := h.Queries.CreateExerciseSession(c, db.CreateExerciseSessionParams{
+		PatientID:        patient.ID,
+		RecommendationID: recommendationID,
+		PerformedOn:      performedOn,
+		DurationMin:      req.DurationMin,
+		Notes:            strings.TrimSpace(req.Notes),
+		RecordedBy:       &userID,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot create exercise session")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toSessionResponse(session))
+}
+
+// GET /patients/:id/exercise-sessions
+func (h *Controller) ListSessions(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patient, ok := h.ownedPatient(c, userID)
+	if !ok {
+		return
+	}
+
+	var req ListSessionsParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListExerciseSessionsByPatientDescParams{
+		PatientID:       patient.ID,
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.ExerciseSession
+	if pager.Desc {
+		items, err = h.Queries.ListExerciseSessionsByPatientDesc(c, params)
+	} else {
+		items, err = h.Queries.ListExerciseSessionsByPatientAsc(c, db.ListExerciseSessionsByPatientAscParams(params))
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list exercise sessions")
+		return
+	}
+
+	items, meta := pagination.Trim(items, pager, sessionCursor)
+	resp := ListSessionsResponse{Sessions: make([]SessionResponse, 0, len(items)), Meta: meta}
+	for _, s := range items {
+		resp.Sessions = append(resp.Sessions, toSessionResponse(s))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ownedPatient đọc :id và kiểm tra bệnh nhân thuộc user; đã trả lỗi nếu ok = false.
+func (h *Controller) ownedPatient(c *gin.Context, userID string) (db.Patient, bool) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid patient id")
+		return db.Patient{}, false
+	}
+
+	patient, err := h.Queries.GetPatientByID(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "patient not found")
+		return patient, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return patient, false
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return patient, false
+	}
+	return patient, true
+}
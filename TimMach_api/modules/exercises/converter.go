@@ -2,11 +2,14 @@ package exercises
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	db "chidinh/db/sqlc"
 	"chidinh/modules/predictions"
+	"chidinh/utils/pagination"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -14,6 +17,7 @@ import (
 // Template represents a domain exercise template.
 type Template struct {
 	ID              int64
+	Slug            string
 	Name            string
 	Intensity       string
 	Description     string
@@ -35,6 +39,7 @@ type Recommendation struct {
 func toTemplateDomain(t db.ExerciseTemplate) Template {
 	return Template{
 		ID:              t.ID,
+		Slug:            t.Slug,
 		Name:            t.Name,
 		Intensity:       t.Intensity,
 		Description:     t.Description,
@@ -48,6 +53,7 @@ func toTemplateDomain(t db.ExerciseTemplate) Template {
 func toTemplateResponse(t Template) TemplateResponse {
 	return TemplateResponse{
 		ID:              t.ID,
+		Slug:            t.Slug,
 		Name:            t.Name,
 		Intensity:       t.Intensity,
 		Description:     t.Description,
@@ -58,6 +64,31 @@ func toTemplateResponse(t Template) TemplateResponse {
 	}
 }
 
+func toCatalogTemplate(t db.ExerciseTemplate) CatalogTemplate {
+	tags := t.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return CatalogTemplate{
+		Slug:            t.Slug,
+		Name:            t.Name,
+		Intensity:       t.Intensity,
+		Description:     t.Description,
+		DurationMin:     t.DurationMin,
+		FreqPerWeek:     t.FreqPerWeek,
+		TargetRiskLevel: t.TargetRiskLevel,
+		Tags:            tags,
+	}
+}
+
+func toTagResponse(t db.ExerciseTag) TagResponse {
+	return TagResponse{
+		Name:        t.Name,
+		Description: t.Description,
+		CreatedAt:   safeTime(t.CreatedAt),
+	}
+}
+
 func toRecommendationDomain(r db.ExerciseRecommendation, tplByID map[int64]db.ExerciseTemplate) Recommendation {
 	var stored predictions.RecommendationPlan
 	if len(r.Plan) > 0 {
@@ -123,3 +154,90 @@ func safeTime(t pgtype.Timestamptz) time.Time {
 	}
 	return t.Time
 }
+
+// normalizeRiskLevel chuẩn hóa target_risk_level; chỉ chấp nhận low/medium/high/none.
+func normalizeRiskLevel(input string) (string, bool) {
+	risk := strings.ToLower(strings.TrimSpace(input))
+	switch risk {
+	case "low", "medium", "high", "none":
+		return risk, true
+	default:
+		return "", false
+	}
+}
+
+// normalizeTags đưa tag về dạng chuẩn (lowercase, bỏ khoảng trắng, loại trùng) để khớp với exercise_tags.
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		out = append(out, t)
+	}
+	return out
+}
+
+func splitTags(input string) []string {
+	if strings.TrimSpace(input) == "" {
+		return nil
+	}
+	return normalizeTags(strings.Split(input, ","))
+}
+
+func recommendationCursor(r db.ExerciseRecommendation) pagination.Cursor {
+	return pagination.Cursor{Time: r.CreatedAt.Time, ID: r.ID}
+}
+
+// templateCursor: template không có created_at nên keyset chỉ theo id.
+func templateCursor(t db.ExerciseTemplate) pagination.Cursor {
+	return pagination.Cursor{ID: t.ID}
+}
+
+func optionalString(v string) *string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func toSessionResponse(s db.ExerciseSession) SessionResponse {
+	resp := SessionResponse{
+		ID:          strconv.FormatInt(s.ID, 10),
+		PatientID:   strconv.FormatInt(s.PatientID, 10),
+		PerformedOn: s.PerformedOn.Time.Format("2006-01-02"),
+		DurationMin: s.DurationMin,
+		Notes:       s.Notes,
+		CreatedAt:   safeTime(s.CreatedAt),
+	}
+	if s.RecommendationID != nil {
+		resp.RecommendationID = strconv.FormatInt(*s.RecommendationID, 10)
+	}
+	if s.RecordedBy != nil {
+		resp.RecordedBy = *s.RecordedBy
+	}
+	return resp
+}
+
+func sessionCursor(s db.ExerciseSession) pagination.Cursor {
+	return pagination.Cursor{Time: s.CreatedAt.Time, ID: s.ID}
+}
+
+// parseSessionDate: performed_on không được ở tương lai.
+func parseSessionDate(input string, now time.Time) (pgtype.Date, error) {
+	day, err := time.Parse("2006-01-02", input)
+	if err != nil {
+		return pgtype.Date{}, errors.New("performed_on must be YYYY-MM-DD")
+	}
+	if day.After(now) {
+		return pgtype.Date{}, errors.New("performed_on must not be in the future")
+	}
+	return pgtype.Date{Time: day, Valid: true}, nil
+}
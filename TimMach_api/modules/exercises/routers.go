@@ -2,12 +2,21 @@ package exercises
 
 import "github.com/gin-gonic/gin"
 
-// RegisterExerciseRoutes đăng ký endpoint template và recommendation (cần auth).
+// RegisterExerciseRoutes đăng ký endpoint template, recommendation và buổi tập (cần auth).
 func RegisterExerciseRoutes(r *gin.RouterGroup, h *Controller) {
 	templates := r.Group("/exercise-templates")
 	templates.GET("", h.ListTemplates)
 	templates.POST("", h.CreateTemplate)
+	templates.POST("/import", h.ImportTemplates)
+	templates.GET("/export", h.ExportTemplates)
+
+	tags := r.Group("/exercise-tags")
+	tags.GET("", h.ListTags)
+	tags.POST("", h.CreateTag)
+	tags.DELETE("/:name", h.DeleteTag)
 
 	recs := r.Group("/patients")
 	recs.GET("/:id/recommendations", h.ListRecommendationsByPatient)
+	recs.POST("/:id/exercise-sessions", h.CreateSession)
+	recs.GET("/:id/exercise-sessions", h.ListSessions)
 }
@@ -4,9 +4,11 @@ import (
 	"time"
 
 	"chidinh/modules/predictions"
+	"chidinh/utils/pagination"
 )
 
 type CreateTemplateRequest struct {
+	Slug            string   `json:"slug"` // optional, mặc định sinh từ name
 	Name            string   `json:"name" binding:"required"`
 	Intensity       string   `json:"intensity" binding:"required"`
 	Description     string   `json:"description" binding:"required"`
@@ -18,6 +20,7 @@ type CreateTemplateRequest struct {
 
 type TemplateResponse struct {
 	ID              int64    `json:"id"`
+	Slug            string   `json:"slug"`
 	Name            string   `json:"name"`
 	Intensity       string   `json:"intensity"`
 	Description     string   `json:"description"`
@@ -27,13 +30,43 @@ type TemplateResponse struct {
 	Tags            []string `json:"tags"`
 }
 
+// ListTemplatesParams: sort = id (tiền tố "-" là giảm dần), mặc định id.
+type ListTemplatesParams struct {
+	Intensity       string `form:"intensity"`
+	TargetRiskLevel string `form:"target_risk_level"`
+	Tags            string `form:"tags"`                                          // comma separated
+	TagsMode        string `form:"tags_mode,default=any" binding:"oneof=any all"` // any/all
+	MinDuration     *int32 `form:"min_duration" binding:"omitempty,min=0"`
+	MaxDuration     *int32 `form:"max_duration" binding:"omitempty,min=0"`
+	MinFreq         *int32 `form:"min_freq" binding:"omitempty,min=0"`
+	MaxFreq         *int32 `form:"max_freq" binding:"omitempty,min=0"`
+	Query           string `form:"q"`
+	pagination.Params
+}
+
 type ListTemplatesResponse struct {
 	Templates []TemplateResponse `json:"templates"`
+	pagination.Meta
+}
+
+type CreateTagRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type TagResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ListTagsResponse struct {
+	Tags []TagResponse `json:"tags"`
 }
 
+// ListRecommendationsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
 type ListRecommendationsParams struct {
-	Limit  int32 `form:"limit,default=10"`
-	Offset int32 `form:"offset,default=0"`
+	pagination.Params
 }
 
 type RecommendationResponse struct {
@@ -46,4 +79,88 @@ type RecommendationResponse struct {
 
 type ListRecommendationsResponse struct {
 	Recommendations []RecommendationResponse `json:"recommendations"`
+	pagination.Meta
+}
+
+// CatalogDocument là định dạng import/export template (JSON hoặc YAML).
+type CatalogDocument struct {
+	Version   int               `json:"version" yaml:"version"`
+	Tags      []CatalogTag      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Templates []CatalogTemplate `json:"templates" yaml:"templates"`
+}
+
+type CatalogTag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type CatalogTemplate struct {
+	Slug            string   `json:"slug" yaml:"slug"`
+	Name            string   `json:"name" yaml:"name"`
+	Intensity       string   `json:"intensity" yaml:"intensity"`
+	Description     string   `json:"description" yaml:"description"`
+	DurationMin     int32    `json:"duration_min" yaml:"duration_min"`
+	FreqPerWeek     int32    `json:"freq_per_week" yaml:"freq_per_week"`
+	TargetRiskLevel string   `json:"target_risk_level" yaml:"target_risk_level"`
+	Tags            []string `json:"tags" yaml:"tags"`
+}
+
+type ImportTemplatesParams struct {
+	Format     string `form:"format" binding:"omitempty,oneof=json yaml"`
+	OnConflict string `form:"on_conflict,default=skip" binding:"oneof=skip overwrite"`
+	DryRun     bool   `form:"dry_run"`
+}
+
+type ExportTemplatesParams struct {
+	Format string `form:"format,default=json" binding:"oneof=json yaml"`
+}
+
+// ImportConflict mô tả template đã tồn tại (cùng slug) nhưng nội dung khác và không được ghi đè.
+type ImportConflict struct {
+	Slug    string   `json:"slug"`
+	Fields  []string `json:"fields"`
+	Message string   `json:"message"`
+}
+
+type ImportItemError struct {
+	Index   int    `json:"index"`
+	Slug    string `json:"slug,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	DryRun    bool              `json:"dry_run"`
+	Created   []string          `json:"created"`
+	Updated   []string          `json:"updated"`
+	Unchanged []string          `json:"unchanged"`
+	Conflicts []ImportConflict  `json:"conflicts"`
+	Errors    []ImportItemError `json:"errors"`
+}
+
+// CreateSessionRequest ghi nhận một buổi tập bệnh nhân đã thực hiện.
+type CreateSessionRequest struct {
+	PerformedOn string `json:"performed_on" binding:"required"` // yyyy-mm-dd
+	DurationMin int32  `json:"duration_min" binding:"required,min=1,max=600"`
+	Notes       string `json:"notes" binding:"max=500"`
+}
+
+// ListSessionsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
+type ListSessionsParams struct {
+	pagination.Params
+}
+
+type SessionResponse struct {
+	ID               string    `json:"id"`
+	PatientID        string    `json:"patient_id"`
+	RecommendationID string    `json:"recommendation_id,omitempty"`
+	PerformedOn      string    `json:"performed_on"`
+	DurationMin      int32     `json:"duration_min"`
+	Notes            string    `json:"notes,omitempty"`
+	RecordedBy       string    `json:"recorded_by,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	pagination.Meta
 }
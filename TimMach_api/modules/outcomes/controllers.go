@@ -0,0 +1,185 @@
+package outcomes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// Controller gom dependency cho module outcomes.
+type Controller struct {
+	Queries *db.Queries
+}
+
+func NewController(queries *db.Queries) *Controller {
+	return &Controller{Queries: queries}
+}
+
+// POST /patients/:id/outcomes
+func (h *Controller) CreateOutcome(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patient, ok := h.ownedPatient(c, userID)
+	if !ok {
+		return
+	}
+
+	var req CreateOutcomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	_, dob, err := utils.OpenPatient(patient.Name, patient.Dob)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+		return
+	}
+
+	params, err := toCreateParams(req, patient.ID, dob, userID, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	outcome, err := h.Queries.CreatePatientOutcome(c, params)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot create outcome")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toOutcomeResponse(outcome))
+}
+
+// GET /patients/:id/outcomes
+func (h *Controller) ListOutcomes(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patient, ok := h.ownedPatient(c, userID)
+	if !ok {
+		return
+	}
+
+	var req ListOutcomesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListPatientOutcomesDescParams{
+		PatientID:       patient.ID,
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.PatientOutcome
+	if pager.Desc {
+		items, err = h.Queries.ListPatientOutcomesDesc(c, params)
+	} else {
+		items, err = h.Queries.ListPatientOutcomesAsc(c, db.ListPatientOutcomesAscParams(params))
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list outcomes")
+		return
+	}
+
+	items, meta := pagination.Trim(items, pager, outcomeCursor)
+	resp := ListOutcomesResponse{Outcomes: make([]OutcomeResponse, 0, len(items)), Meta: meta}
+	for _, o := range items {
+		resp.Outcomes = append(resp.Outcomes, toOutcomeResponse(o))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DELETE /outcomes/:id
+func (h *Controller) DeleteOutcome(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	outcomeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid outcome id")
+		return
+	}
+
+	outcome, err := h.Queries.GetPatientOutcomeByID(c, int64(outcomeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "outcome not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch outcome")
+		return
+	}
+
+	// GetPatientByID bỏ qua bệnh nhân đã xoá mềm nên outcome của họ cũng trả 404.
+	patient, err := h.Queries.GetPatientByID(c, outcome.PatientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "outcome not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	if err := h.Queries.DeletePatientOutcome(c, outcome.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot delete outcome")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ownedPatient đọc :id và kiểm tra bệnh nhân thuộc user; đã trả lỗi nếu ok = false.
+func (h *Controller) ownedPatient(c *gin.Context, userID string) (db.Patient, bool) {
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid patient id")
+		return db.Patient{}, false
+	}
+
+	patient, err := h.Queries.GetPatientByID(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "patient not found")
+		return patient, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return patient, false
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return patient, false
+	}
+	return patient, true
+}
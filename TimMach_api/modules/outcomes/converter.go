@@ -0,0 +1,62 @@
+package outcomes
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils/pagination"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func toOutcomeResponse(o db.PatientOutcome) OutcomeResponse {
+	resp := OutcomeResponse{
+		ID:         strconv.FormatInt(o.ID, 10),
+		PatientID:  strconv.FormatInt(o.PatientID, 10),
+		EventType:  o.EventType,
+		Condition:  o.Condition,
+		OccurredOn: o.OccurredOn.Time.Format("2006-01-02"),
+		Source:     o.Source,
+		CreatedAt:  o.CreatedAt.Time,
+	}
+	if o.RecordedBy != nil {
+		resp.RecordedBy = *o.RecordedBy
+	}
+	return resp
+}
+
+func outcomeCursor(o db.PatientOutcome) pagination.Cursor {
+	return pagination.Cursor{Time: o.CreatedAt.Time, ID: o.ID}
+}
+
+// toCreateParams kiểm tra request; occurred_on không được ở tương lai hay trước ngày sinh.
+func toCreateParams(req CreateOutcomeRequest, patientID int64, dob time.Time, userID string, now time.Time) (db.CreatePatientOutcomeParams, error) {
+	params := db.CreatePatientOutcomeParams{
+		PatientID:  patientID,
+		EventType:  req.EventType,
+		Condition:  req.Condition,
+		Source:     req.Source,
+		RecordedBy: &userID,
+	}
+	switch {
+	case req.EventType == EventDiagnosis && req.Condition == "":
+		return params, errors.New("condition is required for diagnosis")
+	case req.EventType == EventNegativeFollowup && req.Condition != "":
+		return params, errors.New("condition must be empty for negative_followup")
+	}
+
+	occurred, err := time.Parse("2006-01-02", req.OccurredOn)
+	if err != nil {
+		return params, errors.New("occurred_on must be YYYY-MM-DD")
+	}
+	if occurred.After(now) {
+		return params, errors.New("occurred_on must not be in the future")
+	}
+	if !dob.IsZero() && occurred.Before(dob) {
+		return params, errors.New("occurred_on must not be before the patient's date of birth")
+	}
+	params.OccurredOn = pgtype.Date{Time: occurred, Valid: true}
+	return params, nil
+}
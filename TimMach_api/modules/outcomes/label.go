@@ -0,0 +1,55 @@
+package outcomes
+
+import (
+	"context"
+	"time"
+
+	db "chidinh/db/sqlc"
+)
+
+// LoadEvents đọc outcome của mọi bệnh nhân chưa xoá, nhóm theo patient_id (đã sắp theo ngày).
+func LoadEvents(ctx context.Context, q *db.Queries) (map[int64][]Event, error) {
+	rows, err := q.ListOutcomeEventsForAnalytics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64][]Event)
+	for _, r := range rows {
+		out[r.PatientID] = append(out[r.PatientID], Event{Type: r.EventType, OccurredOn: r.OccurredOn.Time})
+	}
+	return out, nil
+}
+
+// Label gắn nhãn prediction tạo lúc predictedAt với cửa sổ theo dõi horizonDays ngày:
+//   - 1 nếu có chẩn đoán trong [ngày dự đoán, ngày dự đoán + horizon];
+//   - 0 nếu không có chẩn đoán trong cửa sổ và có lần theo dõi âm tính từ cuối cửa sổ trở đi;
+//   - nil nếu chưa đủ thời gian theo dõi, hoặc bệnh nhân đã được chẩn đoán trước ngày dự đoán
+//     (bệnh có sẵn, không phải kết cục mà model cần dự đoán).
+func Label(events []Event, predictedAt time.Time, horizonDays int) *int {
+	start := time.Date(predictedAt.Year(), predictedAt.Month(), predictedAt.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, horizonDays)
+
+	followedUp := false
+	for _, e := range events {
+		day := time.Date(e.OccurredOn.Year(), e.OccurredOn.Month(), e.OccurredOn.Day(), 0, 0, 0, 0, time.UTC)
+		switch e.Type {
+		case EventDiagnosis:
+			if day.Before(start) {
+				return nil
+			}
+			if !day.After(end) {
+				positive := 1
+				return &positive
+			}
+		case EventNegativeFollowup:
+			if !day.Before(end) {
+				followedUp = true
+			}
+		}
+	}
+	if followedUp {
+		negative := 0
+		return &negative
+	}
+	return nil
+}
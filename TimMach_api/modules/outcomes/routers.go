@@ -0,0 +1,10 @@
+package outcomes
+
+import "github.com/gin-gonic/gin"
+
+// RegisterOutcomeRoutes gắn endpoint ghi nhận kết cục lâm sàng (cần auth).
+func RegisterOutcomeRoutes(r *gin.RouterGroup, h *Controller) {
+	r.POST("/patients/:id/outcomes", h.CreateOutcome)
+	r.GET("/patients/:id/outcomes", h.ListOutcomes)
+	r.DELETE("/outcomes/:id", h.DeleteOutcome)
+}
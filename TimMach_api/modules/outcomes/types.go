@@ -0,0 +1,52 @@
+package outcomes
+
+import (
+	"time"
+
+	"chidinh/utils/pagination"
+)
+
+const (
+	// EventDiagnosis: bệnh nhân được chẩn đoán bệnh tim mạch vào ngày occurred_on.
+	EventDiagnosis = "diagnosis"
+	// EventNegativeFollowup: lần theo dõi xác nhận chưa mắc bệnh tính tới ngày occurred_on.
+	EventNegativeFollowup = "negative_followup"
+
+	// DefaultHorizonDays là cửa sổ theo dõi mặc định khi gắn nhãn prediction.
+	DefaultHorizonDays = 365
+)
+
+// CreateOutcomeRequest: condition bắt buộc với diagnosis, bỏ trống với negative_followup.
+type CreateOutcomeRequest struct {
+	EventType  string `json:"event_type" binding:"required,oneof=diagnosis negative_followup"`
+	Condition  string `json:"condition" binding:"omitempty,oneof=coronary_heart_disease stroke heart_failure peripheral_artery_disease other_cvd"`
+	OccurredOn string `json:"occurred_on" binding:"required"` // yyyy-mm-dd
+	Source     string `json:"source" binding:"required,oneof=clinician ehr registry self_report"`
+}
+
+// ListOutcomesParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
+type ListOutcomesParams struct {
+	pagination.Params
+}
+
+type OutcomeResponse struct {
+	ID         string    `json:"id"`
+	PatientID  string    `json:"patient_id"`
+	EventType  string    `json:"event_type"`
+	Condition  string    `json:"condition,omitempty"`
+	OccurredOn string    `json:"occurred_on"`
+	Source     string    `json:"source"`
+	RecordedBy string    `json:"recorded_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ListOutcomesResponse struct {
+	Outcomes []OutcomeResponse `json:"outcomes"`
+	pagination.Meta
+}
+
+// Event là outcome rút gọn dùng để gắn nhãn prediction.
+type Event struct {
+	Type       string
+	OccurredOn time.Time
+}
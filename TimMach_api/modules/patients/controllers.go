@@ -1,6 +1,7 @@
 package patients
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	db "chidinh/db/sqlc"
 	"chidinh/utils"
+	"chidinh/utils/pagination"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -42,18 +44,27 @@ func (h *Controller) CreatePatient(c *gin.Context) {
 		return
 	}
 
+	sealed, err := utils.SealPatient(req.Name, dobVal)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot encrypt patient")
+		return
+	}
+
 	patient, err := h.Queries.CreatePatient(c, db.CreatePatientParams{
-		UserID: userID,
-		Name:   req.Name,
-		Gender: req.Gender,
-		Dob:    toDBDate(dobVal),
+		UserID:     userID,
+		Name:       sealed.Name,
+		Gender:     req.Gender,
+		Dob:        sealed.Dob,
+		BirthYear:  sealed.BirthYear,
+		NameIndex:  sealed.NameIndex,
+		KeyVersion: sealed.KeyVersion,
 	})
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot create patient")
 		return
 	}
 
-	c.JSON(http.StatusCreated, toPatientResponse(toPatientDomain(patient, nil)))
+	respondPatient(c, http.StatusCreated, patient, nil)
 }
 
 // GET /patients
@@ -78,25 +89,60 @@ func (h *Controller) ListPatients(c *gin.Context) {
 		return
 	}
 
-	items, err := h.Queries.ListPatientsWithLatestPrediction(c, db.ListPatientsWithLatestPredictionParams{
-		UserID:  userID,
-		Limit:   req.Limit,
-		Offset:  req.Offset,
-		Column4: riskFilter,
-	})
+	params, err := toSearchParams(req)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pager, err := pagination.Parse(req.Params, patientSortFields, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.UserID = userID
+	params.Risk = riskFilter
+	params.CursorCreatedAt = pager.CursorTime()
+	params.CursorID = pager.CursorID()
+	params.Limit = pager.FetchLimit()
+
+	items, err := h.listPatients(c, params, pager.Desc)
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot list patients")
 		return
 	}
 
-	resp := ListPatientsResponse{Patients: make([]PatientResponse, 0, len(items))}
+	items, meta := pagination.Trim(items, pager, patientCursor)
+	resp := ListPatientsResponse{Patients: make([]PatientResponse, 0, len(items)), Meta: meta}
 	for _, p := range items {
-		resp.Patients = append(resp.Patients, toPatientResponse(toPatientDomainFromJoined(p)))
+		patient, err := toPatientDomainFromJoined(p)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+			return
+		}
+		resp.Patients = append(resp.Patients, toPatientResponse(patient))
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// listPatients chọn query keyset theo chiều sort; dòng của query tăng dần được đổi sang cùng kiểu với giảm dần.
+func (h *Controller) listPatients(ctx context.Context, params db.ListPatientsWithLatestPredictionDescParams, desc bool) ([]db.ListPatientsWithLatestPredictionDescRow, error) {
+	if desc {
+		return h.Queries.ListPatientsWithLatestPredictionDesc(ctx, params)
+	}
+	rows, err := h.Queries.ListPatientsWithLatestPredictionAsc(ctx, db.ListPatientsWithLatestPredictionAscParams(params))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]db.ListPatientsWithLatestPredictionDescRow, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, db.ListPatientsWithLatestPredictionDescRow(r))
+	}
+	return out, nil
+}
+
 // GET /patients/:id
 func (h *Controller) GetPatient(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
@@ -131,7 +177,7 @@ func (h *Controller) GetPatient(c *gin.Context) {
 		latest = &pred
 	}
 
-	c.JSON(http.StatusOK, toPatientResponse(toPatientDomain(patient, latest)))
+	respondPatient(c, http.StatusOK, patient, latest)
 }
 
 // PUT/PATCH /patients/:id
@@ -168,7 +214,13 @@ func (h *Controller) UpdatePatient(c *gin.Context) {
 		return
 	}
 
-	newName := existing.Name
+	current, err := toPatientDomain(existing, nil)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+		return
+	}
+
+	newName := current.Name
 	if req.Name != nil {
 		newName = *req.Name
 	}
@@ -178,28 +230,38 @@ func (h *Controller) UpdatePatient(c *gin.Context) {
 		newGender = *req.Gender
 	}
 
-	newDob := existing.Dob
+	newDob := current.Dob
 	if req.Dob != nil {
 		dob, err := parseDate(*req.Dob)
 		if err != nil {
 			utils.RespondError(c, http.StatusBadRequest, "dob must be YYYY-MM-DD")
 			return
 		}
-		newDob = toDBDate(dob)
+		newDob = dob
+	}
+
+	// Luôn mã hoá lại bằng key active, nên cập nhật cũng là một lần xoay key cho bản ghi này.
+	sealed, err := utils.SealPatient(newName, newDob)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot encrypt patient")
+		return
 	}
 
 	updated, err := h.Queries.UpdatePatient(c, db.UpdatePatientParams{
-		ID:     int64(patientID),
-		Name:   newName,
-		Gender: newGender,
-		Dob:    newDob,
+		ID:         int64(patientID),
+		Name:       sealed.Name,
+		Gender:     newGender,
+		Dob:        sealed.Dob,
+		BirthYear:  sealed.BirthYear,
+		NameIndex:  sealed.NameIndex,
+		KeyVersion: sealed.KeyVersion,
 	})
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot update patient")
 		return
 	}
 
-	c.JSON(http.StatusOK, toPatientResponse(toPatientDomain(updated, nil)))
+	respondPatient(c, http.StatusOK, updated, nil)
 }
 
 // DELETE /patients/:id
@@ -230,10 +292,66 @@ func (h *Controller) DeletePatient(c *gin.Context) {
 		return
 	}
 
-	if err := h.Queries.DeletePatient(c, int64(patientID)); err != nil {
+	// Xoá mềm: dữ liệu liên quan giữ nguyên để có thể khôi phục, job retention xoá hẳn sau.
+	if err := h.Queries.SoftDeletePatient(c, int64(patientID)); err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot delete patient")
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// POST /patients/:id/restore
+func (h *Controller) RestorePatient(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid patient id")
+		return
+	}
+
+	patient, err := h.Queries.GetDeletedPatientByID(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "deleted patient not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	restored, err := h.Queries.RestorePatient(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "deleted patient not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot restore patient")
+		return
+	}
+
+	var latest *db.Prediction
+	if pred, err := h.Queries.GetLatestPredictionByPatient(c, restored.ID); err == nil {
+		latest = &pred
+	}
+
+	respondPatient(c, http.StatusOK, restored, latest)
+}
+
+func respondPatient(c *gin.Context, status int, p db.Patient, latest *db.Prediction) {
+	patient, err := toPatientDomain(p, latest)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+		return
+	}
+	c.JSON(status, toPatientResponse(patient))
+}
@@ -1,10 +1,14 @@
 package patients
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
 	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
+	"chidinh/utils/pagination"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -27,28 +31,37 @@ type PredictionSummary struct {
 	CreatedAt   time.Time
 }
 
-func toPatientDomain(p db.Patient, latest *db.Prediction) Patient {
+// toPatientDomain giải mã name/dob (utils/fieldcrypt) khi đọc từ DB.
+func toPatientDomain(p db.Patient, latest *db.Prediction) (Patient, error) {
+	name, dob, err := utils.OpenPatient(p.Name, p.Dob)
+	if err != nil {
+		return Patient{}, err
+	}
 	return Patient{
 		ID:               p.ID,
 		UserID:           p.UserID,
-		Name:             p.Name,
+		Name:             name,
 		Gender:           p.Gender,
-		Dob:              safeDate(p.Dob),
+		Dob:              dob,
 		CreatedAt:        safeTime(p.CreatedAt),
 		LatestPrediction: toPredictionSummaryDomain(latest),
-	}
+	}, nil
 }
 
-func toPatientDomainFromJoined(row db.ListPatientsWithLatestPredictionRow) Patient {
+func toPatientDomainFromJoined(row db.ListPatientsWithLatestPredictionDescRow) (Patient, error) {
+	name, dob, err := utils.OpenPatient(row.Name, row.Dob)
+	if err != nil {
+		return Patient{}, err
+	}
 	return Patient{
 		ID:               row.ID,
 		UserID:           row.UserID,
-		Name:             row.Name,
+		Name:             name,
 		Gender:           row.Gender,
-		Dob:              safeDate(row.Dob),
+		Dob:              dob,
 		CreatedAt:        safeTime(row.CreatedAt),
 		LatestPrediction: toPredictionSummaryFromJoined(row),
-	}
+	}, nil
 }
 
 func toPatientResponse(p Patient) PatientResponse {
@@ -74,7 +87,54 @@ func toPredictionSummaryDomain(pred *db.Prediction) *PredictionSummary {
 	}
 }
 
-func toPredictionSummaryFromJoined(row db.ListPatientsWithLatestPredictionRow) *PredictionSummary {
+// Tên đã mã hoá nên không sắp xếp theo name ở DB được.
+var patientSortFields = []string{"created_at"}
+
+func patientCursor(row db.ListPatientsWithLatestPredictionDescRow) pagination.Cursor {
+	return pagination.Cursor{Time: row.CreatedAt.Time, ID: row.ID}
+}
+
+// toSearchParams chuyển bộ lọc tìm kiếm sang params của query; UserID/Risk/phân trang do controller điền.
+func toSearchParams(req ListPatientsParams) (db.ListPatientsWithLatestPredictionDescParams, error) {
+	params := db.ListPatientsWithLatestPredictionDescParams{
+		Gender:         req.Gender,
+		MinAge:         req.MinAge,
+		MaxAge:         req.MaxAge,
+		MinProbability: req.MinProbability,
+		MaxProbability: req.MaxProbability,
+	}
+	// Tên được tìm qua blind index: mỗi từ trong q phải là tiền tố (>= 2 ký tự, không dấu) của một từ trong tên.
+	if tokens := fieldcrypt.QueryTokens(req.Q); len(tokens) > 0 {
+		params.NameTokens = fieldcrypt.Default().BlindIndex(tokens)
+	}
+	if req.MinAge != nil && req.MaxAge != nil && *req.MinAge > *req.MaxAge {
+		return params, errors.New("min_age must not be greater than max_age")
+	}
+	if req.MinProbability != nil && req.MaxProbability != nil && *req.MinProbability > *req.MaxProbability {
+		return params, errors.New("min_probability must not be greater than max_probability")
+	}
+
+	if req.PredictedFrom != "" {
+		from, err := parseDate(req.PredictedFrom)
+		if err != nil {
+			return params, errors.New("invalid predicted_from, expected YYYY-MM-DD")
+		}
+		params.PredictedFrom = pgtype.Timestamptz{Time: from, Valid: true}
+	}
+	if req.PredictedTo != "" {
+		to, err := parseDate(req.PredictedTo)
+		if err != nil {
+			return params, errors.New("invalid predicted_to, expected YYYY-MM-DD")
+		}
+		params.PredictedTo = pgtype.Timestamptz{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+	if params.PredictedFrom.Valid && params.PredictedTo.Valid && !params.PredictedFrom.Time.Before(params.PredictedTo.Time) {
+		return params, errors.New("predicted_from must not be after predicted_to")
+	}
+	return params, nil
+}
+
+func toPredictionSummaryFromJoined(row db.ListPatientsWithLatestPredictionDescRow) *PredictionSummary {
 	if row.LatestProbability == nil && row.LatestRiskLabel == nil {
 		return nil
 	}
@@ -109,10 +169,6 @@ func parseDate(input string) (time.Time, error) {
 	return time.Parse("2006-01-02", input)
 }
 
-func toDBDate(t time.Time) pgtype.Date {
-	return pgtype.Date{Time: t, Valid: true}
-}
-
 func formatDate(t time.Time) string {
 	if t.IsZero() {
 		return ""
@@ -126,10 +182,3 @@ func safeTime(t pgtype.Timestamptz) time.Time {
 	}
 	return t.Time
 }
-
-func safeDate(d pgtype.Date) time.Time {
-	if !d.Valid {
-		return time.Time{}
-	}
-	return d.Time
-}
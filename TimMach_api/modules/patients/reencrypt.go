@@ -0,0 +1,91 @@
+package patients
+
+import (
+	"context"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+)
+
+const reencryptBatchSize = 200
+
+// ReencryptPatients giải mã rồi mã hoá lại (kèm name_index) các bệnh nhân có key_version khác version
+// hoặc chưa có name_index; version = -1 để xử lý mọi bản ghi. Dùng cho cmd/reencrypt.
+func ReencryptPatients(ctx context.Context, q *db.Queries, version, batch int32) (done, skipped int, err error) {
+	return resealPatients(ctx, q, func(afterID int64) ([]db.ListPatientsForReencryptRow, error) {
+		return q.ListPatientsForReencrypt(ctx, db.ListPatientsForReencryptParams{
+			KeyVersion: version,
+			AfterID:    afterID,
+			Limit:      batch,
+		})
+	})
+}
+
+// BackfillNameIndex dựng name_index cho bệnh nhân tạo trước migration mã hoá (name_index rỗng thì tìm theo
+// tên không ra). main.go chạy một lần lúc khởi động; không còn bản ghi nào thiếu index thì chỉ tốn một query.
+func BackfillNameIndex(ctx context.Context, q *db.Queries) {
+	done, skipped, err := resealPatients(ctx, q, func(afterID int64) ([]db.ListPatientsForReencryptRow, error) {
+		rows, err := q.ListPatientsWithoutNameIndex(ctx, db.ListPatientsWithoutNameIndexParams{
+			AfterID: afterID,
+			Limit:   reencryptBatchSize,
+		})
+		out := make([]db.ListPatientsForReencryptRow, 0, len(rows))
+		for _, r := range rows {
+			out = append(out, db.ListPatientsForReencryptRow(r))
+		}
+		return out, err
+	})
+	if err != nil {
+		utils.L().Warnw("backfill patient name_index failed", "error", err, "done", done, "skipped", skipped)
+		return
+	}
+	if done > 0 || skipped > 0 {
+		utils.L().Infow("backfilled patient name_index", "count", done, "skipped", skipped)
+	}
+}
+
+// resealPatients mã hoá lại từng bệnh nhân do list trả về (keyset theo id); bản ghi bị API sửa giữa chừng
+// (ciphertext cũ không còn khớp) được bỏ qua vì API đã ghi bằng key active kèm name_index mới.
+func resealPatients(ctx context.Context, q *db.Queries, list func(afterID int64) ([]db.ListPatientsForReencryptRow, error)) (done, skipped int, err error) {
+	var afterID int64
+	for {
+		rows, err := list(afterID)
+		if err != nil {
+			return done, skipped, err
+		}
+		if len(rows) == 0 {
+			return done, skipped, nil
+		}
+		for _, row := range rows {
+			afterID = row.ID
+			name, dob, err := utils.OpenPatient(row.Name, row.Dob)
+			if err != nil {
+				utils.L().Warnw("cannot decrypt patient", "error", err, "patient_id", row.ID)
+				skipped++
+				continue
+			}
+			sealed, err := utils.SealPatient(name, dob)
+			if err != nil {
+				return done, skipped, err
+			}
+			n, err := q.UpdatePatientEncryption(ctx, db.UpdatePatientEncryptionParams{
+				Name:       sealed.Name,
+				Dob:        sealed.Dob,
+				BirthYear:  sealed.BirthYear,
+				NameIndex:  sealed.NameIndex,
+				KeyVersion: sealed.KeyVersion,
+				ID:         row.ID,
+				OldName:    row.Name,
+				OldDob:     row.Dob,
+			})
+			if err != nil {
+				return done, skipped, err
+			}
+			if n == 0 {
+				skipped++
+				continue
+			}
+			done++
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package patients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const purgeBatchSize = 100
+
+// Purger xoá hẳn bệnh nhân đã xoá mềm quá thời hạn retention, kèm file PDF báo cáo trên disk.
+// Predictions, recommendations, reports... bị xoá theo ON DELETE CASCADE.
+type Purger struct {
+	Queries   *db.Queries
+	Retention time.Duration
+}
+
+func NewPurger(queries *db.Queries, retention time.Duration) *Purger {
+	return &Purger{Queries: queries, Retention: retention}
+}
+
+// Run chạy PurgeExpired mỗi interval cho tới khi ctx bị huỷ.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := p.PurgeExpired(ctx); err != nil {
+			utils.L().Warnw("purge deleted patients failed", "error", err, "purged", n)
+		} else if n > 0 {
+			utils.L().Infow("purged deleted patients", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired xoá các bệnh nhân có deleted_at trước (now - Retention), trả về số bệnh nhân đã xoá.
+// Bệnh nhân xoá lỗi (vd. không xoá được file) được log rồi bỏ qua để không chặn những người còn lại;
+// lượt sau sẽ thử lại, và lỗi tổng hợp được trả về để Run cảnh báo.
+func (p *Purger) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := pgtype.Timestamptz{Time: time.Now().Add(-p.Retention), Valid: true}
+	purged, failed := 0, 0
+	var afterID int64
+	for {
+		ids, err := p.Queries.ListPatientsForPurge(ctx, db.ListPatientsForPurgeParams{
+			DeletedAt: cutoff,
+			AfterID:   afterID,
+			Limit:     purgeBatchSize,
+		})
+		if err != nil {
+			return purged, err
+		}
+		for _, id := range ids {
+			afterID = id
+			if err := p.purgePatient(ctx, id); err != nil {
+				if ctx.Err() != nil {
+					return purged, ctx.Err()
+				}
+				utils.L().Warnw("purge deleted patient failed", "error", err, "patient_id", id)
+				failed++
+				continue
+			}
+			purged++
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	if failed > 0 {
+		return purged, fmt.Errorf("%d deleted patients could not be purged", failed)
+	}
+	return purged, nil
+}
+
+// purgePatient xoá file báo cáo và archive export trước rồi mới xoá row: nếu lỗi giữa chừng
+// thì lần chạy sau vẫn thấy bệnh nhân và thử lại, không để lại file mồ côi.
+func (p *Purger) purgePatient(ctx context.Context, patientID int64) error {
+	files, err := p.Queries.ListReportFilesByPatient(ctx, patientID)
+	if err != nil {
+		return err
+	}
+	archives, err := p.Queries.ListDataRequestArchivesByPatient(ctx, patientID)
+	if err != nil {
+		return err
+	}
+	for _, path := range append(files, archives...) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := p.Queries.PurgePatient(ctx, patientID); err != nil {
+		return err
+	}
+	return p.Queries.ClearDataRequestArchivesByPatient(ctx, patientID)
+}
@@ -12,4 +12,5 @@ func RegisterPatientRoutes(r *gin.RouterGroup, h *Controller) {
 	group.PUT("/:id", h.UpdatePatient)
 	group.PATCH("/:id", h.UpdatePatient)
 	group.DELETE("/:id", h.DeletePatient)
+	group.POST("/:id/restore", h.RestorePatient)
 }
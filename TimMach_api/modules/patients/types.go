@@ -1,6 +1,10 @@
 package patients
 
-import "time"
+import (
+	"time"
+
+	"chidinh/utils/pagination"
+)
 
 type CreatePatientRequest struct {
 	Name   string `json:"name" binding:"required"`
@@ -32,9 +36,21 @@ type PatientResponse struct {
 
 type ListPatientsResponse struct {
 	Patients []PatientResponse `json:"patients"`
+	pagination.Meta
 }
+
+// ListPatientsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at; sort=name không còn
+// hỗ trợ (400) vì tên đã mã hoá. Các bộ lọc kết hợp với nhau (AND); predicted_*, *_probability và risk
+// xét theo prediction mới nhất.
 type ListPatientsParams struct {
-	Limit  int32  `form:"limit,default=10"`
-	Offset int32  `form:"offset,default=0"`
-	Risk   string `form:"risk"`
+	pagination.Params
+	Risk           string   `form:"risk"`
+	Q              string   `form:"q"` // tiền tố các từ trong tên, không phân biệt hoa thường/dấu
+	Gender         *int16   `form:"gender" binding:"omitempty,oneof=0 1 2"`
+	MinAge         *int32   `form:"min_age" binding:"omitempty,min=0,max=150"` // tuổi = năm hiện tại - năm sinh
+	MaxAge         *int32   `form:"max_age" binding:"omitempty,min=0,max=150"`
+	PredictedFrom  string   `form:"predicted_from"` // yyyy-mm-dd
+	PredictedTo    string   `form:"predicted_to"`   // yyyy-mm-dd, tính cả ngày này
+	MinProbability *float64 `form:"min_probability" binding:"omitempty,min=0,max=1"`
+	MaxProbability *float64 `form:"max_probability" binding:"omitempty,min=0,max=1"`
 }
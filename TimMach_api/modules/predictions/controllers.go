@@ -5,28 +5,51 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	db "chidinh/db/sqlc"
 	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
 	"chidinh/utils/httpclient"
+	"chidinh/utils/mlscorer"
+	"chidinh/utils/pagination"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/sync/errgroup"
 )
 
+// Chế độ chấm điểm (config ML_SCORER_MODE).
+const (
+	ScorerRemote   = "remote"   // chỉ gọi FastAPI
+	ScorerFallback = "fallback" // gọi FastAPI, lỗi thì dùng scorer Go in-process
+	ScorerLocal    = "local"    // chỉ dùng scorer Go in-process
+)
+
+var errMLNotConfigured = errors.New("ml client is not configured")
+
 // Controller gom dependency cho module predictions.
 type Controller struct {
-	Queries *db.Queries
-	MLHTTP  *resty.Client
+	Queries    *db.Queries
+	MLHTTP     *resty.Client
+	Scorer     *mlscorer.Model
+	ScorerMode string
+	Shadow     *Shadow
 }
 
-// NewController khởi tạo controller với DB queries và HTTP client gọi ML.
-func NewController(queries *db.Queries, mlHTTP *resty.Client) *Controller {
-	return &Controller{Queries: queries, MLHTTP: mlHTTP}
+// NewController khởi tạo controller với DB queries, HTTP client gọi ML, scorer in-process
+// và shadow routing (hai tham số cuối có thể nil).
+func NewController(queries *db.Queries, mlHTTP *resty.Client, scorer *mlscorer.Model, scorerMode string, shadow *Shadow) *Controller {
+	if scorerMode == "" {
+		scorerMode = ScorerRemote
+	}
+	return &Controller{Queries: queries, MLHTTP: mlHTTP, Scorer: scorer, ScorerMode: scorerMode, Shadow: shadow}
 }
 
 // POST /patients/:id/predict
@@ -61,38 +84,69 @@ func (h *Controller) CreatePrediction(c *gin.Context) {
 	var req CreatePredictionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.L().Warnf("create prediction: bind error: %v", err)
-		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		respondValidation(c, http.StatusBadRequest, "invalid request body", bindingIssues(err, req))
 		return
 	}
 
-	mlPayload := toMLRequest(req)
+	_, dob, err := utils.OpenPatient(patient.Name, patient.Dob)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot decrypt patient")
+		return
+	}
 
-	if h.MLHTTP == nil {
-		utils.RespondError(c, http.StatusInternalServerError, "ml client is not configured")
+	ageYears, gender, issues, warnings := resolveDemographics(patient, dob, req, time.Now())
+	mlPayload := toMLRequest(req, ageYears, gender)
+	if len(issues) == 0 {
+		var featureWarnings []FieldIssue
+		issues, featureWarnings = validateFeatures(mlPayload)
+		warnings = append(warnings, featureWarnings...)
+	}
+	if len(issues) > 0 {
+		respondValidation(c, http.StatusUnprocessableEntity, "invalid prediction input", issues)
 		return
 	}
 
-	var mlResp MLResponse
-	if _, err := httpclient.PostJSON(c, h.MLHTTP, "/predict", mlPayload, &mlResp); err != nil {
+	mlResp, err := h.callML(c, mlPayload, true)
+	if errors.Is(err, errMLNotConfigured) {
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err != nil {
 		utils.RespondError(c, http.StatusBadGateway, fmt.Sprintf("ml service error: %v", err))
 		return
 	}
 
-	rawFeatures := encodeStoredFeatures(mlPayload)
-	factorsJSON, _ := json.Marshal(mlResp.Factors)
+	explanation := buildExplanation(mlResp)
+	factors := applyFactorContributions(mlResp.Factors, explanation)
+
+	keyring := fieldcrypt.Default()
+	rawFeatures, err := keyring.SealJSON(encodeStoredFeatures(mlPayload))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot encrypt prediction input")
+		return
+	}
+	factorsJSON, _ := json.Marshal(factors)
 
 	pred, err := h.Queries.CreatePrediction(c, db.CreatePredictionParams{
-		PatientID:   int64(patientID),
-		Probability: mlResp.Probability,
-		RiskLabel:   mlResp.RiskLevel,
-		RawFeatures: rawFeatures,
-		Factors:     factorsJSON,
+		PatientID:          int64(patientID),
+		Probability:        mlResp.Probability,
+		RiskLabel:          mlResp.RiskLevel,
+		RawFeatures:        rawFeatures,
+		Factors:            factorsJSON,
+		Explanation:        encodeExplanation(explanation),
+		ModelName:          mlResp.ModelName,
+		ModelVersion:       mlResp.ModelVersion,
+		FeatureSchemaHash:  mlResp.FeatureSchemaHash,
+		Warnings:           encodeWarnings(warnings),
+		FeaturesKeyVersion: keyring.ActiveVersion(),
 	})
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot save prediction")
 		return
 	}
 
+	h.recordShadow(pred.ID, mlPayload)
+
 	plan, planBlob, err := buildRecommendation(c, h.Queries, mlResp.RiskLevel)
 	if err != nil {
 		utils.L().Warnf("create recommendation error: %v", err)
@@ -151,17 +205,31 @@ func (h *Controller) ListPredictions(c *gin.Context) {
 		return
 	}
 
-	items, err := h.Queries.ListPredictionsByPatient(c, db.ListPredictionsByPatientParams{
-		PatientID: int64(patientID),
-		Limit:     req.Limit,
-		Offset:    req.Offset,
-	})
+	pager, err := pagination.Parse(req.Params, createdAtSort, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListPredictionsByPatientDescParams{
+		PatientID:       int64(patientID),
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.Prediction
+	if pager.Desc {
+		items, err = h.Queries.ListPredictionsByPatientDesc(c, params)
+	} else {
+		items, err = h.Queries.ListPredictionsByPatientAsc(c, db.ListPredictionsByPatientAscParams(params))
+	}
 	if err != nil {
 		utils.RespondError(c, http.StatusInternalServerError, "cannot list predictions")
 		return
 	}
 
-	resp := ListPredictionsResponse{Predictions: make([]PredictionResponse, 0, len(items))}
+	items, meta := pagination.Trim(items, pager, predictionCursor)
+	resp := ListPredictionsResponse{Predictions: make([]PredictionResponse, 0, len(items)), Meta: meta}
 	for _, p := range items {
 		resp.Predictions = append(resp.Predictions, toPredictionResponse(toPredictionDomain(p)))
 	}
@@ -169,6 +237,425 @@ func (h *Controller) ListPredictions(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// POST /patients/:id/simulate
+// Lấy features của prediction gần nhất, áp từng kịch bản thay đổi rồi gọi ML.
+// Không lưu bản ghi predictions nào.
+func (h *Controller) SimulatePrediction(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	patientID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid patient id")
+		return
+	}
+
+	patient, err := h.Queries.GetPatientByID(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "patient not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	var req SimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if h.MLHTTP == nil && h.Scorer == nil {
+		utils.RespondError(c, http.StatusInternalServerError, errMLNotConfigured.Error())
+		return
+	}
+
+	latest, err := h.Queries.GetLatestPredictionByPatient(c, int64(patientID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusUnprocessableEntity, "patient has no prediction to simulate from")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch latest prediction")
+		return
+	}
+
+	baseFeatures, err := DecodeStoredFeatures(latest.RawFeatures)
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, "stored features cannot be decoded")
+		return
+	}
+
+	scenarioFeatures := make([]MLRequest, len(req.Scenarios))
+	for i, sc := range req.Scenarios {
+		scenarioFeatures[i] = applyChanges(baseFeatures, sc.Changes)
+		if issues, _ := validateFeatures(scenarioFeatures[i]); len(issues) > 0 {
+			respondValidation(c, http.StatusUnprocessableEntity, fmt.Sprintf("scenario %q produces invalid features", sc.Name), issues)
+			return
+		}
+	}
+
+	var baseline MLResponse
+	results := make([]MLResponse, len(req.Scenarios))
+
+	g, ctx := errgroup.WithContext(c)
+	g.Go(func() error {
+		var err error
+		baseline, err = h.callML(ctx, baseFeatures, false)
+		return err
+	})
+	for i := range scenarioFeatures {
+		g.Go(func() error {
+			var err error
+			results[i], err = h.callML(ctx, scenarioFeatures[i], false)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		utils.RespondError(c, http.StatusBadGateway, fmt.Sprintf("ml service error: %v", err))
+		return
+	}
+
+	resp := SimulateResponse{
+		PatientID: strconv.Itoa(patientID),
+		Baseline: SimulationBaseline{
+			PredictionID: strconv.FormatInt(latest.ID, 10),
+			MeasuredAt:   safeTime(latest.CreatedAt),
+			Features:     baseFeatures,
+			Probability:  baseline.Probability,
+			RiskLevel:    baseline.RiskLevel,
+		},
+		Scenarios: make([]SimulationResult, 0, len(req.Scenarios)),
+	}
+	for i, sc := range req.Scenarios {
+		resp.Scenarios = append(resp.Scenarios, SimulationResult{
+			Name:             sc.Name,
+			Changes:          sc.Changes,
+			Features:         scenarioFeatures[i],
+			Probability:      results[i].Probability,
+			RiskLevel:        results[i].RiskLevel,
+			ProbabilityDelta: math.Round((results[i].Probability-baseline.Probability)*10000) / 10000,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// POST /predictions/rescore
+// Chấm lại các prediction cũ của user bằng model ML hiện tại và lưu vào prediction_rescores để so sánh.
+// Prediction đã được tạo (hoặc đã chấm lại) bởi đúng model/version hiện tại thì bỏ qua.
+// Model và từng lần chấm đi qua modelInfo/callML nên theo ML_SCORER_MODE như create/simulate.
+func (h *Controller) RescorePredictions(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req RescoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	model, err := h.modelInfo(c)
+	if errors.Is(err, errMLNotConfigured) {
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusBadGateway, fmt.Sprintf("ml service error: %v", err))
+		return
+	}
+
+	items, err := h.Queries.ListPredictionsForRescore(c, db.ListPredictionsForRescoreParams{
+		UserID:       userID,
+		PatientID:    req.PatientID,
+		ModelName:    model.ModelName,
+		ModelVersion: model.ModelVersion,
+		Limit:        req.Limit,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list predictions")
+		return
+	}
+
+	resp := RescoreResponse{
+		ModelName:    model.ModelName,
+		ModelVersion: model.ModelVersion,
+		Failed:       []RescoreFailure{},
+	}
+	for _, p := range items {
+		predID := strconv.FormatInt(p.ID, 10)
+		features, err := DecodeStoredFeatures(p.RawFeatures)
+		if err != nil {
+			resp.Failed = append(resp.Failed, RescoreFailure{PredictionID: predID, Error: "stored features cannot be decoded"})
+			continue
+		}
+
+		mlResp, err := h.callML(c, features, false)
+		if err != nil {
+			resp.Failed = append(resp.Failed, RescoreFailure{PredictionID: predID, Error: fmt.Sprintf("ml service error: %v", err)})
+			continue
+		}
+
+		_, err = h.Queries.UpsertPredictionRescore(c, db.UpsertPredictionRescoreParams{
+			PredictionID:      p.ID,
+			ModelName:         mlResp.ModelName,
+			ModelVersion:      mlResp.ModelVersion,
+			FeatureSchemaHash: mlResp.FeatureSchemaHash,
+			Probability:       mlResp.Probability,
+			RiskLabel:         mlResp.RiskLevel,
+		})
+		if err != nil {
+			resp.Failed = append(resp.Failed, RescoreFailure{PredictionID: predID, Error: "cannot save rescore"})
+			continue
+		}
+		resp.Rescored++
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /predictions/:id/rescores
+// So sánh prediction gốc với các lần chấm lại bằng model khác.
+func (h *Controller) ListRescores(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	predictionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid prediction id")
+		return
+	}
+
+	pred, err := h.Queries.GetPredictionByID(c, int64(predictionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "prediction not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch prediction")
+		return
+	}
+
+	patient, err := h.Queries.GetPatientByID(c, pred.PatientID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	rescores, err := h.Queries.ListRescoresByPrediction(c, pred.ID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list rescores")
+		return
+	}
+
+	resp := ListRescoresResponse{
+		Prediction: toPredictionResponse(toPredictionDomain(pred)),
+		Rescores:   make([]RescoreItem, 0, len(rescores)),
+	}
+	for _, r := range rescores {
+		resp.Rescores = append(resp.Rescores, toRescoreItem(r, pred.Probability))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /predictions/shadow/compare?from=YYYY-MM-DD&to=YYYY-MM-DD&model_version=
+// So sánh model chính với model shadow/canary trong khoảng thời gian (mặc định 7 ngày gần nhất):
+// tỉ lệ đồng thuận risk label, chênh lệch probability và ma trận nhầm lẫn risk label.
+func (h *Controller) CompareShadow(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ShadowComparisonParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query params")
+		return
+	}
+	from, to, err := parseWindow(req.From, req.To, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summaryParams := db.GetShadowComparisonSummaryParams{
+		UserID:       userID,
+		FromTime:     pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:       pgtype.Timestamptz{Time: to, Valid: true},
+		ModelVersion: optionalString(req.ModelVersion),
+	}
+
+	var summary db.GetShadowComparisonSummaryRow
+	var confusion []db.ListShadowConfusionRow
+	g, ctx := errgroup.WithContext(c)
+	g.Go(func() error {
+		var err error
+		summary, err = h.Queries.GetShadowComparisonSummary(ctx, summaryParams)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		confusion, err = h.Queries.ListShadowConfusion(ctx, db.ListShadowConfusionParams(summaryParams))
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot query shadow predictions")
+		return
+	}
+
+	resp := toShadowComparisonResponse(summary, confusion)
+	resp.From = from
+	resp.To = to
+	resp.ModelVersion = req.ModelVersion
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /predictions/:id/explanation
+// Trả về đóng góp theo từng feature (đã chuẩn hóa + xếp hạng) của một prediction.
+func (h *Controller) GetExplanation(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	predictionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid prediction id")
+		return
+	}
+
+	pred, err := h.Queries.GetPredictionByID(c, int64(predictionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "prediction not found")
+		return
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch prediction")
+		return
+	}
+
+	patient, err := h.Queries.GetPatientByID(c, pred.PatientID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return
+	}
+
+	domain := toPredictionDomain(pred)
+	if domain.Explanation == nil {
+		utils.RespondError(c, http.StatusNotFound, "explanation not available for this prediction")
+		return
+	}
+
+	c.JSON(http.StatusOK, toExplanationResponse(domain))
+}
+
+// callML chấm điểm theo ScorerMode. Ở chế độ fallback, chỉ lỗi phía FastAPI (down, timeout, 5xx)
+// mới chuyển sang scorer in-process; 4xx (input bị từ chối) và request bị client huỷ trả lỗi như cũ.
+func (h *Controller) callML(ctx context.Context, payload MLRequest, explain bool) (MLResponse, error) {
+	if h.useLocalScorer() {
+		return h.scoreLocal(payload)
+	}
+	if h.MLHTTP == nil {
+		return MLResponse{}, errMLNotConfigured
+	}
+
+	path := "/predict"
+	if explain {
+		path += "?explain=true"
+	}
+	var resp MLResponse
+	httpResp, err := httpclient.PostJSON(ctx, h.MLHTTP, path, payload, &resp)
+	if err == nil || h.ScorerMode != ScorerFallback || h.Scorer == nil || !mlUnavailable(ctx, httpResp) {
+		return resp, err
+	}
+
+	utils.L().Warnw("ml service unavailable, using in-process scorer", "error", err)
+	return h.scoreLocal(payload)
+}
+
+// modelInfo trả về model sẽ chấm điểm, chọn nguồn theo cùng quy tắc với callML
+// (FastAPI GET /model, hoặc model in-process ở local / khi FastAPI không sẵn sàng ở fallback).
+func (h *Controller) modelInfo(ctx context.Context) (MLModelInfo, error) {
+	if h.useLocalScorer() {
+		return h.localModelInfo()
+	}
+	if h.MLHTTP == nil {
+		return MLModelInfo{}, errMLNotConfigured
+	}
+
+	var info MLModelInfo
+	httpResp, err := httpclient.GetJSON(ctx, h.MLHTTP, "/model", &info)
+	if err == nil || h.ScorerMode != ScorerFallback || h.Scorer == nil || !mlUnavailable(ctx, httpResp) {
+		return info, err
+	}
+
+	utils.L().Warnw("ml service unavailable, using in-process model info", "error", err)
+	return h.localModelInfo()
+}
+
+// useLocalScorer: chế độ local, hoặc chỉ có scorer in-process mà không có FastAPI.
+func (h *Controller) useLocalScorer() bool {
+	return h.ScorerMode == ScorerLocal || (h.MLHTTP == nil && h.Scorer != nil)
+}
+
+// mlUnavailable cho biết lỗi gọi FastAPI là do service (lỗi kết nối, timeout của HTTP client, 5xx).
+// ctx đã huỷ (client ngắt kết nối) thì không fallback.
+func mlUnavailable(ctx context.Context, resp *resty.Response) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil || resp.RawResponse == nil {
+		return true
+	}
+	return resp.StatusCode() >= http.StatusInternalServerError
+}
+
+func (h *Controller) localModelInfo() (MLModelInfo, error) {
+	if h.Scorer == nil {
+		return MLModelInfo{}, errMLNotConfigured
+	}
+	return toModelInfo(h.Scorer), nil
+}
+
+func (h *Controller) scoreLocal(payload MLRequest) (MLResponse, error) {
+	if h.Scorer == nil {
+		return MLResponse{}, errMLNotConfigured
+	}
+	res, err := h.Scorer.Predict(toScorerInput(payload))
+	if err != nil {
+		return MLResponse{}, err
+	}
+	return fromScorerResult(res), nil
+}
+
 // buildRecommendation selects up to 3 templates matching risk level (no hardcoded defaults).
 // Returns both response plan and a compact blob (summary + template IDs) to persist.
 func buildRecommendation(ctx context.Context, q *db.Queries, risk string) (RecommendationPlan, []byte, error) {
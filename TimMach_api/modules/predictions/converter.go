@@ -2,10 +2,18 @@ package predictions
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/fieldcrypt"
+	"chidinh/utils/mlscorer"
+	"chidinh/utils/pagination"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -18,10 +26,20 @@ type Prediction struct {
 	RiskLabel   string
 	Factors     []RiskFactor
 	RawFeatures []byte
+	Explanation *Explanation
+	Warnings    []FieldIssue
+	Model       ModelVersion
 	CreatedAt   time.Time
 }
 
-func toMLRequest(req CreatePredictionRequest) MLRequest {
+// ModelVersion định danh model đã tạo ra prediction (rỗng với prediction trước khi có tracking).
+type ModelVersion struct {
+	Name              string
+	Version           string
+	FeatureSchemaHash string
+}
+
+func toMLRequest(req CreatePredictionRequest, ageYears float64, gender int) MLRequest {
 	// Chuyển payload client sang payload gửi cho ML (thay nil bằng 0).
 	toInt := func(v *int) int {
 		if v == nil {
@@ -30,8 +48,8 @@ func toMLRequest(req CreatePredictionRequest) MLRequest {
 		return *v
 	}
 	return MLRequest{
-		AgeYears:    req.AgeYears,
-		Gender:      req.Gender,
+		AgeYears:    ageYears,
+		Gender:      gender,
 		Height:      req.Height,
 		Weight:      req.Weight,
 		APHi:        req.APHi,
@@ -44,6 +62,104 @@ func toMLRequest(req CreatePredictionRequest) MLRequest {
 	}
 }
 
+// resolveDemographics lấy tuổi tại thời điểm đo (từ dob đã giải mã) và giới tính (đổi sang mã ML) từ hồ sơ bệnh nhân.
+// Giá trị client chỉ dùng khi hồ sơ thiếu; nếu lệch với hồ sơ thì bị bỏ qua và trả về thành cảnh báo.
+func resolveDemographics(p db.Patient, dob time.Time, req CreatePredictionRequest, now time.Time) (ageYears float64, gender int, errs, warns []FieldIssue) {
+	measuredAt := now
+	if req.MeasuredAt != nil {
+		measuredAt = *req.MeasuredAt
+		if measuredAt.After(now.Add(24 * time.Hour)) {
+			errs = append(errs, FieldIssue{Field: "measured_at", Code: issueInvalid, Message: "measured_at không được ở tương lai"})
+		}
+	}
+
+	switch {
+	case !dob.IsZero() && measuredAt.Before(dob):
+		errs = append(errs, FieldIssue{Field: "measured_at", Code: issueInconsistent, Message: "measured_at trước ngày sinh của bệnh nhân"})
+	case !dob.IsZero():
+		ageYears = utils.AgeYearsAt(dob, measuredAt)
+		if req.AgeYears != nil && math.Abs(*req.AgeYears-ageYears) >= 1 {
+			warns = append(warns, FieldIssue{
+				Field:   "age_years",
+				Code:    issueOverrideIgnored,
+				Message: fmt.Sprintf("age_years gửi lên (%g) khác tuổi tính từ ngày sinh (%.2f), dùng giá trị từ hồ sơ", *req.AgeYears, ageYears),
+			})
+		}
+	case req.AgeYears != nil:
+		ageYears = *req.AgeYears
+	default:
+		errs = append(errs, FieldIssue{Field: "age_years", Code: issueRequired, Message: "hồ sơ chưa có ngày sinh, cần gửi age_years"})
+	}
+
+	if g, ok := utils.GenderToML(p.Gender); ok {
+		gender = g
+		if req.Gender != nil && *req.Gender != g {
+			warns = append(warns, FieldIssue{
+				Field:   "gender",
+				Code:    issueOverrideIgnored,
+				Message: fmt.Sprintf("gender gửi lên (%d) khác hồ sơ bệnh nhân (%s), dùng giá trị từ hồ sơ", *req.Gender, utils.GenderLabel(p.Gender)),
+			})
+		}
+	} else if req.Gender != nil {
+		gender = *req.Gender
+	} else {
+		errs = append(errs, FieldIssue{Field: "gender", Code: issueRequired, Message: "hồ sơ không ghi nam/nữ, cần gửi gender (1 = nữ, 2 = nam)"})
+	}
+
+	return ageYears, gender, errs, warns
+}
+
+func toScorerInput(req MLRequest) mlscorer.Input {
+	return mlscorer.Input{
+		AgeYears:    req.AgeYears,
+		Gender:      req.Gender,
+		Height:      req.Height,
+		Weight:      req.Weight,
+		APHi:        req.APHi,
+		APLo:        req.APLo,
+		Cholesterol: req.Cholesterol,
+		Gluc:        req.Gluc,
+		Smoke:       req.Smoke,
+		Alco:        req.Alco,
+		Active:      req.Active,
+	}
+}
+
+// toModelInfo mô tả model in-process giống output GET /model của FastAPI.
+func toModelInfo(m *mlscorer.Model) MLModelInfo {
+	return MLModelInfo{
+		ModelName:         m.ModelName,
+		ModelVersion:      m.ModelVersion,
+		FeatureSchemaHash: m.FeatureSchemaHash,
+		FeatureColumns:    m.InputFeatures,
+	}
+}
+
+func fromScorerResult(res mlscorer.Result) MLResponse {
+	out := MLResponse{
+		Probability:       res.Probability,
+		Label:             res.Label,
+		RiskLevel:         res.RiskLevel,
+		Factors:           make([]RiskFactor, 0, len(res.Factors)),
+		BaseValue:         &res.BaseValue,
+		Contributions:     make([]FeatureContribution, 0, len(res.Contributions)),
+		ModelName:         res.ModelName,
+		ModelVersion:      res.ModelVersion,
+		FeatureSchemaHash: res.FeatureSchemaHash,
+	}
+	for _, f := range res.Factors {
+		out.Factors = append(out.Factors, RiskFactor{Field: f.Field, Status: f.Status, Message: f.Message})
+	}
+	for _, c := range res.Contributions {
+		out.Contributions = append(out.Contributions, FeatureContribution{
+			Feature:      c.Feature,
+			Value:        c.Value,
+			Contribution: c.Contribution,
+		})
+	}
+	return out
+}
+
 func toPredictionDomain(p db.Prediction) Prediction {
 	factors := decodeDBFactors(p.Factors)
 	return Prediction{
@@ -52,23 +168,142 @@ func toPredictionDomain(p db.Prediction) Prediction {
 		Probability: p.Probability,
 		RiskLabel:   p.RiskLabel,
 		Factors:     factors,
-		RawFeatures: p.RawFeatures,
-		CreatedAt:   safeTime(p.CreatedAt),
+		RawFeatures: utils.OpenFeatures(p.RawFeatures),
+		Explanation: DecodeExplanation(p.Explanation),
+		Warnings:    decodeWarnings(p.Warnings),
+		Model: ModelVersion{
+			Name:              p.ModelName,
+			Version:           p.ModelVersion,
+			FeatureSchemaHash: p.FeatureSchemaHash,
+		},
+		CreatedAt: safeTime(p.CreatedAt),
 	}
 }
 
+var createdAtSort = []string{"created_at"}
+
+func predictionCursor(p db.Prediction) pagination.Cursor {
+	return pagination.Cursor{Time: p.CreatedAt.Time, ID: p.ID}
+}
+
 func toPredictionResponse(p Prediction) PredictionResponse {
 	// raw_features được giữ nguyên để FE có thể xem input gốc.
 	rawJSON := json.RawMessage(p.RawFeatures)
 	return PredictionResponse{
-		ID:          strconv.FormatInt(p.ID, 10),
-		PatientID:   strconv.FormatInt(p.PatientID, 10),
-		Probability: p.Probability,
-		RiskLabel:   p.RiskLabel,
-		RawFeatures: rawJSON,
-		Factors:     p.Factors,
-		CreatedAt:   p.CreatedAt,
+		ID:                strconv.FormatInt(p.ID, 10),
+		PatientID:         strconv.FormatInt(p.PatientID, 10),
+		Probability:       p.Probability,
+		RiskLabel:         p.RiskLabel,
+		RawFeatures:       rawJSON,
+		Factors:           p.Factors,
+		Warnings:          p.Warnings,
+		ModelName:         p.Model.Name,
+		ModelVersion:      p.Model.Version,
+		FeatureSchemaHash: p.Model.FeatureSchemaHash,
+		CreatedAt:         p.CreatedAt,
+	}
+}
+
+func toExplanationResponse(p Prediction) ExplanationResponse {
+	resp := ExplanationResponse{
+		PredictionID: strconv.FormatInt(p.ID, 10),
+		PatientID:    strconv.FormatInt(p.PatientID, 10),
+		Probability:  p.Probability,
+		RiskLabel:    p.RiskLabel,
+		Features:     []FeatureContribution{},
+		Factors:      p.Factors,
+		CreatedAt:    p.CreatedAt,
+	}
+	if resp.Factors == nil {
+		resp.Factors = []RiskFactor{}
+	}
+	if p.Explanation != nil {
+		resp.BaseValue = p.Explanation.BaseValue
+		resp.Features = p.Explanation.Features
 	}
+	return resp
+}
+
+func toRescoreItem(r db.PredictionRescore, original float64) RescoreItem {
+	return RescoreItem{
+		ModelName:         r.ModelName,
+		ModelVersion:      r.ModelVersion,
+		FeatureSchemaHash: r.FeatureSchemaHash,
+		Probability:       r.Probability,
+		RiskLabel:         r.RiskLabel,
+		ProbabilityDelta:  math.Round((r.Probability-original)*10000) / 10000,
+		CreatedAt:         safeTime(r.CreatedAt),
+	}
+}
+
+func toShadowComparisonResponse(summary db.GetShadowComparisonSummaryRow, confusion []db.ListShadowConfusionRow) ShadowComparisonResponse {
+	resp := ShadowComparisonResponse{
+		Total:         summary.Total,
+		Errors:        summary.Errors,
+		Compared:      summary.Compared,
+		MeanDiff:      round4(summary.MeanDiff),
+		MeanAbsDiff:   round4(summary.MeanAbsDiff),
+		MaxAbsDiff:    round4(summary.MaxAbsDiff),
+		P95AbsDiff:    round4(summary.P95AbsDiff),
+		MeanLatencyMs: math.Round(summary.MeanLatencyMs),
+		Confusion:     make(map[string]map[string]int64),
+	}
+	if summary.Compared > 0 {
+		resp.AgreementRate = round4(float64(summary.Agreed) / float64(summary.Compared))
+	}
+
+	levels := []string{"low", "medium", "high"}
+	for _, p := range levels {
+		resp.Confusion[p] = make(map[string]int64, len(levels))
+		for _, s := range levels {
+			resp.Confusion[p][s] = 0
+		}
+	}
+	for _, row := range confusion {
+		if resp.Confusion[row.PrimaryLabel] == nil {
+			resp.Confusion[row.PrimaryLabel] = make(map[string]int64)
+		}
+		resp.Confusion[row.PrimaryLabel][row.ShadowLabel] = row.Count
+	}
+	return resp
+}
+
+// parseWindow đọc khoảng ngày [from, to] (YYYY-MM-DD, to tính cả ngày) thành [from, to+1d).
+// Mặc định 7 ngày gần nhất tính đến hôm nay.
+func parseWindow(fromStr, toStr string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toStr != "" {
+		t, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -6)
+	if fromStr != "" {
+		f, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		from = f
+	}
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+func optionalString(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
 }
 
 func encodeStoredFeatures(input MLRequest) []byte {
@@ -80,6 +315,63 @@ func encodeStoredFeatures(input MLRequest) []byte {
 	return raw
 }
 
+// DecodeStoredFeatures giải mã raw_features rồi đọc về MLRequest.
+// Hỗ trợ cả định dạng cũ {input:{...}, factors:[...]} lẫn fields phẳng.
+func DecodeStoredFeatures(stored []byte) (MLRequest, error) {
+	raw, err := fieldcrypt.Default().OpenJSON(stored)
+	if err != nil {
+		return MLRequest{}, err
+	}
+	var container struct {
+		Input *MLRequest `json:"input"`
+	}
+	if err := json.Unmarshal(raw, &container); err == nil && container.Input != nil {
+		return *container.Input, nil
+	}
+	var out MLRequest
+	err = json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// applyChanges tạo bản features mới từ features gốc theo FeatureChanges (không sửa input).
+func applyChanges(base MLRequest, ch FeatureChanges) MLRequest {
+	out := base
+	if ch.Weight != nil {
+		out.Weight = *ch.Weight
+	}
+	if ch.WeightDelta != nil {
+		out.Weight += *ch.WeightDelta
+	}
+	if ch.APHi != nil {
+		out.APHi = *ch.APHi
+	}
+	if ch.APHiDelta != nil {
+		out.APHi += *ch.APHiDelta
+	}
+	if ch.APLo != nil {
+		out.APLo = *ch.APLo
+	}
+	if ch.APLoDelta != nil {
+		out.APLo += *ch.APLoDelta
+	}
+	if ch.Cholesterol != nil {
+		out.Cholesterol = *ch.Cholesterol
+	}
+	if ch.Gluc != nil {
+		out.Gluc = *ch.Gluc
+	}
+	if ch.Smoke != nil {
+		out.Smoke = *ch.Smoke
+	}
+	if ch.Alco != nil {
+		out.Alco = *ch.Alco
+	}
+	if ch.Active != nil {
+		out.Active = *ch.Active
+	}
+	return out
+}
+
 func decodeDBFactors(raw []byte) []RiskFactor {
 	if len(raw) == 0 {
 		return nil
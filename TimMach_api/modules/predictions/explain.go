@@ -0,0 +1,128 @@
+package predictions
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// factorFeatures map factor (do ML trả về) sang các feature của model để cộng dồn contribution.
+var factorFeatures = map[string][]string{
+	"cholesterol":    {"cholesterol"},
+	"gluc":           {"gluc"},
+	"blood_pressure": {"ap_hi", "ap_lo", "bp_ratio"},
+	"bmi":            {"bmi", "weight", "height"},
+	"smoke":          {"smoke"},
+	"alco":           {"alco"},
+	"active":         {"active"},
+}
+
+var featureLabels = map[string]string{
+	"age_years":   "Tuổi",
+	"gender":      "Giới tính",
+	"height":      "Chiều cao",
+	"weight":      "Cân nặng",
+	"ap_hi":       "Huyết áp tâm thu",
+	"ap_lo":       "Huyết áp tâm trương",
+	"cholesterol": "Cholesterol",
+	"gluc":        "Đường huyết",
+	"smoke":       "Hút thuốc",
+	"alco":        "Uống rượu",
+	"active":      "Vận động",
+	"bmi":         "BMI",
+	"bp_ratio":    "Tỷ lệ huyết áp",
+}
+
+// FeatureLabel trả tên hiển thị tiếng Việt của feature (dùng cho báo cáo).
+func FeatureLabel(feature string) string {
+	if label, ok := featureLabels[feature]; ok {
+		return label
+	}
+	return feature
+}
+
+// buildExplanation chuẩn hóa contribution từ ML: tính share theo trị tuyệt đối,
+// xếp hạng giảm dần theo mức ảnh hưởng và gắn chiều tác động.
+func buildExplanation(resp MLResponse) *Explanation {
+	if len(resp.Contributions) == 0 {
+		return nil
+	}
+
+	features := make([]FeatureContribution, len(resp.Contributions))
+	copy(features, resp.Contributions)
+
+	total := 0.0
+	for _, f := range features {
+		total += math.Abs(f.Contribution)
+	}
+
+	sort.SliceStable(features, func(i, j int) bool {
+		return math.Abs(features[i].Contribution) > math.Abs(features[j].Contribution)
+	})
+
+	for i := range features {
+		features[i].Rank = i + 1
+		if total > 0 {
+			features[i].Share = math.Round(math.Abs(features[i].Contribution)/total*10000) / 10000
+		}
+		switch {
+		case features[i].Contribution > 0:
+			features[i].Direction = "increase"
+		case features[i].Contribution < 0:
+			features[i].Direction = "decrease"
+		default:
+			features[i].Direction = "neutral"
+		}
+	}
+
+	expl := &Explanation{Features: features}
+	if resp.BaseValue != nil {
+		expl.BaseValue = *resp.BaseValue
+	}
+	return expl
+}
+
+// applyFactorContributions điền RiskFactor.Contribution bằng tổng contribution của các feature liên quan.
+func applyFactorContributions(factors []RiskFactor, expl *Explanation) []RiskFactor {
+	if expl == nil || len(factors) == 0 {
+		return factors
+	}
+	byFeature := make(map[string]float64, len(expl.Features))
+	for _, f := range expl.Features {
+		byFeature[f.Feature] = f.Contribution
+	}
+
+	out := make([]RiskFactor, len(factors))
+	for i, f := range factors {
+		out[i] = f
+		total := 0.0
+		for _, feature := range factorFeatures[f.Field] {
+			total += byFeature[feature]
+		}
+		out[i].Contribution = math.Round(total*10000) / 10000
+	}
+	return out
+}
+
+func encodeExplanation(expl *Explanation) []byte {
+	if expl == nil {
+		return []byte("{}")
+	}
+	raw, err := json.Marshal(expl)
+	if err != nil {
+		return []byte("{}")
+	}
+	return raw
+}
+
+// DecodeExplanation đọc cột predictions.explanation; trả nil nếu prediction chưa có dữ liệu giải thích.
+func DecodeExplanation(raw []byte) *Explanation {
+	if len(raw) == 0 {
+		return nil
+	}
+	var expl Explanation
+	if err := json.Unmarshal(raw, &expl); err != nil || len(expl.Features) == 0 {
+		return nil
+	}
+	return &expl
+}
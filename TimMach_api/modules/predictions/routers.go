@@ -11,4 +11,11 @@ func RegisterPredictionRoutes(r *gin.RouterGroup, h *Controller) {
 
 	group.POST("/:id/predict", h.CreatePrediction)
 	group.GET("/:id/predictions", h.ListPredictions)
+	group.POST("/:id/simulate", h.SimulatePrediction)
+
+	predictionGroup := r.Group("/predictions")
+	predictionGroup.GET("/:id/explanation", h.GetExplanation)
+	predictionGroup.GET("/:id/rescores", h.ListRescores)
+	predictionGroup.POST("/rescore", h.RescorePredictions)
+	predictionGroup.GET("/shadow/compare", h.CompareShadow)
 }
@@ -0,0 +1,70 @@
+package predictions
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/httpclient"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const shadowTimeout = 10 * time.Second
+
+// Shadow gửi bản sao request predict tới model ứng viên (ML_SHADOW_BASE_URL).
+// Percent = 100 là shadow toàn bộ; nhỏ hơn là canary (chỉ một phần request được gửi).
+// Kết quả chỉ được lưu vào prediction_shadows, không bao giờ ảnh hưởng response.
+type Shadow struct {
+	HTTP    *resty.Client
+	Percent int
+}
+
+// NewShadow khởi tạo shadow routing; percent được kẹp vào [0, 100].
+func NewShadow(client *resty.Client, percent int) *Shadow {
+	return &Shadow{HTTP: client, Percent: min(max(percent, 0), 100)}
+}
+
+func (s *Shadow) sampled() bool {
+	if s == nil || s.HTTP == nil || s.Percent <= 0 {
+		return false
+	}
+	return s.Percent >= 100 || rand.IntN(100) < s.Percent
+}
+
+// recordShadow gọi model shadow ở background sau khi prediction chính đã được lưu.
+// Lỗi gọi model cũng được lưu (cột error) để tính tỉ lệ lỗi khi so sánh.
+func (h *Controller) recordShadow(predictionID int64, payload MLRequest) {
+	if !h.Shadow.sampled() {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shadowTimeout)
+		defer cancel()
+
+		start := time.Now()
+		var resp MLResponse
+		_, err := httpclient.PostJSON(ctx, h.Shadow.HTTP, "/predict", payload, &resp)
+
+		params := db.CreatePredictionShadowParams{
+			PredictionID: predictionID,
+			LatencyMs:    int32(time.Since(start).Milliseconds()),
+		}
+		if err != nil {
+			params.Error = err.Error()
+		} else {
+			params.ModelName = resp.ModelName
+			params.ModelVersion = resp.ModelVersion
+			params.FeatureSchemaHash = resp.FeatureSchemaHash
+			params.Probability = &resp.Probability
+			params.RiskLabel = resp.RiskLevel
+		}
+
+		if _, err := h.Queries.CreatePredictionShadow(context.Background(), params); err != nil {
+			utils.L().Warnf("save shadow prediction failed: %v", err)
+		}
+	}()
+}
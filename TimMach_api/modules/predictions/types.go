@@ -3,6 +3,8 @@ package predictions
 import (
 	"encoding/json"
 	"time"
+
+	"chidinh/utils/pagination"
 )
 
 // MLRequest định nghĩa payload gửi sang service FastAPI.
@@ -21,11 +23,26 @@ type MLRequest struct {
 }
 
 // MLResponse là output từ FastAPI.
+// BaseValue/Contributions chỉ có khi gọi /predict?explain=true.
 type MLResponse struct {
-	Probability float64      `json:"probability"`
-	Label       int          `json:"label"`
-	RiskLevel   string       `json:"risk_level"`
-	Factors     []RiskFactor `json:"factors,omitempty"`
+	Probability   float64               `json:"probability"`
+	Label         int                   `json:"label"`
+	RiskLevel     string                `json:"risk_level"`
+	Factors       []RiskFactor          `json:"factors,omitempty"`
+	BaseValue     *float64              `json:"base_value,omitempty"`
+	Contributions []FeatureContribution `json:"contributions,omitempty"`
+
+	ModelName         string `json:"model_name"`
+	ModelVersion      string `json:"model_version"`
+	FeatureSchemaHash string `json:"feature_schema_hash"`
+}
+
+// MLModelInfo là output của GET /model bên FastAPI.
+type MLModelInfo struct {
+	ModelName         string   `json:"model_name"`
+	ModelVersion      string   `json:"model_version"`
+	FeatureSchemaHash string   `json:"feature_schema_hash"`
+	FeatureColumns    []string `json:"feature_columns"`
 }
 
 type RiskFactor struct {
@@ -35,33 +52,82 @@ type RiskFactor struct {
 	Contribution float64 `json:"contribution,omitempty"`
 }
 
-// CreatePredictionRequest chứa dữ liệu đầu vào client gửi lên (giống MLRequest).
+// FeatureContribution là phần xác suất mà một feature đóng góp (dương: tăng nguy cơ, âm: giảm nguy cơ).
+// Share/Rank/Direction do API tính sau khi chuẩn hóa.
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Contribution float64 `json:"contribution"`
+	Share        float64 `json:"share"`
+	Rank         int     `json:"rank"`
+	Direction    string  `json:"direction"`
+}
+
+// Explanation được lưu trong predictions.explanation.
+type Explanation struct {
+	BaseValue float64               `json:"base_value"`
+	Features  []FeatureContribution `json:"features"`
+}
+
+// FieldIssue là lỗi hoặc cảnh báo validate của một trường input.
+type FieldIssue struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse là body lỗi khi input không qua validate (400 lỗi bind, 422 lỗi lâm sàng).
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldIssue `json:"fields"`
+}
+
+// CreatePredictionRequest chứa dữ liệu đầu vào client gửi lên.
+// Tuổi và giới tính lấy từ hồ sơ bệnh nhân (tuổi tính theo dob tại MeasuredAt, mặc định là lúc gọi API);
+// AgeYears/Gender (mã ML: 1 = nữ, 2 = nam) chỉ dùng khi hồ sơ thiếu thông tin, lệch với hồ sơ thì bị bỏ qua và ghi cảnh báo.
 type CreatePredictionRequest struct {
-	AgeYears    float64 `json:"age_years" binding:"required"`
-	Gender      int     `json:"gender" binding:"required"`
-	Height      float64 `json:"height" binding:"required"`
-	Weight      float64 `json:"weight" binding:"required"`
-	APHi        int     `json:"ap_hi" binding:"required"`
-	APLo        int     `json:"ap_lo" binding:"required"`
-	Cholesterol int     `json:"cholesterol" binding:"required"`
-	Gluc        int     `json:"gluc" binding:"required"`
-	Smoke       *int    `json:"smoke" binding:"required,oneof=0 1"`
-	Alco        *int    `json:"alco" binding:"required,oneof=0 1"`
-	Active      *int    `json:"active" binding:"required,oneof=0 1"`
+	AgeYears    *float64   `json:"age_years,omitempty"`
+	Gender      *int       `json:"gender,omitempty"`
+	MeasuredAt  *time.Time `json:"measured_at,omitempty"`
+	Height      float64    `json:"height" binding:"required"`
+	Weight      float64    `json:"weight" binding:"required"`
+	APHi        int        `json:"ap_hi" binding:"required"`
+	APLo        int        `json:"ap_lo" binding:"required"`
+	Cholesterol int        `json:"cholesterol" binding:"required"`
+	Gluc        int        `json:"gluc" binding:"required"`
+	Smoke       *int       `json:"smoke" binding:"required,oneof=0 1"`
+	Alco        *int       `json:"alco" binding:"required,oneof=0 1"`
+	Active      *int       `json:"active" binding:"required,oneof=0 1"`
 }
 
 type PredictionResponse struct {
-	ID          string          `json:"id"`
-	PatientID   string          `json:"patient_id"`
-	Probability float64         `json:"probability"`
-	RiskLabel   string          `json:"risk_label"`
-	RawFeatures json.RawMessage `json:"raw_features,omitempty"`
-	Factors     []RiskFactor    `json:"factors,omitempty"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID                string          `json:"id"`
+	PatientID         string          `json:"patient_id"`
+	Probability       float64         `json:"probability"`
+	RiskLabel         string          `json:"risk_label"`
+	RawFeatures       json.RawMessage `json:"raw_features,omitempty"`
+	Factors           []RiskFactor    `json:"factors,omitempty"`
+	Warnings          []FieldIssue    `json:"warnings,omitempty"`
+	ModelName         string          `json:"model_name"`
+	ModelVersion      string          `json:"model_version"`
+	FeatureSchemaHash string          `json:"feature_schema_hash"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+type ExplanationResponse struct {
+	PredictionID string                `json:"prediction_id"`
+	PatientID    string                `json:"patient_id"`
+	Probability  float64               `json:"probability"`
+	RiskLabel    string                `json:"risk_label"`
+	BaseValue    float64               `json:"base_value"`
+	Features     []FeatureContribution `json:"features"`
+	Factors      []RiskFactor          `json:"factors"`
+	CreatedAt    time.Time             `json:"created_at"`
 }
 
 type ListPredictionsResponse struct {
 	Predictions []PredictionResponse `json:"predictions"`
+	pagination.Meta
 }
 
 // Recommendation response struct
@@ -83,7 +149,110 @@ type CreatePredictionResponse struct {
 	Prediction     PredictionResponse `json:"prediction"`
 	Recommendation RecommendationPlan `json:"recommendation"`
 }
+
+// FeatureChanges mô tả thay đổi giả định trên features gốc.
+// Trường thường gán giá trị tuyệt đối, trường *_delta cộng thêm vào giá trị hiện tại.
+type FeatureChanges struct {
+	Weight      *float64 `json:"weight,omitempty"`
+	WeightDelta *float64 `json:"weight_delta,omitempty"`
+	APHi        *int     `json:"ap_hi,omitempty"`
+	APHiDelta   *int     `json:"ap_hi_delta,omitempty"`
+	APLo        *int     `json:"ap_lo,omitempty"`
+	APLoDelta   *int     `json:"ap_lo_delta,omitempty"`
+	Cholesterol *int     `json:"cholesterol,omitempty" binding:"omitempty,min=1,max=3"`
+	Gluc        *int     `json:"gluc,omitempty" binding:"omitempty,min=1,max=3"`
+	Smoke       *int     `json:"smoke,omitempty" binding:"omitempty,oneof=0 1"`
+	Alco        *int     `json:"alco,omitempty" binding:"omitempty,oneof=0 1"`
+	Active      *int     `json:"active,omitempty" binding:"omitempty,oneof=0 1"`
+}
+
+type SimulationScenario struct {
+	Name    string         `json:"name" binding:"required"`
+	Changes FeatureChanges `json:"changes"`
+}
+
+type SimulateRequest struct {
+	Scenarios []SimulationScenario `json:"scenarios" binding:"required,min=1,max=10,dive"`
+}
+
+type SimulationResult struct {
+	Name             string         `json:"name"`
+	Changes          FeatureChanges `json:"changes"`
+	Features         MLRequest      `json:"features"`
+	Probability      float64        `json:"probability"`
+	RiskLevel        string         `json:"risk_level"`
+	ProbabilityDelta float64        `json:"probability_delta"`
+}
+
+type SimulationBaseline struct {
+	PredictionID string    `json:"prediction_id"`
+	MeasuredAt   time.Time `json:"measured_at"`
+	Features     MLRequest `json:"features"`
+	Probability  float64   `json:"probability"`
+	RiskLevel    string    `json:"risk_level"`
+}
+
+type SimulateResponse struct {
+	PatientID string             `json:"patient_id"`
+	Baseline  SimulationBaseline `json:"baseline"`
+	Scenarios []SimulationResult `json:"scenarios"`
+}
+
+type RescoreRequest struct {
+	PatientID *int64 `json:"patient_id,omitempty"`
+	Limit     int32  `json:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type RescoreFailure struct {
+	PredictionID string `json:"prediction_id"`
+	Error        string `json:"error"`
+}
+
+type RescoreResponse struct {
+	ModelName    string           `json:"model_name"`
+	ModelVersion string           `json:"model_version"`
+	Rescored     int              `json:"rescored"`
+	Failed       []RescoreFailure `json:"failed"`
+}
+
+type RescoreItem struct {
+	ModelName         string    `json:"model_name"`
+	ModelVersion      string    `json:"model_version"`
+	FeatureSchemaHash string    `json:"feature_schema_hash"`
+	Probability       float64   `json:"probability"`
+	RiskLabel         string    `json:"risk_label"`
+	ProbabilityDelta  float64   `json:"probability_delta"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ListRescoresResponse struct {
+	Prediction PredictionResponse `json:"prediction"`
+	Rescores   []RescoreItem      `json:"rescores"`
+}
+
+type ShadowComparisonParams struct {
+	From         string `form:"from"`
+	To           string `form:"to"`
+	ModelVersion string `form:"model_version"`
+}
+
+type ShadowComparisonResponse struct {
+	From          time.Time                   `json:"from"`
+	To            time.Time                   `json:"to"`
+	ModelVersion  string                      `json:"model_version,omitempty"`
+	Total         int64                       `json:"total"`
+	Errors        int64                       `json:"errors"`
+	Compared      int64                       `json:"compared"`
+	AgreementRate float64                     `json:"agreement_rate"`
+	MeanDiff      float64                     `json:"mean_diff"`
+	MeanAbsDiff   float64                     `json:"mean_abs_diff"`
+	MaxAbsDiff    float64                     `json:"max_abs_diff"`
+	P95AbsDiff    float64                     `json:"p95_abs_diff"`
+	MeanLatencyMs float64                     `json:"mean_latency_ms"`
+	Confusion     map[string]map[string]int64 `json:"confusion"` // [primary][shadow] -> count
+}
+
+// ListPredictionsParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
 type ListPredictionsParams struct {
-	Limit  int32 `form:"limit,default=10"`
-	Offset int32 `form:"offset,default=0"`
+	pagination.Params
 }
@@ -0,0 +1,172 @@
+package predictions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Mã lỗi/cảnh báo trong FieldIssue.Code.
+const (
+	issueRequired     = "required"
+	issueInvalid      = "invalid"
+	issueOutOfRange   = "out_of_range"
+	issueInconsistent = "inconsistent"
+	issueUnusual      = "unusual"
+	// giá trị client gửi lệch với hồ sơ bệnh nhân nên không được dùng
+	issueOverrideIgnored = "override_ignored"
+)
+
+// fieldRange: ngoài [Min, Max] là lỗi (không thể xảy ra về sinh lý),
+// ngoài [WarnMin, WarnMax] là cảnh báo (có thể đúng nhưng bất thường hoặc ngoài dữ liệu huấn luyện).
+type fieldRange struct {
+	Field   string
+	Label   string
+	Unit    string
+	Min     float64
+	Max     float64
+	WarnMin float64
+	WarnMax float64
+}
+
+var physiologicRanges = []fieldRange{
+	{Field: "age_years", Label: "Tuổi", Unit: "năm", Min: 18, Max: 120, WarnMin: 30, WarnMax: 65},
+	{Field: "height", Label: "Chiều cao", Unit: "cm", Min: 100, Max: 250, WarnMin: 140, WarnMax: 210},
+	{Field: "weight", Label: "Cân nặng", Unit: "kg", Min: 25, Max: 300, WarnMin: 40, WarnMax: 180},
+	{Field: "ap_hi", Label: "Huyết áp tâm thu", Unit: "mmHg", Min: 60, Max: 260, WarnMin: 90, WarnMax: 200},
+	{Field: "ap_lo", Label: "Huyết áp tâm trương", Unit: "mmHg", Min: 30, Max: 180, WarnMin: 50, WarnMax: 120},
+	{Field: "bmi", Label: "BMI", Unit: "kg/m²", Min: 10, Max: 80, WarnMin: 15, WarnMax: 50},
+}
+
+// validateFeatures kiểm tra khoảng sinh lý và quy tắc liên trường trước khi gửi ML.
+// errs khác rỗng thì không được chấm điểm; warns được lưu kèm prediction.
+func validateFeatures(f MLRequest) (errs, warns []FieldIssue) {
+	values := map[string]float64{
+		"age_years": f.AgeYears,
+		"height":    f.Height,
+		"weight":    f.Weight,
+		"ap_hi":     float64(f.APHi),
+		"ap_lo":     float64(f.APLo),
+	}
+	if f.Height > 0 {
+		h := f.Height / 100
+		values["bmi"] = f.Weight / (h * h)
+	}
+
+	for _, r := range physiologicRanges {
+		v, ok := values[r.Field]
+		if !ok {
+			continue
+		}
+		switch {
+		case v < r.Min || v > r.Max:
+			errs = append(errs, FieldIssue{
+				Field:   r.Field,
+				Code:    issueOutOfRange,
+				Message: fmt.Sprintf("%s phải trong khoảng %g–%g %s", r.Label, r.Min, r.Max, r.Unit),
+			})
+		case v < r.WarnMin || v > r.WarnMax:
+			warns = append(warns, FieldIssue{
+				Field:   r.Field,
+				Code:    issueUnusual,
+				Message: fmt.Sprintf("%s %.1f %s nằm ngoài khoảng thường gặp %g–%g", r.Label, v, r.Unit, r.WarnMin, r.WarnMax),
+			})
+		}
+	}
+
+	if f.Gender != 1 && f.Gender != 2 {
+		errs = append(errs, FieldIssue{Field: "gender", Code: issueInvalid, Message: "gender phải là 1 (nữ) hoặc 2 (nam)"})
+	}
+	if f.Cholesterol < 1 || f.Cholesterol > 3 {
+		errs = append(errs, FieldIssue{Field: "cholesterol", Code: issueInvalid, Message: "cholesterol phải là 1, 2 hoặc 3"})
+	}
+	if f.Gluc < 1 || f.Gluc > 3 {
+		errs = append(errs, FieldIssue{Field: "gluc", Code: issueInvalid, Message: "gluc phải là 1, 2 hoặc 3"})
+	}
+
+	if f.APLo > 0 && f.APHi > 0 {
+		pulse := f.APHi - f.APLo
+		switch {
+		case pulse <= 0:
+			errs = append(errs, FieldIssue{
+				Field:   "ap_lo",
+				Code:    issueInconsistent,
+				Message: "Huyết áp tâm trương phải nhỏ hơn huyết áp tâm thu",
+			})
+		case pulse < 20 || pulse > 100:
+			warns = append(warns, FieldIssue{
+				Field:   "ap_hi",
+				Code:    issueUnusual,
+				Message: fmt.Sprintf("Hiệu áp %d mmHg bất thường (thường 20–100)", pulse),
+			})
+		}
+	}
+
+	return errs, warns
+}
+
+// bindingIssues chuyển lỗi bind JSON của gin thành lỗi theo từng trường (tên theo json tag của obj).
+func bindingIssues(err error, obj any) []FieldIssue {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return []FieldIssue{{Field: typeErr.Field, Code: issueInvalid, Message: "sai kiểu dữ liệu"}}
+		}
+		return []FieldIssue{{Field: "body", Code: issueInvalid, Message: "body không phải JSON hợp lệ"}}
+	}
+
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	issues := make([]FieldIssue, 0, len(verrs))
+	for _, fe := range verrs {
+		name := fe.Field()
+		if sf, ok := t.FieldByName(fe.StructField()); ok {
+			if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; tag != "" {
+				name = tag
+			}
+		}
+		switch fe.Tag() {
+		case "required":
+			issues = append(issues, FieldIssue{Field: name, Code: issueRequired, Message: "bắt buộc và khác 0"})
+		case "oneof":
+			issues = append(issues, FieldIssue{Field: name, Code: issueInvalid, Message: "phải là một trong: " + fe.Param()})
+		default:
+			issues = append(issues, FieldIssue{Field: name, Code: issueInvalid, Message: fmt.Sprintf("không hợp lệ (%s)", fe.Tag())})
+		}
+	}
+	return issues
+}
+
+func respondValidation(c *gin.Context, code int, msg string, issues []FieldIssue) {
+	c.AbortWithStatusJSON(code, ValidationErrorResponse{Error: msg, Fields: issues})
+}
+
+func encodeWarnings(warns []FieldIssue) []byte {
+	if warns == nil {
+		warns = []FieldIssue{}
+	}
+	data, err := json.Marshal(warns)
+	if err != nil {
+		return []byte("[]")
+	}
+	return data
+}
+
+func decodeWarnings(raw []byte) []FieldIssue {
+	var warns []FieldIssue
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, &warns); err != nil {
+		return nil
+	}
+	return warns
+}
@@ -1,15 +1,12 @@
 package reports
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"html/template"
 	"math"
 	"net/http"
-	"net/mail"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -17,27 +14,32 @@ import (
 	"time"
 
 	db "chidinh/db/sqlc"
+	"chidinh/modules/emails"
 	"chidinh/modules/predictions"
 	"chidinh/utils"
 	"chidinh/utils/mailer"
+	"chidinh/utils/pagination"
 
-	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/chromedp"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 )
 
 const reportStorageDir = "tmp/reports"
 
+// Controller: email báo cáo được ghi vào Outbox và gửi nền; Mailer chỉ dùng để kiểm tra SMTP đã cấu hình.
 type Controller struct {
-	Queries *db.Queries
-	Mailer  *mailer.Mailer
+	Queries   *db.Queries
+	Mailer    *mailer.Mailer
+	Brandings *emails.Brandings
+	Outbox    *emails.Outbox
 }
 
-func NewController(queries *db.Queries, mailerSvc *mailer.Mailer) *Controller {
+func NewController(queries *db.Queries, mailerSvc *mailer.Mailer, brandings *emails.Brandings, outbox *emails.Outbox) *Controller {
 	return &Controller{
-		Queries: queries,
-		Mailer:  mailerSvc,
+		Queries:   queries,
+		Mailer:    mailerSvc,
+		Brandings: brandings,
+		Outbox:    outbox,
 	}
 }
 
@@ -57,6 +59,18 @@ type PredictionView struct {
 	RiskLevel       string
 	Label           string
 	MainRiskFactors []string
+	BaseValuePct    float64
+	Contributions   []ContributionView
+}
+
+// ContributionView là một thanh trong biểu đồ đóng góp của feature.
+// WidthPct tỉ lệ theo feature có ảnh hưởng lớn nhất (100%).
+type ContributionView struct {
+	Label     string
+	Value     string
+	PointsPct float64
+	WidthPct  int
+	Increase  bool
 }
 
 type ExerciseSessionView struct {
@@ -123,7 +137,8 @@ func (h *Controller) GetPatientReport(c *gin.Context) {
 
 // SendPatientReportEmail gửi báo cáo PDF qua email (legacy endpoint).
 // POST /patients/:id/report/email
-// Generate PDF on-the-fly và gửi trực tiếp qua email, không lưu vào database.
+// Generate PDF on-the-fly và đưa email vào outbox (202), không lưu báo cáo vào database;
+// theo dõi trạng thái gửi qua GET /email-outbox/:email_id.
 func (h *Controller) SendPatientReportEmail(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
 	if !ok {
@@ -148,13 +163,9 @@ func (h *Controller) SendPatientReportEmail(c *gin.Context) {
 		return
 	}
 
-	email := strings.TrimSpace(req.Email)
-	if email == "" {
-		utils.RespondError(c, http.StatusBadRequest, "email is required")
-		return
-	}
-	if _, err := mail.ParseAddress(email); err != nil {
-		utils.RespondError(c, http.StatusBadRequest, "email is invalid")
+	recipients, err := parseRecipients(req)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -170,30 +181,32 @@ func (h *Controller) SendPatientReportEmail(c *gin.Context) {
 		return
 	}
 
-	subject := req.Subject
-	if subject == "" {
-		subject = "Báo cáo kết quả tim mạch"
-	}
-	body := req.Message
-	if body == "" {
-		body = "Đính kèm báo cáo kết quả tim mạch của bạn."
+	patient, err := h.getOwnedPatient(c, userID, int64(patientID))
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return
 	}
-
-	err = h.Mailer.Send(email, subject, body, []mailer.Attachment{{
+	prepared, err := h.buildReportEmail(c, userID, patient, time.Now(), recipients, req, mailer.Attachment{
 		Filename: filename,
 		MimeType: "application/pdf",
 		Content:  pdfBytes,
-	}})
+	})
 	if err != nil {
-		if errors.Is(err, mailer.ErrNotConfigured) {
-			utils.RespondError(c, http.StatusInternalServerError, "email service is not configured")
-			return
-		}
-		utils.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("cannot send email: %v", err))
+		respondReportEmailError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "sent"})
+	queued, err := h.Outbox.Enqueue(c, prepared.Message, emails.EnqueueOptions{
+		UserID:        userID,
+		PatientID:     &patient.ID,
+		PDFProtection: prepared.Protection,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot queue email")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toSendReportEmailResponse(0, prepared, queued, nil))
 }
 
 // CreateReport tạo báo cáo mới và lưu vào database.
@@ -241,27 +254,9 @@ func (h *Controller) CreateReport(c *gin.Context) {
 		return
 	}
 
-	filename := fmt.Sprintf("report_%d_%s.pdf", patientID, time.Now().Format("20060102_150405"))
-	filePath, err := saveReportFile(int64(patientID), filename, pdfBytes)
-	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("cannot save report file: %v", err))
-		return
-	}
-
-	recipientsJSON, err := json.Marshal([]ReportRecipient{})
-	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot initialize recipients")
-		return
-	}
-
-	report, err := h.Queries.CreateReport(c, db.CreateReportParams{
-		PatientID:  int64(patientID),
-		Filename:   filename,
-		FileUrl:    filePath,
-		Recipients: recipientsJSON,
-	})
+	report, err := h.storeReport(c, int64(patientID), pdfBytes)
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot create report record")
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -275,7 +270,7 @@ func (h *Controller) CreateReport(c *gin.Context) {
 }
 
 // ListReports lấy danh sách báo cáo của bệnh nhân.
-// GET /patients/:id/reports?limit=10&offset=0
+// GET /patients/:id/reports?limit=10&cursor=&sort=-created_at
 // Trả về danh sách reports với thông tin recipients đã gửi email.
 func (h *Controller) ListReports(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
@@ -307,28 +302,31 @@ func (h *Controller) ListReports(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
 		return
 	}
-	limit := req.Limit
-	if limit == 0 {
-		limit = 10
-	}
-
-	items, err := h.Queries.ListReportsByPatient(c, db.ListReportsByPatientParams{
-		PatientID: int64(patientID),
-		Limit:     limit,
-		Offset:    req.Offset,
-	})
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot list reports")
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	total, err := h.Queries.CountReportsByPatient(c, int64(patientID))
+	params := db.ListReportsByPatientDescParams{
+		PatientID:       int64(patientID),
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.Report
+	if pager.Desc {
+		items, err = h.Queries.ListReportsByPatientDesc(c, params)
+	} else {
+		items, err = h.Queries.ListReportsByPatientAsc(c, db.ListReportsByPatientAscParams(params))
+	}
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot count reports")
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list reports")
 		return
 	}
 
-	resp := ListReportsResponse{Reports: make([]ReportResponse, 0, len(items)), Total: total}
+	items, meta := pagination.Trim(items, pager, reportCursor)
+	resp := ListReportsResponse{Reports: make([]ReportResponse, 0, len(items)), Meta: meta}
 	for _, r := range items {
 		item, mapErr := mapReportResponse(r)
 		if mapErr != nil {
@@ -389,9 +387,10 @@ func (h *Controller) DownloadReport(c *gin.Context) {
 // Workflow:
 // 1. Lấy report từ database
 // 2. Validate ownership
-// 3. Đọc PDF file từ disk
-// 4. Gửi email qua mailer service
-// 5. Cập nhật recipients JSONB trong database (track email đã gửi)
+// 3. Kiểm tra to/cc/bcc, bỏ địa chỉ đã nhận báo cáo (trừ khi resend)
+// 4. Đọc PDF file từ disk
+// 5. Đưa email vào outbox (mỗi địa chỉ một dòng); cùng câu lệnh thêm recipient queued vào recipients JSONB
+// 6. Job gửi email cập nhật trạng thái từng recipient (sent | failed | bounced) theo kết quả thực tế
 func (h *Controller) SendReportEmail(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
 	if !ok {
@@ -416,17 +415,13 @@ func (h *Controller) SendReportEmail(c *gin.Context) {
 		return
 	}
 
-	email := strings.TrimSpace(req.Email)
-	if email == "" {
-		utils.RespondError(c, http.StatusBadRequest, "email is required")
-		return
-	}
-	if _, err := mail.ParseAddress(email); err != nil {
-		utils.RespondError(c, http.StatusBadRequest, "email is invalid")
+	recipients, err := parseRecipients(req)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	report, _, err := h.getOwnedReport(c, userID, int64(reportID))
+	report, patient, err := h.getOwnedReport(c, userID, int64(reportID))
 	if err != nil {
 		status := http.StatusInternalServerError
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -438,6 +433,21 @@ func (h *Controller) SendReportEmail(c *gin.Context) {
 		return
 	}
 
+	// Bỏ địa chỉ đã nhận báo cáo này (kể cả đang chờ gửi) để tránh gửi trùng.
+	var skipped []string
+	if !req.Resend {
+		history, err := decodeRecipients(report.Recipients)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "cannot parse recipients")
+			return
+		}
+		recipients, skipped = recipients.withoutPrevious(history)
+		if recipients.count() == 0 {
+			utils.RespondError(c, http.StatusConflict, "all recipients already received this report")
+			return
+		}
+	}
+
 	fileBytes, err := os.ReadFile(report.FileUrl)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -448,59 +458,28 @@ func (h *Controller) SendReportEmail(c *gin.Context) {
 		return
 	}
 
-	subject := req.Subject
-	if subject == "" {
-		subject = "Báo cáo kết quả tim mạch"
-	}
-	body := req.Message
-	if body == "" {
-		body = "Đính kèm báo cáo kết quả tim mạch của bạn."
-	}
-
-	err = h.Mailer.Send(email, subject, body, []mailer.Attachment{{
+	prepared, err := h.buildReportEmail(c, userID, patient, report.CreatedAt.Time, recipients, req, mailer.Attachment{
 		Filename: report.Filename,
 		MimeType: "application/pdf",
 		Content:  fileBytes,
-	}})
-	if err != nil {
-		if errors.Is(err, mailer.ErrNotConfigured) {
-			utils.RespondError(c, http.StatusInternalServerError, "email service is not configured")
-			return
-		}
-		utils.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("cannot send email: %v", err))
-		return
-	}
-
-	recipients, err := decodeRecipients(report.Recipients)
-	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot parse recipients")
-		return
-	}
-	recipients = append(recipients, ReportRecipient{
-		Email:  email,
-		SentAt: time.Now(),
-		Status: "sent",
 	})
-	recipientsJSON, err := json.Marshal(recipients)
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot update recipients")
+		respondReportEmailError(c, err)
 		return
 	}
 
-	_, err = h.Queries.UpdateReportRecipients(c, db.UpdateReportRecipientsParams{
-		ID:         report.ID,
-		Recipients: recipientsJSON,
+	queued, err := h.Outbox.Enqueue(c, prepared.Message, emails.EnqueueOptions{
+		UserID:        userID,
+		PatientID:     &report.PatientID,
+		ReportID:      &report.ID,
+		PDFProtection: prepared.Protection,
 	})
 	if err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot persist recipients")
+		utils.RespondError(c, http.StatusInternalServerError, "cannot queue email")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "sent",
-		"report_id": report.ID,
-		"sent_to":   email,
-	})
+	c.JSON(http.StatusAccepted, toSendReportEmailResponse(report.ID, prepared, queued, skipped))
 }
 
 // DeleteReport xóa báo cáo (cả file và database record).
@@ -531,6 +510,12 @@ func (h *Controller) DeleteReport(c *gin.Context) {
 		return
 	}
 
+	// Email chưa gửi giữ bản sao PDF trong outbox: huỷ trước khi xoá báo cáo.
+	if _, err := h.Queries.CancelQueuedReportEmails(c, &report.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot cancel queued report emails")
+		return
+	}
+
 	if err := os.Remove(report.FileUrl); err != nil && !errors.Is(err, os.ErrNotExist) {
 		utils.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("cannot delete file: %v", err))
 		return
@@ -546,57 +531,20 @@ func (h *Controller) DeleteReport(c *gin.Context) {
 
 // buildPatientReportPDF tạo file PDF báo cáo từ HTML template.
 // Trả về: (filename, pdfBytes, error)
-// Sử dụng chromedp để convert HTML → PDF.
 func (h *Controller) buildPatientReportPDF(ctx context.Context, userID string, patientID int64) (string, []byte, error) {
-	vm, err := buildPatientReportViewModel(ctx, h.Queries, userID, patientID)
+	branding, err := h.Brandings.ForUser(ctx, userID)
 	if err != nil {
 		return "", nil, err
 	}
 
-	tplPath := filepath.Join("modules", "reports", "templates", "patient_report.html")
-	tpl, err := template.ParseFiles(tplPath)
+	vm, err := buildPatientReportViewModel(ctx, h.Queries, userID, patientID, branding.Name)
 	if err != nil {
-		return "", nil, fmt.Errorf("cannot load template: %w", err)
-	}
-
-	var htmlBuf bytes.Buffer
-	if err := tpl.Execute(&htmlBuf, vm); err != nil {
-		return "", nil, fmt.Errorf("cannot render template: %w", err)
+		return "", nil, err
 	}
 
-	// create context
-	ctx, cancel := chromedp.NewContext(ctx)
-	defer cancel()
-
-	var pdfBuf []byte
-	// capture entire browser viewport, returning png with quality=90
-	err = chromedp.Run(ctx,
-		chromedp.Navigate("about:blank"),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			frameTree, err := page.GetFrameTree().Do(ctx)
-			if err != nil {
-				return err
-			}
-			return page.SetDocumentContent(frameTree.Frame.ID, htmlBuf.String()).Do(ctx)
-		}),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			buf, _, err := page.PrintToPDF().
-				WithPrintBackground(true).
-				WithMarginTop(0.4).
-				WithMarginBottom(0.4).
-				WithMarginLeft(0.4).
-				WithMarginRight(0.4).
-				Do(ctx)
-			if err != nil {
-				return err
-			}
-			pdfBuf = buf
-			return nil
-		}),
-	)
-
+	pdfBuf, err := RenderPDF(ctx, "patient_report.html", vm)
 	if err != nil {
-		return "", nil, fmt.Errorf("cannot generate pdf: %w", err)
+		return "", nil, err
 	}
 
 	filename := fmt.Sprintf("patient_%d_report.pdf", patientID)
@@ -609,10 +557,10 @@ func (h *Controller) buildPatientReportPDF(ctx context.Context, userID string, p
 // - predictions (latest prediction + history)
 // - raw_features (height, weight, BMI từ prediction gần nhất)
 // - exercise_recommendations (exercise plan)
-func buildPatientReportViewModel(ctx context.Context, q *db.Queries, userID string, patientID int64) (PatientReportViewModel, error) {
+func buildPatientReportViewModel(ctx context.Context, q *db.Queries, userID string, patientID int64, clinicName string) (PatientReportViewModel, error) {
 	now := time.Now()
 	vm := PatientReportViewModel{
-		ClinicName:  "HeartCare Clinic",
+		ClinicName:  clinicName,
 		GeneratedAt: now.Format("2006-01-02 15:04"),
 	}
 
@@ -624,22 +572,26 @@ func buildPatientReportViewModel(ctx context.Context, q *db.Queries, userID stri
 		return vm, errPatientForbidden
 	}
 
-	vm.Patient = mapPatientInfo(patient)
+	vm.Patient, err = mapPatientInfo(patient)
+	if err != nil {
+		return vm, err
+	}
 
 	// Lấy prediction gần nhất (nếu có)
 	latestPred, err := q.GetLatestPredictionByPatient(ctx, patientID)
 	if err == nil {
-		features, factors := decodeFeatures(latestPred.RawFeatures, latestPred.Factors)
+		features, factors := decodeFeatures(utils.OpenFeatures(latestPred.RawFeatures), latestPred.Factors)
 		// Map prediction cho view (risk, probability, factors từ ML)
-		vm.LatestPrediction = mapPredictionView(latestPred, factors)
+		vm.LatestPrediction = mapPredictionView(latestPred, factors, predictions.DecodeExplanation(latestPred.Explanation))
 		// Merge health metrics từ raw_features vào patient info (height, weight, BMI)
 		vm.Patient = mergeFeaturesIntoPatient(vm.Patient, features)
 	}
 
-	historyRows, err := q.ListPredictionsByPatient(ctx, db.ListPredictionsByPatientParams{
-		PatientID: patientID,
-		Limit:     50,
-		Offset:    0,
+	historyRows, err := q.ListPredictionsByPatientDesc(ctx, db.ListPredictionsByPatientDescParams{
+		PatientID:       patientID,
+		CursorCreatedAt: pagination.Newest.CursorTime(),
+		CursorID:        pagination.Newest.CursorID(),
+		Limit:           50,
 	})
 	if err == nil {
 		vm.History = mapHistory(historyRows)
@@ -664,10 +616,11 @@ func loadRecommendationPlan(ctx context.Context, q *db.Queries, patientID int64,
 	if latestPred.ID != 0 {
 		rec, err = q.GetExerciseRecommendationByPrediction(ctx, latestPred.ID)
 	} else {
-		items, listErr := q.ListExerciseRecommendationsByPatient(ctx, db.ListExerciseRecommendationsByPatientParams{
-			PatientID: patientID,
-			Limit:     1,
-			Offset:    0,
+		items, listErr := q.ListExerciseRecommendationsByPatientDesc(ctx, db.ListExerciseRecommendationsByPatientDescParams{
+			PatientID:       patientID,
+			CursorCreatedAt: pagination.Newest.CursorTime(),
+			CursorID:        pagination.Newest.CursorID(),
+			Limit:           1,
 		})
 		if listErr == nil && len(items) > 0 {
 			rec = items[0]
@@ -709,41 +662,72 @@ func loadRecommendationPlan(ctx context.Context, q *db.Queries, patientID int64,
 	return plan, nil
 }
 
-// mapPatientInfo convert db.Patient → PatientInfoView.
+// mapPatientInfo convert db.Patient → PatientInfoView (giải mã name/dob).
 // Tính tuổi từ DOB (date of birth).
-func mapPatientInfo(p db.Patient) PatientInfoView {
-	age := 0
-	if p.Dob.Valid {
-		diff := time.Since(p.Dob.Time)
-		age = int(diff.Hours() / 24 / 365)
+func mapPatientInfo(p db.Patient) (PatientInfoView, error) {
+	name, dob, err := utils.OpenPatient(p.Name, p.Dob)
+	if err != nil {
+		return PatientInfoView{}, err
 	}
 	return PatientInfoView{
-		Name:     p.Name,
-		DOB:      p.Dob.Time.Format("2006-01-02"),
-		Gender:   genderLabel(p.Gender),
+		Name:     name,
+		DOB:      dob.Format("2006-01-02"),
+		Gender:   utils.GenderLabel(p.Gender),
 		HeightCm: 0,
 		WeightKg: 0,
 		BMI:      0,
-		AgeYears: age,
-	}
+		AgeYears: int(utils.AgeYearsAt(dob, time.Now())),
+	}, nil
 }
 
 // mapPredictionView convert db.Prediction → PredictionView.
-// Extract risk factors từ raw_features JSONB.
-func mapPredictionView(pred db.Prediction, factors []predictions.RiskFactor) *PredictionView {
+// Extract risk factors từ raw_features JSONB, kèm biểu đồ contribution nếu prediction có explanation.
+func mapPredictionView(pred db.Prediction, factors []predictions.RiskFactor, expl *predictions.Explanation) *PredictionView {
 	mainFactors := make([]string, 0, len(factors))
 	for _, f := range factors {
 		if f.Message != "" {
 			mainFactors = append(mainFactors, f.Message)
 		}
 	}
-	return &PredictionView{
+	view := &PredictionView{
 		Time:            pred.CreatedAt.Time.Format("2006-01-02 15:04"),
 		ProbabilityPct:  int(pred.Probability * 100),
 		RiskLevel:       pred.RiskLabel,
 		Label:           riskLabel(pred.RiskLabel),
 		MainRiskFactors: mainFactors,
 	}
+	if expl != nil {
+		view.BaseValuePct = math.Round(expl.BaseValue*1000) / 10
+		view.Contributions = mapContributions(expl.Features)
+	}
+	return view
+}
+
+// mapContributions lấy tối đa maxChartFeatures feature ảnh hưởng nhất (đã xếp hạng sẵn) để vẽ biểu đồ.
+func mapContributions(features []predictions.FeatureContribution) []ContributionView {
+	const maxChartFeatures = 8
+	if len(features) > maxChartFeatures {
+		features = features[:maxChartFeatures]
+	}
+	maxAbs := 0.0
+	for _, f := range features {
+		maxAbs = math.Max(maxAbs, math.Abs(f.Contribution))
+	}
+	out := make([]ContributionView, 0, len(features))
+	for _, f := range features {
+		width := 0
+		if maxAbs > 0 {
+			width = int(math.Round(math.Abs(f.Contribution) / maxAbs * 100))
+		}
+		out = append(out, ContributionView{
+			Label:     predictions.FeatureLabel(f.Feature),
+			Value:     strconv.FormatFloat(f.Value, 'f', -1, 64),
+			PointsPct: math.Round(f.Contribution*1000) / 10,
+			WidthPct:  width,
+			Increase:  f.Contribution > 0,
+		})
+	}
+	return out
 }
 
 // mapHistory convert danh sách predictions → history items cho báo cáo.
@@ -807,8 +791,8 @@ func decodeFactors(raw []byte) []predictions.RiskFactor {
 }
 
 // mergeFeaturesIntoPatient merge health metrics từ raw_features vào patient info.
-// Lấy height, weight từ prediction để tính BMI.
-// Update age từ age_years nếu chính xác hơn tính từ DOB.
+// Lấy height, weight từ prediction để tính BMI. Tuổi luôn tính từ DOB (age_years của prediction
+// cũng được tính từ DOB tại thời điểm đo nên không cần ghi đè).
 func mergeFeaturesIntoPatient(info PatientInfoView, f featuresPayload) PatientInfoView {
 	if f.Height > 0 {
 		info.HeightCm = f.Height
@@ -820,23 +804,9 @@ func mergeFeaturesIntoPatient(info PatientInfoView, f featuresPayload) PatientIn
 		hm := f.Height / 100
 		info.BMI = math.Round((f.Weight/(hm*hm))*10) / 10
 	}
-	if f.AgeYears > 0 {
-		info.AgeYears = int(math.Round(f.AgeYears))
-	}
 	return info
 }
 
-func genderLabel(g int16) string {
-	switch g {
-	case 1:
-		return "Male"
-	case 2:
-		return "Female"
-	default:
-		return "Other"
-	}
-}
-
 func riskLabel(r string) string {
 	switch strings.ToLower(r) {
 	case "high":
@@ -850,11 +820,41 @@ func riskLabel(r string) string {
 	}
 }
 
+// storeReport lưu file PDF lên disk và tạo record reports (chưa có recipient).
+func (h *Controller) storeReport(ctx context.Context, patientID int64, pdfBytes []byte) (db.Report, error) {
+	filename := fmt.Sprintf("report_%d_%s.pdf", patientID, time.Now().Format("20060102_150405"))
+	filePath, err := saveReportFile(patientID, filename, pdfBytes)
+	if err != nil {
+		return db.Report{}, fmt.Errorf("cannot save report file: %w", err)
+	}
+
+	recipientsJSON, err := json.Marshal([]ReportRecipient{})
+	if err != nil {
+		return db.Report{}, errors.New("cannot initialize recipients")
+	}
+
+	report, err := h.Queries.CreateReport(ctx, db.CreateReportParams{
+		PatientID:  patientID,
+		Filename:   filename,
+		FileUrl:    filePath,
+		Recipients: recipientsJSON,
+	})
+	if err != nil {
+		return db.Report{}, errors.New("cannot create report record")
+	}
+	return report, nil
+}
+
+// PatientStorageDir là thư mục chứa file PDF báo cáo của một bệnh nhân.
+func PatientStorageDir(patientID int64) string {
+	return filepath.Join(reportStorageDir, fmt.Sprintf("%d", patientID))
+}
+
 // saveReportFile lưu file PDF vào disk.
 // Cấu trúc: tmp/reports/{patient_id}/{filename}.pdf
 // Trả về file path để lưu vào database.
 func saveReportFile(patientID int64, filename string, content []byte) (string, error) {
-	dir := filepath.Join(reportStorageDir, fmt.Sprintf("%d", patientID))
+	dir := PatientStorageDir(patientID)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", err
 	}
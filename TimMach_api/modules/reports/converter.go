@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	db "chidinh/db/sqlc"
+	"chidinh/utils/pagination"
 )
 
 func mapReportResponse(r db.Report) (ReportResponse, error) {
@@ -22,6 +23,10 @@ func mapReportResponse(r db.Report) (ReportResponse, error) {
 	}, nil
 }
 
+func reportCursor(r db.Report) pagination.Cursor {
+	return pagination.Cursor{Time: r.CreatedAt.Time, ID: r.ID}
+}
+
 func decodeRecipients(raw []byte) ([]ReportRecipient, error) {
 	if len(raw) == 0 {
 		return []ReportRecipient{}, nil
@@ -32,3 +37,23 @@ func decodeRecipients(raw []byte) ([]ReportRecipient, error) {
 	}
 	return recipients, nil
 }
+
+func toSendReportEmailResponse(reportID int64, prepared reportEmail, queued []db.EmailOutbox, skipped []string) SendReportEmailResponse {
+	resp := SendReportEmailResponse{
+		Message:       "queued",
+		ReportID:      reportID,
+		PDFProtection: prepared.Protection,
+		PDFPassword:   prepared.Code,
+		Recipients:    make([]QueuedRecipient, 0, len(queued)),
+		Skipped:       skipped,
+	}
+	for _, e := range queued {
+		resp.Recipients = append(resp.Recipients, QueuedRecipient{
+			EmailID: e.ID,
+			Email:   e.ToAddress,
+			Role:    e.RecipientRole,
+			Status:  e.Status,
+		})
+	}
+	return resp
+}
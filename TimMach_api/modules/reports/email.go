@@ -0,0 +1,104 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/emails"
+	"chidinh/utils"
+	"chidinh/utils/mailer"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errProtectionRequired = errors.New("clinic requires password-protected report PDFs")
+
+// reportEmail là email báo cáo đã render; Code là mật khẩu dùng một lần (ProtectionCode),
+// chỉ trả về cho bác sĩ trong response, không lưu và không gửi kèm email.
+type reportEmail struct {
+	Message    mailer.Message
+	Protection string
+	Code       string
+}
+
+// buildReportEmail render template "report" theo branding phòng khám của user và đính kèm file báo cáo;
+// PDF được mã hoá theo req.PDFProtection (bỏ trống thì theo cấu hình phòng khám).
+func (h *Controller) buildReportEmail(
+	ctx context.Context,
+	userID string,
+	patient db.Patient,
+	reportDate time.Time,
+	recipients recipientList,
+	req SendReportEmailRequest,
+	attachment mailer.Attachment,
+) (reportEmail, error) {
+	branding, err := h.Brandings.ForUser(ctx, userID)
+	if err != nil {
+		return reportEmail{}, err
+	}
+	name, dob, err := utils.OpenPatient(patient.Name, patient.Dob)
+	if err != nil {
+		return reportEmail{}, err
+	}
+
+	out := reportEmail{Protection: resolvePDFProtection(req.PDFProtection, branding.PDFProtection)}
+	if out.Protection == emails.ProtectionNone && branding.PDFProtection != emails.ProtectionNone {
+		return reportEmail{}, errProtectionRequired
+	}
+
+	var password string
+	switch out.Protection {
+	case emails.ProtectionDOB:
+		password = dob.Format("02012006")
+	case emails.ProtectionCode:
+		if out.Code, err = oneTimeCode(); err != nil {
+			return reportEmail{}, err
+		}
+		password = out.Code
+	}
+	if password != "" {
+		if attachment.Content, err = EncryptPDF(attachment.Content, password); err != nil {
+			return reportEmail{}, err
+		}
+	}
+
+	email, err := emails.Render("report", req.Locale, branding, emails.ReportEmailData{
+		PatientName:   name,
+		ReportDate:    reportDate.Format("2006-01-02"),
+		Message:       strings.TrimSpace(req.Message),
+		PDFProtection: out.Protection,
+	})
+	if err != nil {
+		return reportEmail{}, err
+	}
+	if subject := strings.TrimSpace(req.Subject); subject != "" {
+		email.Subject = subject
+	}
+	out.Message = email.Message(attachment)
+	out.Message.To, out.Message.Cc, out.Message.Bcc = recipients.To, recipients.Cc, recipients.Bcc
+	return out, nil
+}
+
+// resolvePDFProtection: request bỏ trống thì dùng cấu hình phòng khám (mặc định none).
+func resolvePDFProtection(requested, clinicDefault string) string {
+	if requested != "" {
+		return requested
+	}
+	if clinicDefault != "" {
+		return clinicDefault
+	}
+	return emails.ProtectionNone
+}
+
+func respondReportEmailError(c *gin.Context, err error) {
+	if errors.Is(err, errProtectionRequired) {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	utils.RespondError(c, http.StatusInternalServerError, fmt.Sprintf("cannot render email: %v", err))
+}
@@ -0,0 +1,101 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"math/big"
+	"path/filepath"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var templateDir = filepath.Join("modules", "reports", "templates")
+
+// RenderPDF render HTML template (tên file trong modules/reports/templates) với data
+// rồi dùng chromedp để convert HTML → PDF.
+func RenderPDF(ctx context.Context, name string, data any) ([]byte, error) {
+	tpl, err := template.ParseFiles(filepath.Join(templateDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load template: %w", err)
+	}
+
+	var htmlBuf bytes.Buffer
+	if err := tpl.Execute(&htmlBuf, data); err != nil {
+		return nil, fmt.Errorf("cannot render template: %w", err)
+	}
+
+	// create context
+	ctx, cancel := chromedp.NewContext(ctx)
+	defer cancel()
+
+	var pdfBuf []byte
+	err = chromedp.Run(ctx,
+		chromedp.Navigate("about:blank"),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			frameTree, err := page.GetFrameTree().Do(ctx)
+			if err != nil {
+				return err
+			}
+			return page.SetDocumentContent(frameTree.Frame.ID, htmlBuf.String()).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			buf, _, err := page.PrintToPDF().
+				WithPrintBackground(true).
+				WithMarginTop(0.4).
+				WithMarginBottom(0.4).
+				WithMarginLeft(0.4).
+				WithMarginRight(0.4).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			pdfBuf = buf
+			return nil
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate pdf: %w", err)
+	}
+	return pdfBuf, nil
+}
+
+// oneTimeCodeDigits là độ dài mật khẩu dùng một lần của PDF.
+const oneTimeCodeDigits = 8
+
+func init() {
+	// pdfcpu mặc định đọc/ghi thư mục config trong $HOME; server không cần.
+	api.DisableConfigDir()
+}
+
+// EncryptPDF mã hoá PDF bằng AES-256 với mật khẩu mở file password; owner password ngẫu nhiên
+// nên không ai đổi được quyền (chỉ cho phép in).
+func EncryptPDF(pdf []byte, password string) ([]byte, error) {
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return nil, err
+	}
+	conf := model.NewAESConfiguration(password, hex.EncodeToString(owner), 256)
+
+	var out bytes.Buffer
+	if err := api.Encrypt(bytes.NewReader(pdf), &out, conf); err != nil {
+		return nil, fmt.Errorf("cannot encrypt pdf: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// oneTimeCode sinh mã số ngẫu nhiên oneTimeCodeDigits chữ số.
+func oneTimeCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(oneTimeCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", oneTimeCodeDigits, n), nil
+}
@@ -0,0 +1,106 @@
+package reports
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"chidinh/modules/emails"
+)
+
+// maxRecipients giới hạn tổng số địa chỉ to/cc/bcc của một lần gửi.
+const maxRecipients = 20
+
+// recipientList là người nhận đã kiểm tra và bỏ trùng; một địa chỉ xuất hiện ở nhiều vai trò
+// chỉ giữ vai trò đầu tiên theo thứ tự to > cc > bcc.
+type recipientList struct {
+	To  []string
+	Cc  []string
+	Bcc []string
+}
+
+func (r recipientList) count() int {
+	return len(r.To) + len(r.Cc) + len(r.Bcc)
+}
+
+// parseRecipients đọc to/cc/bcc (email cũ được tính là một địa chỉ to), kiểm tra từng địa chỉ bằng net/mail
+// và trả lỗi liệt kê mọi địa chỉ không hợp lệ.
+func parseRecipients(req SendReportEmailRequest) (recipientList, error) {
+	var legacy []string
+	if email := strings.TrimSpace(req.Email); email != "" {
+		legacy = []string{email}
+	}
+
+	var (
+		list    recipientList
+		invalid []string
+		seen    = make(map[string]struct{})
+	)
+	for _, group := range []struct {
+		field string
+		addrs []string
+		out   *[]string
+	}{
+		{"email", legacy, &list.To},
+		{emails.RoleTo, req.To, &list.To},
+		{emails.RoleCc, req.Cc, &list.Cc},
+		{emails.RoleBcc, req.Bcc, &list.Bcc},
+	} {
+		for i, raw := range group.addrs {
+			addr, err := mail.ParseAddress(strings.TrimSpace(raw))
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s[%d] %q", group.field, i, raw))
+				continue
+			}
+			key := strings.ToLower(addr.Address)
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			*group.out = append(*group.out, addr.Address)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return list, fmt.Errorf("invalid recipients: %s", strings.Join(invalid, ", "))
+	}
+	if list.count() == 0 {
+		return list, fmt.Errorf("at least one recipient is required")
+	}
+	if list.count() > maxRecipients {
+		return list, fmt.Errorf("too many recipients (max %d)", maxRecipients)
+	}
+	return list, nil
+}
+
+// withoutPrevious bỏ các địa chỉ đã có trong lịch sử gửi của báo cáo với trạng thái queued/sending/sent
+// (failed/bounced vẫn được gửi lại), trả về danh sách còn lại và các địa chỉ bị bỏ qua.
+func (r recipientList) withoutPrevious(history []ReportRecipient) (recipientList, []string) {
+	delivered := make(map[string]struct{}, len(history))
+	for _, h := range history {
+		switch h.Status {
+		case emails.StatusQueued, emails.StatusSending, emails.StatusSent:
+			delivered[strings.ToLower(h.Email)] = struct{}{}
+		}
+	}
+
+	var (
+		out     recipientList
+		skipped []string
+	)
+	filter := func(addrs []string) []string {
+		var kept []string
+		for _, addr := range addrs {
+			if _, ok := delivered[strings.ToLower(addr)]; ok {
+				skipped = append(skipped, addr)
+				continue
+			}
+			kept = append(kept, addr)
+		}
+		return kept
+	}
+	out.To = filter(r.To)
+	out.Cc = filter(r.Cc)
+	out.Bcc = filter(r.Bcc)
+	return out, skipped
+}
@@ -0,0 +1,83 @@
+package reports
+
+import (
+	"context"
+	"errors"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/emails"
+	"chidinh/utils/mailer"
+)
+
+// ErrScheduledCodeProtection: phòng khám yêu cầu mã dùng một lần (ProtectionCode) mà lần gửi theo lịch không có
+// bác sĩ để báo mã cho bệnh nhân, nên lịch thất bại thay vì tự hạ xuống mật khẩu yếu hơn.
+var ErrScheduledCodeProtection = errors.New("clinic requires one-time code PDF protection, which scheduled reports cannot deliver; use dob protection or send the report manually")
+
+// ScheduledReportOptions là người nhận và nội dung email của một lần gửi báo cáo theo lịch (modules/schedules).
+type ScheduledReportOptions struct {
+	To      []string
+	Cc      []string
+	Locale  string
+	Message string
+}
+
+// ScheduledReport là kết quả gửi báo cáo theo lịch; Report có thể đã được lưu dù bước đưa email vào outbox lỗi.
+type ScheduledReport struct {
+	Report db.Report
+	Emails []db.EmailOutbox
+}
+
+// NormalizeRecipients kiểm tra và bỏ trùng to/cc theo cùng quy tắc với POST /reports/:id/email.
+func NormalizeRecipients(to, cc []string) ([]string, []string, error) {
+	list, err := parseRecipients(SendReportEmailRequest{To: to, Cc: cc})
+	return list.To, list.Cc, err
+}
+
+// SendScheduledReport tạo báo cáo PDF (buildPatientReportPDF), lưu như POST /patients/:id/reports
+// rồi đưa email vào outbox như POST /reports/:id/email.
+// Phòng khám cấu hình code thì trả ErrScheduledCodeProtection trước khi tạo báo cáo.
+func (h *Controller) SendScheduledReport(ctx context.Context, userID string, patientID int64, opts ScheduledReportOptions) (ScheduledReport, error) {
+	var out ScheduledReport
+
+	recipients, err := parseRecipients(SendReportEmailRequest{To: opts.To, Cc: opts.Cc})
+	if err != nil {
+		return out, err
+	}
+	patient, err := h.getOwnedPatient(ctx, userID, patientID)
+	if err != nil {
+		return out, err
+	}
+	branding, err := h.Brandings.ForUser(ctx, userID)
+	if err != nil {
+		return out, err
+	}
+	if branding.PDFProtection == emails.ProtectionCode {
+		return out, ErrScheduledCodeProtection
+	}
+
+	_, pdfBytes, err := h.buildPatientReportPDF(ctx, userID, patientID)
+	if err != nil {
+		return out, err
+	}
+	if out.Report, err = h.storeReport(ctx, patientID, pdfBytes); err != nil {
+		return out, err
+	}
+
+	req := SendReportEmailRequest{Message: opts.Message, Locale: opts.Locale}
+	prepared, err := h.buildReportEmail(ctx, userID, patient, out.Report.CreatedAt.Time, recipients, req, mailer.Attachment{
+		Filename: out.Report.Filename,
+		MimeType: "application/pdf",
+		Content:  pdfBytes,
+	})
+	if err != nil {
+		return out, err
+	}
+
+	out.Emails, err = h.Outbox.Enqueue(ctx, prepared.Message, emails.EnqueueOptions{
+		UserID:        userID,
+		PatientID:     &patient.ID,
+		ReportID:      &out.Report.ID,
+		PDFProtection: prepared.Protection,
+	})
+	return out, err
+}
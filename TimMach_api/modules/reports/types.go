@@ -1,22 +1,67 @@
 package reports
 
-import "time"
+import (
+	"time"
 
+	"chidinh/utils/pagination"
+)
+
+// SendReportEmailRequest: người nhận gồm to/cc/bcc (email là trường cũ, tương đương một địa chỉ to);
+// với báo cáo đã lưu, địa chỉ đã nhận (queued/sending/sent) bị bỏ qua trừ khi resend = true.
+// subject bỏ trống thì dùng subject của template; message là lời nhắn thêm hiển thị trong email;
+// locale (vi/en) bỏ trống thì theo ngôn ngữ mặc định của phòng khám.
+// pdf_protection (none | dob | code) bỏ trống thì theo cấu hình phòng khám; không được chọn none
+// khi phòng khám yêu cầu mã hoá.
 type SendReportEmailRequest struct {
-	Email   string `json:"email" binding:"omitempty,email"`
-	Subject string `json:"subject"`
-	Message string `json:"message"`
+	Email   string   `json:"email"`
+	To      []string `json:"to"`
+	Cc      []string `json:"cc"`
+	Bcc     []string `json:"bcc"`
+	Resend  bool     `json:"resend"`
+	Subject string   `json:"subject"`
+	Message string   `json:"message"`
+	Locale  string   `json:"locale"`
+
+	PDFProtection string `json:"pdf_protection" binding:"omitempty,oneof=none dob code"`
+}
+
+// QueuedRecipient là một địa chỉ đã được đưa vào outbox; theo dõi bằng GET /email-outbox/:email_id.
+type QueuedRecipient struct {
+	EmailID int64  `json:"email_id"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Status  string `json:"status"`
+}
+
+// SendReportEmailResponse: PDFPassword chỉ có khi pdf_protection = code, là mật khẩu dùng một lần
+// để bác sĩ báo cho bệnh nhân qua kênh riêng; hệ thống không lưu lại mã này.
+type SendReportEmailResponse struct {
+	Message       string            `json:"message"`
+	ReportID      int64             `json:"report_id,omitempty"`
+	PDFProtection string            `json:"pdf_protection"`
+	PDFPassword   string            `json:"pdf_password,omitempty"`
+	Recipients    []QueuedRecipient `json:"recipients"`
+	Skipped       []string          `json:"skipped,omitempty"` // đã nhận báo cáo trước đó
 }
 
+// ListReportsRequest: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at.
 type ListReportsRequest struct {
-	Limit  int32 `form:"limit" binding:"omitempty,min=1,max=100"`
-	Offset int32 `form:"offset" binding:"omitempty,min=0"`
+	pagination.Params
 }
 
+// ReportRecipient là một lần gửi báo cáo; Status đồng bộ với email_outbox (queued | sending | sent | failed | bounced)
+// mỗi khi job gửi email ghi kết quả. Bản ghi cũ (trước outbox) không có OutboxID.
 type ReportRecipient struct {
-	Email  string    `json:"email"`
-	SentAt time.Time `json:"sent_at"`
-	Status string    `json:"status"`
+	Email    string     `json:"email"`
+	Role     string     `json:"role,omitempty"` // to | cc | bcc
+	Status   string     `json:"status"`
+	OutboxID int64      `json:"outbox_id,omitempty"`
+	QueuedAt *time.Time `json:"queued_at,omitempty"`
+	SentAt   *time.Time `json:"sent_at,omitempty"`
+	Attempts int32      `json:"attempts,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	// PDFProtection: none | dob | code; bản ghi trước khi có mã hoá PDF không có trường này.
+	PDFProtection string `json:"pdf_protection,omitempty"`
 }
 
 type ReportResponse struct {
@@ -30,5 +75,5 @@ type ReportResponse struct {
 
 type ListReportsResponse struct {
 	Reports []ReportResponse `json:"reports"`
-	Total   int64            `json:"total"`
+	pagination.Meta
 }
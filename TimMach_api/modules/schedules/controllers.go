@@ -0,0 +1,279 @@
+package schedules
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+	"chidinh/utils/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Controller gom dependencies cho API quản lý lịch gửi định kỳ; việc chạy lịch nằm ở Scheduler.
+type Controller struct {
+	Queries *db.Queries
+}
+
+func NewController(q *db.Queries) *Controller {
+	return &Controller{Queries: q}
+}
+
+// POST /report-schedules
+func (h *Controller) CreateSchedule(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.PatientID != nil && !h.ownedPatient(c, userID, *req.PatientID) {
+		return
+	}
+
+	fields, err := normalizeSchedule(req, nil, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	schedule, err := h.Queries.CreateReportSchedule(c, db.CreateReportScheduleParams{
+		UserID:         userID,
+		PatientID:      req.PatientID,
+		Kind:           req.Kind,
+		Cron:           fields.Cron,
+		ToAddresses:    fields.To,
+		CcAddresses:    fields.Cc,
+		Locale:         fields.Locale,
+		Message:        fields.Message,
+		InactiveMonths: fields.InactiveMonths,
+		Enabled:        fields.Enabled,
+		NextRunAt:      pgtype.Timestamptz{Time: fields.NextRunAt, Valid: true},
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot create schedule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toScheduleResponse(schedule))
+}
+
+// GET /report-schedules?patient_id=&limit=&cursor=&sort=-created_at
+func (h *Controller) ListSchedules(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req ListSchedulesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	pager, err := pagination.Parse(req.Params, []string{"created_at"}, "-created_at")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := db.ListReportSchedulesByUserDescParams{
+		UserID:          userID,
+		PatientID:       req.PatientID,
+		CursorID:        pager.CursorID(),
+		CursorCreatedAt: pager.CursorTime(),
+		Limit:           pager.FetchLimit(),
+	}
+	var items []db.ReportSchedule
+	if pager.Desc {
+		items, err = h.Queries.ListReportSchedulesByUserDesc(c, params)
+	} else {
+		items, err = h.Queries.ListReportSchedulesByUserAsc(c, db.ListReportSchedulesByUserAscParams(params))
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list schedules")
+		return
+	}
+
+	items, meta := pagination.Trim(items, pager, scheduleCursor)
+	resp := ListSchedulesResponse{Schedules: make([]ScheduleResponse, 0, len(items)), Meta: meta}
+	for _, item := range items {
+		resp.Schedules = append(resp.Schedules, toScheduleResponse(item))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /report-schedules/:id
+func (h *Controller) GetSchedule(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	schedule, ok := h.ownedSchedule(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduleResponse(schedule))
+}
+
+// PUT /report-schedules/:id
+// Thay toàn bộ cấu hình (trừ kind/patient_id) và tính lại next_run_at từ thời điểm hiện tại.
+func (h *Controller) UpdateSchedule(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	schedule, ok := h.ownedSchedule(c, userID)
+	if !ok {
+		return
+	}
+
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	fields, err := normalizeSchedule(req, &schedule, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	updated, err := h.Queries.UpdateReportSchedule(c, db.UpdateReportScheduleParams{
+		ID:             schedule.ID,
+		Cron:           fields.Cron,
+		ToAddresses:    fields.To,
+		CcAddresses:    fields.Cc,
+		Locale:         fields.Locale,
+		Message:        fields.Message,
+		InactiveMonths: fields.InactiveMonths,
+		Enabled:        fields.Enabled,
+		NextRunAt:      pgtype.Timestamptz{Time: fields.NextRunAt, Valid: true},
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot update schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, toScheduleResponse(updated))
+}
+
+// DELETE /report-schedules/:id
+// Xoá lịch cùng lịch sử chạy; báo cáo và email đã tạo vẫn được giữ.
+func (h *Controller) DeleteSchedule(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	schedule, ok := h.ownedSchedule(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.Queries.DeleteReportSchedule(c, schedule.ID); err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot delete schedule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GET /report-schedules/:id/runs?limit=20
+// Các lần chạy gần nhất với kết quả (report đã tạo, email trong outbox, lỗi).
+func (h *Controller) ListRuns(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	schedule, ok := h.ownedSchedule(c, userID)
+	if !ok {
+		return
+	}
+
+	var req ListRunsParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultRunsLimit
+	}
+
+	runs, err := h.Queries.ListReportScheduleRuns(c, db.ListReportScheduleRunsParams{
+		ScheduleID: schedule.ID,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot list schedule runs")
+		return
+	}
+
+	resp := ListRunsResponse{Runs: make([]ScheduleRunResponse, 0, len(runs))}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, toRunResponse(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ownedSchedule đọc :id và kiểm tra lịch thuộc user; đã trả lỗi nếu ok = false.
+func (h *Controller) ownedSchedule(c *gin.Context, userID string) (db.ReportSchedule, bool) {
+	scheduleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid schedule id")
+		return db.ReportSchedule{}, false
+	}
+
+	schedule, err := h.Queries.GetReportScheduleByID(c, scheduleID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "schedule not found")
+		return schedule, false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch schedule")
+		return schedule, false
+	}
+	if schedule.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "schedule does not belong to user")
+		return schedule, false
+	}
+	return schedule, true
+}
+
+// ownedPatient kiểm tra bệnh nhân (chưa xoá) thuộc user; đã trả lỗi nếu false.
+func (h *Controller) ownedPatient(c *gin.Context, userID string, patientID int64) bool {
+	patient, err := h.Queries.GetPatientByID(c, patientID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusNotFound, "patient not found")
+		return false
+	}
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot fetch patient")
+		return false
+	}
+	if patient.UserID != userID {
+		utils.RespondError(c, http.StatusForbidden, "patient does not belong to user")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package schedules
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/reports"
+	"chidinh/utils/pagination"
+)
+
+// scheduleFields là phần cấu hình lịch đã kiểm tra, dùng chung cho tạo mới và cập nhật.
+type scheduleFields struct {
+	Cron           string
+	To             []string
+	Cc             []string
+	Locale         string
+	Message        string
+	InactiveMonths int32
+	Enabled        bool
+	NextRunAt      time.Time
+}
+
+// normalizeSchedule kiểm tra request; current khác nil khi cập nhật (kind/patient_id phải giữ nguyên,
+// enabled bỏ trống thì giữ giá trị cũ). next_run_at luôn được tính lại từ now theo cron.
+func normalizeSchedule(req ScheduleRequest, current *db.ReportSchedule, now time.Time) (scheduleFields, error) {
+	var out scheduleFields
+
+	if current != nil {
+		if req.Kind != current.Kind {
+			return out, errors.New("kind cannot be changed")
+		}
+		if req.PatientID != nil && (current.PatientID == nil || *req.PatientID != *current.PatientID) {
+			return out, errors.New("patient_id cannot be changed")
+		}
+	}
+	patientID := req.PatientID
+	if current != nil {
+		patientID = current.PatientID
+	}
+	if req.Kind == KindReport && patientID == nil {
+		return out, errors.New("patient_id is required for report schedules")
+	}
+
+	out.Cron = strings.TrimSpace(req.Cron)
+	next, err := validateCron(out.Cron, now)
+	if err != nil {
+		return out, err
+	}
+	out.NextRunAt = next
+
+	if len(req.To)+len(req.Cc) > 0 {
+		if out.To, out.Cc, err = reports.NormalizeRecipients(req.To, req.Cc); err != nil {
+			return out, err
+		}
+	}
+	if req.Kind == KindReport && len(out.To) == 0 {
+		return out, errors.New("to is required for report schedules")
+	}
+	out.To, out.Cc = nonNil(out.To), nonNil(out.Cc)
+
+	out.Locale = req.Locale
+	out.Message = strings.TrimSpace(req.Message)
+	out.InactiveMonths = req.InactiveMonths
+	if out.InactiveMonths <= 0 {
+		out.InactiveMonths = defaultInactiveMonths
+	}
+	out.Enabled = true
+	if current != nil {
+		out.Enabled = current.Enabled
+	}
+	if req.Enabled != nil {
+		out.Enabled = *req.Enabled
+	}
+	return out, nil
+}
+
+func toScheduleResponse(s db.ReportSchedule) ScheduleResponse {
+	resp := ScheduleResponse{
+		ID:             s.ID,
+		PatientID:      s.PatientID,
+		Kind:           s.Kind,
+		Cron:           s.Cron,
+		To:             nonNil(s.ToAddresses),
+		Cc:             nonNil(s.CcAddresses),
+		Locale:         s.Locale,
+		Message:        s.Message,
+		InactiveMonths: s.InactiveMonths,
+		Enabled:        s.Enabled,
+		NextRunAt:      s.NextRunAt.Time,
+		CreatedAt:      s.CreatedAt.Time,
+		UpdatedAt:      s.UpdatedAt.Time,
+	}
+	if s.LastRunAt.Valid {
+		t := s.LastRunAt.Time
+		resp.LastRunAt = &t
+	}
+	return resp
+}
+
+func toRunResponse(r db.ReportScheduleRun) ScheduleRunResponse {
+	resp := ScheduleRunResponse{
+		ID:           r.ID,
+		ScheduleID:   r.ScheduleID,
+		ScheduledFor: r.ScheduledFor.Time,
+		Status:       r.Status,
+		ReportID:     r.ReportID,
+		EmailIDs:     r.EmailIds,
+		PatientIDs:   r.PatientIds,
+		Detail:       r.Detail,
+		StartedAt:    r.StartedAt.Time,
+	}
+	if resp.EmailIDs == nil {
+		resp.EmailIDs = []int64{}
+	}
+	if resp.PatientIDs == nil {
+		resp.PatientIDs = []int64{}
+	}
+	if r.FinishedAt.Valid {
+		t := r.FinishedAt.Time
+		resp.FinishedAt = &t
+	}
+	return resp
+}
+
+func scheduleCursor(s db.ReportSchedule) pagination.Cursor {
+	return pagination.Cursor{Time: s.CreatedAt.Time, ID: s.ID}
+}
+
+// nonNil tránh ghi NULL vào cột TEXT[] NOT NULL và trả [] thay vì null trong JSON.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// intervalSamples là số mốc liên tiếp được xét khi kiểm tra khoảng cách tối thiểu giữa hai lần chạy.
+const intervalSamples = 24
+
+// nextRun trả về mốc chạy kế tiếp (sau after) của biểu thức cron.
+func nextRun(expr string, after time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	next := sched.Next(after)
+	if next.IsZero() {
+		return time.Time{}, errors.New("cron expression never fires")
+	}
+	return next, nil
+}
+
+// validateCron kiểm tra biểu thức cron và các mốc sắp tới không cách nhau dưới minScheduleInterval,
+// trả về mốc chạy đầu tiên sau now.
+func validateCron(expr string, now time.Time) (time.Time, error) {
+	sched, err := cron.ParseStandard(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	first := sched.Next(now)
+	if first.IsZero() {
+		return time.Time{}, errors.New("cron expression never fires")
+	}
+	prev := first
+	for range intervalSamples {
+		next := sched.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(prev) < minScheduleInterval {
+			return time.Time{}, fmt.Errorf("schedule must not run more often than every %s", minScheduleInterval)
+		}
+		prev = next
+	}
+	return first, nil
+}
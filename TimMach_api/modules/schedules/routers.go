@@ -0,0 +1,14 @@
+package schedules
+
+import "github.com/gin-gonic/gin"
+
+// RegisterScheduleRoutes gắn endpoint quản lý lịch gửi báo cáo/nhắc tái khám định kỳ (cần auth).
+func RegisterScheduleRoutes(r *gin.RouterGroup, h *Controller) {
+	group := r.Group("/report-schedules")
+	group.GET("", h.ListSchedules)
+	group.POST("", h.CreateSchedule)
+	group.GET("/:id", h.GetSchedule)
+	group.PUT("/:id", h.UpdateSchedule)
+	group.DELETE("/:id", h.DeleteSchedule)
+	group.GET("/:id/runs", h.ListRuns)
+}
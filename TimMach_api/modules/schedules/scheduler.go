@@ -0,0 +1,298 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/modules/emails"
+	"chidinh/modules/reports"
+	"chidinh/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	dueBatchSize = 20
+	// maxReminderPatients giới hạn số bệnh nhân liệt kê trong một email nhắc.
+	maxReminderPatients = 100
+	// fallbackDelay dời lịch có cron không còn hợp lệ để job không chạy lại liên tục.
+	fallbackDelay = 24 * time.Hour
+)
+
+// Scheduler chạy các lịch đến hạn trong report_schedules: report tạo báo cáo PDF qua module reports,
+// reminder gửi email nhắc tái khám; email đều đi qua Outbox.
+// Nhiều replica chạy song song an toàn: mỗi lịch được giữ bằng advisory lock (pg_try_advisory_lock) trên
+// một connection riêng trong lúc chạy, và StartReportScheduleRun chỉ nhận mỗi mốc một lần.
+// Bỏ lỡ nhiều mốc (server dừng) thì chỉ chạy bù một lần rồi tiếp tục từ mốc kế tiếp sau hiện tại.
+type Scheduler struct {
+	Pool      *pgxpool.Pool
+	Queries   *db.Queries
+	Reports   *reports.Controller
+	Outbox    *emails.Outbox
+	Brandings *emails.Brandings
+}
+
+func NewScheduler(pool *pgxpool.Pool, queries *db.Queries, reportController *reports.Controller, outbox *emails.Outbox, brandings *emails.Brandings) *Scheduler {
+	return &Scheduler{Pool: pool, Queries: queries, Reports: reportController, Outbox: outbox, Brandings: brandings}
+}
+
+// Run chạy RunDue mỗi interval cho tới khi ctx bị huỷ.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.RunDue(ctx); err != nil {
+			utils.L().Warnw("run report schedules failed", "error", err, "ran", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue chạy tối đa dueBatchSize lịch đến hạn, trả về số lịch đã chạy ở replica này.
+// Lịch đang bị replica khác giữ lock được bỏ qua và xét lại ở lượt sau. Lịch lỗi (vd. không ghi được run)
+// được log rồi bỏ qua để các lịch còn lại trong batch vẫn chạy; lỗi tổng hợp được trả về để Run cảnh báo.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	ids, err := s.Queries.ListDueReportSchedules(ctx, dueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	ran, failed := 0, 0
+	for _, id := range ids {
+		ok, err := s.runLocked(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ran, ctx.Err()
+			}
+			utils.L().Warnw("run report schedule failed", "error", err, "schedule_id", id)
+			failed++
+			continue
+		}
+		if ok {
+			ran++
+		}
+	}
+	if failed > 0 {
+		return ran, fmt.Errorf("%d report schedules failed", failed)
+	}
+	return ran, nil
+}
+
+// runLocked giữ advisory lock của lịch trên một connection riêng, nhận mốc đến hạn và ghi kết quả run.
+// Trả về false nếu lịch đang bị giữ, không còn đến hạn hoặc mốc đã được xử lý.
+func (s *Scheduler) runLocked(ctx context.Context, id int64) (bool, error) {
+	conn, err := s.Pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+	q := db.New(conn)
+
+	locked, err := q.TryLockReportSchedule(ctx, id)
+	if err != nil || !locked {
+		return false, err
+	}
+	defer func() {
+		if _, err := q.UnlockReportSchedule(context.Background(), id); err != nil {
+			// Không trả connection còn giữ lock về pool.
+			utils.L().Warnw("unlock report schedule failed", "error", err, "schedule_id", id)
+			_ = conn.Conn().Close(context.Background())
+		}
+	}()
+
+	schedule, err := q.GetReportScheduleByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if !schedule.Enabled || schedule.NextRunAt.Time.After(now) {
+		return false, nil
+	}
+
+	next, cronErr := nextRun(schedule.Cron, now)
+	if cronErr != nil {
+		next = now.Add(fallbackDelay)
+	}
+	run, err := q.StartReportScheduleRun(ctx, db.StartReportScheduleRunParams{
+		NextRunAt:    pgtype.Timestamptz{Time: next, Valid: true},
+		ScheduleID:   schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	result := runResult{Status: RunFailed}
+	if cronErr != nil {
+		result.Detail = cronErr.Error()
+	} else {
+		result = s.execute(ctx, schedule)
+	}
+	if result.Status == RunFailed {
+		utils.L().Warnw("report schedule run failed", "schedule_id", schedule.ID, "run_id", run.ID, "detail", result.Detail)
+	}
+
+	if _, err := q.FinishReportScheduleRun(ctx, db.FinishReportScheduleRunParams{
+		Status:     result.Status,
+		ReportID:   result.ReportID,
+		EmailIds:   nonNilIDs(result.EmailIDs),
+		PatientIds: nonNilIDs(result.PatientIDs),
+		Detail:     result.Detail,
+		ID:         run.ID,
+	}); err != nil {
+		return true, fmt.Errorf("record schedule %d run: %w", schedule.ID, err)
+	}
+	return true, nil
+}
+
+// runResult là kết quả một lần chạy, ghi vào report_schedule_runs.
+type runResult struct {
+	Status     string
+	ReportID   *int64
+	EmailIDs   []int64
+	PatientIDs []int64
+	Detail     string
+}
+
+func (s *Scheduler) execute(ctx context.Context, schedule db.ReportSchedule) runResult {
+	switch schedule.Kind {
+	case KindReport:
+		return s.runReport(ctx, schedule)
+	case KindReminder:
+		return s.runReminder(ctx, schedule)
+	default:
+		return runResult{Status: RunFailed, Detail: fmt.Sprintf("unknown schedule kind %q", schedule.Kind)}
+	}
+}
+
+// runReport tạo và gửi báo cáo mới của bệnh nhân; bệnh nhân đã bị xoá thì bỏ qua.
+func (s *Scheduler) runReport(ctx context.Context, schedule db.ReportSchedule) runResult {
+	if schedule.PatientID == nil {
+		return runResult{Status: RunFailed, Detail: "report schedule has no patient"}
+	}
+
+	sent, err := s.Reports.SendScheduledReport(ctx, schedule.UserID, *schedule.PatientID, reports.ScheduledReportOptions{
+		To:      schedule.ToAddresses,
+		Cc:      schedule.CcAddresses,
+		Locale:  schedule.Locale,
+		Message: schedule.Message,
+	})
+	result := runResult{PatientIDs: []int64{*schedule.PatientID}}
+	if sent.Report.ID != 0 {
+		result.ReportID = &sent.Report.ID
+	}
+	for _, e := range sent.Emails {
+		result.EmailIDs = append(result.EmailIDs, e.ID)
+	}
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		result.Status, result.Detail = RunSkipped, "patient not found"
+	case err != nil:
+		result.Status, result.Detail = RunFailed, err.Error()
+	default:
+		result.Status = RunSucceeded
+	}
+	return result
+}
+
+// runReminder gửi một email liệt kê các bệnh nhân chưa có prediction trong inactive_months tháng;
+// không có ai thì bỏ qua. to rỗng thì gửi cho email của user sở hữu lịch.
+func (s *Scheduler) runReminder(ctx context.Context, schedule db.ReportSchedule) runResult {
+	months := schedule.InactiveMonths
+	if months <= 0 {
+		months = defaultInactiveMonths
+	}
+	patients, err := s.Queries.ListInactivePatients(ctx, db.ListInactivePatientsParams{
+		UserID:    schedule.UserID,
+		PatientID: schedule.PatientID,
+		Before:    pgtype.Timestamptz{Time: time.Now().AddDate(0, -int(months), 0), Valid: true},
+		Limit:     maxReminderPatients + 1,
+	})
+	if err != nil {
+		return runResult{Status: RunFailed, Detail: err.Error()}
+	}
+	if len(patients) == 0 {
+		return runResult{Status: RunSkipped, Detail: "no inactive patients"}
+	}
+
+	data := emails.ReminderEmailData{InactiveMonths: int(months), Message: schedule.Message}
+	if len(patients) > maxReminderPatients {
+		patients, data.Truncated = patients[:maxReminderPatients], true
+	}
+	result := runResult{Status: RunFailed}
+	for _, p := range patients {
+		name, _, err := utils.OpenPatient(p.Name, p.Dob)
+		if err != nil {
+			result.Detail = fmt.Sprintf("cannot decrypt patient %d: %v", p.ID, err)
+			return result
+		}
+		item := emails.ReminderPatient{Name: name}
+		if p.LastPredictionAt.Valid {
+			item.LastPredictionAt = p.LastPredictionAt.Time.Format("2006-01-02")
+		}
+		data.Patients = append(data.Patients, item)
+		result.PatientIDs = append(result.PatientIDs, p.ID)
+	}
+
+	to := schedule.ToAddresses
+	if len(to) == 0 {
+		user, err := s.Queries.GetUserByID(ctx, schedule.UserID)
+		if err != nil {
+			result.Detail = fmt.Sprintf("cannot fetch user email: %v", err)
+			return result
+		}
+		to = []string{user.Email}
+	}
+
+	branding, err := s.Brandings.ForUser(ctx, schedule.UserID)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	email, err := emails.Render("reminder", schedule.Locale, branding, data)
+	if err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	msg := email.Message()
+	msg.To, msg.Cc = to, schedule.CcAddresses
+
+	queued, err := s.Outbox.Enqueue(ctx, msg, emails.EnqueueOptions{
+		UserID:    schedule.UserID,
+		PatientID: schedule.PatientID,
+	})
+	if err != nil {
+		result.Detail = fmt.Sprintf("cannot queue email: %v", err)
+		return result
+	}
+	for _, e := range queued {
+		result.EmailIDs = append(result.EmailIDs, e.ID)
+	}
+	result.Status = RunSucceeded
+	return result
+}
+
+// nonNilIDs tránh ghi NULL vào cột BIGINT[] NOT NULL.
+func nonNilIDs(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
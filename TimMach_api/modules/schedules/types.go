@@ -0,0 +1,99 @@
+package schedules
+
+import (
+	"time"
+
+	"chidinh/utils/pagination"
+)
+
+// Loại lịch (report_schedules.kind).
+const (
+	KindReport   = "report"
+	KindReminder = "reminder"
+)
+
+// Trạng thái một lần chạy (report_schedule_runs.status).
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunSkipped   = "skipped"
+	RunFailed    = "failed"
+)
+
+const (
+	defaultInactiveMonths = 3
+	// minScheduleInterval: lịch chạy dày hơn mức này bị từ chối để tránh spam email bệnh nhân.
+	minScheduleInterval = time.Hour
+	defaultRunsLimit    = 20
+)
+
+// ScheduleRequest tạo/cập nhật lịch gửi định kỳ.
+// kind = report: gửi báo cáo PDF mới của patient_id (bắt buộc) tới to/cc (bắt buộc có to).
+// kind = reminder: nhắc khi bệnh nhân chưa có prediction trong inactive_months tháng (mặc định 3);
+// patient_id bỏ trống = mọi bệnh nhân của user, to bỏ trống = gửi cho email của user.
+// cron: 5 trường (phút giờ ngày tháng thứ) hoặc @daily/@weekly/@monthly, giờ theo server trừ khi có
+// tiền tố "CRON_TZ=Asia/Ho_Chi_Minh "; không được chạy dày hơn mỗi giờ.
+// Khi cập nhật, kind và patient_id không đổi được (tạo lịch mới thay thế).
+type ScheduleRequest struct {
+	PatientID      *int64   `json:"patient_id"`
+	Kind           string   `json:"kind" binding:"required,oneof=report reminder"`
+	Cron           string   `json:"cron" binding:"required,max=100"`
+	To             []string `json:"to"`
+	Cc             []string `json:"cc"`
+	Locale         string   `json:"locale" binding:"omitempty,oneof=vi en"`
+	Message        string   `json:"message" binding:"max=2000"`
+	InactiveMonths int32    `json:"inactive_months" binding:"omitempty,min=1,max=60"`
+	Enabled        *bool    `json:"enabled"` // mặc định true
+}
+
+type ScheduleResponse struct {
+	ID             int64      `json:"id"`
+	PatientID      *int64     `json:"patient_id"`
+	Kind           string     `json:"kind"`
+	Cron           string     `json:"cron"`
+	To             []string   `json:"to"`
+	Cc             []string   `json:"cc"`
+	Locale         string     `json:"locale,omitempty"`
+	Message        string     `json:"message,omitempty"`
+	InactiveMonths int32      `json:"inactive_months"`
+	Enabled        bool       `json:"enabled"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ListSchedulesParams: sort = created_at (tiền tố "-" là giảm dần), mặc định -created_at; patient_id lọc theo bệnh nhân.
+type ListSchedulesParams struct {
+	pagination.Params
+	PatientID *int64 `form:"patient_id"`
+}
+
+type ListSchedulesResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+	pagination.Meta
+}
+
+// ScheduleRunResponse là một lần chạy; EmailIDs theo dõi bằng GET /email-outbox/:id,
+// PatientIDs là bệnh nhân được gửi báo cáo/nhắc, Detail là lỗi hoặc lý do bỏ qua.
+type ScheduleRunResponse struct {
+	ID           int64      `json:"id"`
+	ScheduleID   int64      `json:"schedule_id"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	Status       string     `json:"status"`
+	ReportID     *int64     `json:"report_id,omitempty"`
+	EmailIDs     []int64    `json:"email_ids"`
+	PatientIDs   []int64    `json:"patient_ids"`
+	Detail       string     `json:"detail,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// ListRunsParams: các lần chạy mới nhất trước.
+type ListRunsParams struct {
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type ListRunsResponse struct {
+	Runs []ScheduleRunResponse `json:"runs"`
+}
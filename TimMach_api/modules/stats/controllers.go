@@ -1,12 +1,16 @@
 package stats
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	db "chidinh/db/sqlc"
 	"chidinh/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,6 +24,8 @@ func NewController(q *db.Queries) *Controller {
 }
 
 // GET /stats
+// Đọc rollup đã tính sẵn; nếu user chưa có rollup hoặc dữ liệu vừa thay đổi thì tính lại ngay
+// để dashboard không hiển thị số cũ trong lúc chờ job nền.
 func (h *Controller) GetStats(c *gin.Context) {
 	userID, ok := utils.UserIDFromContext(c)
 	if !ok {
@@ -27,34 +33,133 @@ func (h *Controller) GetStats(c *gin.Context) {
 		return
 	}
 
-	var totalPatients int64
-	var totalPredictions int64
-	var riskDist []db.GetRiskDistributionRow
+	rollup, err := h.Queries.GetUserStatsRollup(c, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot query stats")
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) || rollup.Dirty {
+		fresh, err := h.Queries.RefreshUserStatsRollup(c, userID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, "cannot query stats")
+			return
+		}
+		c.JSON(http.StatusOK, buildStatsResponse(fresh))
+		return
+	}
+
+	c.JSON(http.StatusOK, buildStatsResponse(rollupFromRow(rollup)))
+}
+
+// GET /stats/timeseries
+func (h *Controller) GetTimeseries(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req TimeseriesParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	w, err := parseTimeseries(req, time.Now())
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fromTime := pgtype.Timestamptz{Time: w.From, Valid: true}
+	toTime := pgtype.Timestamptz{Time: w.To, Valid: true}
+	tz := w.Loc.String()
+
+	var patientRows []db.GetNewPatientsTimeseriesRow
+	var predictionRows []db.GetPredictionsTimeseriesRow
+	var riskRows []db.GetRiskTimeseriesRow
 
 	g, ctx := errgroup.WithContext(c)
 
 	g.Go(func() error {
 		var err error
-		totalPatients, err = h.Queries.GetTotalPatients(ctx, userID)
+		patientRows, err = h.Queries.GetNewPatientsTimeseries(ctx, db.GetNewPatientsTimeseriesParams{
+			Bucket: w.Bucket, Tz: tz, UserID: userID, FromTime: fromTime, ToTime: toTime,
+		})
 		return err
 	})
 
 	g.Go(func() error {
 		var err error
-		totalPredictions, err = h.Queries.GetTotalPredictions(ctx, userID)
+		predictionRows, err = h.Queries.GetPredictionsTimeseries(ctx, db.GetPredictionsTimeseriesParams{
+			Bucket: w.Bucket, Tz: tz, UserID: userID, FromTime: fromTime, ToTime: toTime,
+		})
 		return err
 	})
 
 	g.Go(func() error {
 		var err error
-		riskDist, err = h.Queries.GetRiskDistribution(ctx, userID)
+		riskRows, err = h.Queries.GetRiskTimeseries(ctx, db.GetRiskTimeseriesParams{
+			Bucket: w.Bucket, Tz: tz, UserID: userID, FromTime: fromTime, ToTime: toTime,
+		})
 		return err
 	})
 
 	if err := g.Wait(); err != nil {
-		utils.RespondError(c, http.StatusInternalServerError, "cannot query stats")
+		utils.RespondError(c, http.StatusInternalServerError, "cannot query stats timeseries")
+		return
+	}
+
+	c.JSON(http.StatusOK, buildTimeseriesResponse(w, patientRows, predictionRows, riskRows))
+}
+
+// GET /stats/risk-factors
+func (h *Controller) GetRiskFactors(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req FactorParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	rows, err := h.Queries.ListLatestPredictionFactors(c, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot query risk factors")
+		return
+	}
+
+	c.JSON(http.StatusOK, buildFactorsResponse(req.GroupBy, rows))
+}
+
+// GET /stats/risk-factors/combinations
+func (h *Controller) GetRiskFactorCombinations(c *gin.Context) {
+	userID, ok := utils.UserIDFromContext(c)
+	if !ok {
+		utils.RespondError(c, http.StatusUnauthorized, "missing user in context")
+		return
+	}
+
+	var req CombinationParams
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	if req.Size == 0 {
+		req.Size = defaultCombinationSize
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultCombinationLimit
+	}
+
+	rows, err := h.Queries.ListLatestPredictionFactors(c, userID)
+	if err != nil {
+		utils.RespondError(c, http.StatusInternalServerError, "cannot query risk factors")
 		return
 	}
 
-	c.JSON(http.StatusOK, buildStatsResponse(totalPatients, totalPredictions, riskDist))
+	c.JSON(http.StatusOK, buildCombinationsResponse(req.GroupBy, req.Size, req.Limit, rows))
 }
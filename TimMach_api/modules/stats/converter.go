@@ -1,7 +1,11 @@
 package stats
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"time"
+	_ "time/tzdata" // image runtime không có sẵn tzdata cho tham số tz
 
 	db "chidinh/db/sqlc"
 )
@@ -12,30 +16,180 @@ type RiskSummary struct {
 	Items []RiskCount
 }
 
-func buildStatsResponse(
-	totalPatients int64,
-	totalPredictions int64,
-	distRows []db.GetRiskDistributionRow,
-) StatsResponse {
-	counts := map[string]int64{
-		"low":    0,
-		"medium": 0,
-		"high":   0,
-	}
-	for _, r := range distRows {
-		label := strings.ToLower(r.RiskLabel)
-		if _, ok := counts[label]; ok {
-			counts[label] = r.Count
-		}
-	}
-
+func buildStatsResponse(r db.UserStatsRollup) StatsResponse {
 	return StatsResponse{
-		TotalPatients:    totalPatients,
-		TotalPredictions: totalPredictions,
+		TotalPatients:    r.TotalPatients,
+		TotalPredictions: r.TotalPredictions,
 		RiskCounts: []RiskCount{
-			{RiskLabel: "high", Count: counts["high"]},
-			{RiskLabel: "medium", Count: counts["medium"]},
-			{RiskLabel: "low", Count: counts["low"]},
+			{RiskLabel: "high", Count: r.HighRisk},
+			{RiskLabel: "medium", Count: r.MediumRisk},
+			{RiskLabel: "low", Count: r.LowRisk},
 		},
 	}
 }
+
+func rollupFromRow(r db.GetUserStatsRollupRow) db.UserStatsRollup {
+	return db.UserStatsRollup{
+		UserID:           r.UserID,
+		TotalPatients:    r.TotalPatients,
+		TotalPredictions: r.TotalPredictions,
+		HighRisk:         r.HighRisk,
+		MediumRisk:       r.MediumRisk,
+		LowRisk:          r.LowRisk,
+		RefreshedAt:      r.RefreshedAt,
+	}
+}
+
+// riskCounts đếm theo nhãn high/medium/low; nhãn khác bị bỏ qua.
+type riskCounts map[string]int64
+
+func newRiskCounts() riskCounts {
+	return riskCounts{"low": 0, "medium": 0, "high": 0}
+}
+
+func (rc riskCounts) add(label string, n int64) {
+	label = strings.ToLower(label)
+	if _, ok := rc[label]; ok {
+		rc[label] += n
+	}
+}
+
+func (rc riskCounts) items() []RiskCount {
+	return []RiskCount{
+		{RiskLabel: "high", Count: rc["high"]},
+		{RiskLabel: "medium", Count: rc["medium"]},
+		{RiskLabel: "low", Count: rc["low"]},
+	}
+}
+
+// timeseriesWindow là khoảng [From, To) đã chuẩn hoá theo múi giờ cùng danh sách đầu bucket.
+type timeseriesWindow struct {
+	Bucket string
+	Loc    *time.Location
+	From   time.Time
+	To     time.Time
+	Starts []time.Time
+}
+
+func parseTimeseries(req TimeseriesParams, now time.Time) (timeseriesWindow, error) {
+	w := timeseriesWindow{Bucket: req.Bucket}
+	if w.Bucket == "" {
+		w.Bucket = BucketDay
+	}
+	tz := req.TZ
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return w, errors.New("invalid tz, expected an IANA time zone name")
+	}
+	w.Loc = loc
+
+	today := truncateBucket(now.In(loc), BucketDay)
+	last := today
+	if req.To != "" {
+		if last, err = time.ParseInLocation("2006-01-02", req.To, loc); err != nil {
+			return w, errors.New("invalid to, expected YYYY-MM-DD")
+		}
+	}
+	w.To = last.AddDate(0, 0, 1)
+
+	if req.From != "" {
+		if w.From, err = time.ParseInLocation("2006-01-02", req.From, loc); err != nil {
+			return w, errors.New("invalid from, expected YYYY-MM-DD")
+		}
+	} else {
+		switch w.Bucket {
+		case BucketWeek:
+			w.From = truncateBucket(last, BucketWeek).AddDate(0, 0, -7*11)
+		case BucketMonth:
+			w.From = truncateBucket(last, BucketMonth).AddDate(0, -11, 0)
+		default:
+			w.From = last.AddDate(0, 0, -29)
+		}
+	}
+	if !w.From.Before(w.To) {
+		return w, errors.New("from must not be after to")
+	}
+
+	for s := truncateBucket(w.From, w.Bucket); s.Before(w.To); s = nextBucket(s, w.Bucket) {
+		if len(w.Starts) == maxTimeseriesBuckets {
+			return w, fmt.Errorf("range too large, at most %d buckets", maxTimeseriesBuckets)
+		}
+		w.Starts = append(w.Starts, s)
+	}
+	return w, nil
+}
+
+// truncateBucket khớp với date_trunc của Postgres: tuần bắt đầu từ thứ Hai.
+func truncateBucket(t time.Time, bucket string) time.Time {
+	y, m, d := t.Date()
+	switch bucket {
+	case BucketWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// buildTimeseriesResponse ghép kết quả ba truy vấn theo đầu bucket; bucket không có dữ liệu vẫn
+// xuất hiện với giá trị 0 (avg_probability = null) để client vẽ trục thời gian liên tục.
+func buildTimeseriesResponse(
+	w timeseriesWindow,
+	patientRows []db.GetNewPatientsTimeseriesRow,
+	predictionRows []db.GetPredictionsTimeseriesRow,
+	riskRows []db.GetRiskTimeseriesRow,
+) TimeseriesResponse {
+	points := make([]TimeseriesPoint, len(w.Starts))
+	risks := make([]riskCounts, len(w.Starts))
+	index := make(map[int64]int, len(w.Starts))
+	for i, s := range w.Starts {
+		points[i].BucketStart = s
+		risks[i] = newRiskCounts()
+		index[s.Unix()] = i
+	}
+
+	for _, r := range patientRows {
+		if i, ok := index[r.BucketStart.Time.Unix()]; ok {
+			points[i].NewPatients = r.NewPatients
+		}
+	}
+	for _, r := range predictionRows {
+		if i, ok := index[r.BucketStart.Time.Unix()]; ok {
+			avg := round4(r.AvgProbability)
+			points[i].Predictions = r.Predictions
+			points[i].AvgProbability = &avg
+		}
+	}
+	for _, r := range riskRows {
+		if i, ok := index[r.BucketStart.Time.Unix()]; ok {
+			risks[i].add(r.RiskLabel, r.Count)
+		}
+	}
+	for i := range points {
+		points[i].RiskCounts = risks[i].items()
+	}
+
+	return TimeseriesResponse{
+		Bucket: w.Bucket,
+		From:   w.From.Format("2006-01-02"),
+		To:     w.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		TZ:     w.Loc.String(),
+		Points: points,
+	}
+}
@@ -0,0 +1,225 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+)
+
+// KnownFactors là các field ML trả về trong predictions.factors; luôn xuất hiện trong kết quả
+// (kể cả khi không bệnh nhân nào có) để dashboard hiển thị cố định.
+var KnownFactors = []string{"cholesterol", "gluc", "blood_pressure", "bmi", "smoke", "alco", "active"}
+
+const unknownGroup = "unknown"
+
+// ageBand tính tuổi tại thời điểm dự đoán từ birth_year (dob đã mã hoá) và gom theo nhóm 10 năm.
+func ageBand(r db.ListLatestPredictionFactorsRow) string {
+	if r.BirthYear == nil || !r.CreatedAt.Valid {
+		return unknownGroup
+	}
+	age := r.CreatedAt.Time.Year() - int(*r.BirthYear)
+	switch {
+	case age < 0:
+		return unknownGroup
+	case age < 40:
+		return "<40"
+	case age >= 70:
+		return "70+"
+	default:
+		lower := age / 10 * 10
+		return strconv.Itoa(lower) + "-" + strconv.Itoa(lower+9)
+	}
+}
+
+func groupOf(groupBy string, r db.ListLatestPredictionFactorsRow) string {
+	switch groupBy {
+	case GroupByAgeBand:
+		return ageBand(r)
+	case GroupByGender:
+		return strings.ToLower(utils.GenderLabel(r.Gender))
+	default:
+		return ""
+	}
+}
+
+// splitGroups chia rows theo group_by; keys đã sắp xếp để response ổn định.
+func splitGroups(groupBy string, rows []db.ListLatestPredictionFactorsRow) ([]string, map[string][]db.ListLatestPredictionFactorsRow) {
+	groups := make(map[string][]db.ListLatestPredictionFactorsRow)
+	if groupBy == "" {
+		return nil, groups
+	}
+	for _, r := range rows {
+		k := groupOf(groupBy, r)
+		groups[k] = append(groups[k], r)
+	}
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, groups
+}
+
+func buildFactorsResponse(groupBy string, rows []db.ListLatestPredictionFactorsRow) FactorsResponse {
+	resp := FactorsResponse{GroupBy: groupBy, FactorBreakdown: factorBreakdown("", rows)}
+	keys, groups := splitGroups(groupBy, rows)
+	for _, k := range keys {
+		resp.Groups = append(resp.Groups, factorBreakdown(k, groups[k]))
+	}
+	return resp
+}
+
+func factorBreakdown(group string, rows []db.ListLatestPredictionFactorsRow) FactorBreakdown {
+	out := FactorBreakdown{Group: group, Patients: int64(len(rows))}
+
+	fields := append([]string(nil), KnownFactors...)
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		seen[f] = true
+	}
+	var extra []string
+	var total float64
+	with := make(map[string]*mean)
+	for _, r := range rows {
+		total += r.Probability
+		for _, f := range r.FactorFields {
+			if !seen[f] {
+				seen[f] = true
+				extra = append(extra, f)
+			}
+			if with[f] == nil {
+				with[f] = &mean{}
+			}
+			with[f].add(r.Probability)
+		}
+	}
+	sort.Strings(extra)
+	fields = append(fields, extra...)
+
+	all := mean{sum: total, n: len(rows)}
+	out.AvgProbability = all.value()
+	out.Factors = make([]FactorStat, 0, len(fields))
+	for _, f := range fields {
+		w := with[f]
+		if w == nil {
+			w = &mean{}
+		}
+		without := mean{sum: all.sum - w.sum, n: all.n - w.n}
+		out.Factors = append(out.Factors, FactorStat{
+			Field:                 f,
+			Patients:              int64(w.n),
+			Prevalence:            share(w.n, all.n),
+			AvgProbabilityWith:    w.value(),
+			AvgProbabilityWithout: without.value(),
+		})
+	}
+	return out
+}
+
+func buildCombinationsResponse(groupBy string, size, limit int, rows []db.ListLatestPredictionFactorsRow) CombinationsResponse {
+	resp := CombinationsResponse{
+		GroupBy:              groupBy,
+		Size:                 size,
+		CombinationBreakdown: combinationBreakdown("", size, limit, rows),
+	}
+	keys, groups := splitGroups(groupBy, rows)
+	for _, k := range keys {
+		resp.Groups = append(resp.Groups, combinationBreakdown(k, size, limit, groups[k]))
+	}
+	return resp
+}
+
+// combinationBreakdown đếm mọi tổ hợp size factor cùng xuất hiện trên một bệnh nhân,
+// sắp theo số bệnh nhân giảm dần rồi theo tên field.
+func combinationBreakdown(group string, size, limit int, rows []db.ListLatestPredictionFactorsRow) CombinationBreakdown {
+	out := CombinationBreakdown{Group: group, Patients: int64(len(rows))}
+
+	counts := make(map[string]*mean)
+	for _, r := range rows {
+		for _, combo := range subsets(r.FactorFields, size) {
+			key := strings.Join(combo, ",")
+			if counts[key] == nil {
+				counts[key] = &mean{}
+			}
+			counts[key].add(r.Probability)
+		}
+	}
+
+	out.Combinations = make([]FactorCombination, 0, len(counts))
+	for key, m := range counts {
+		out.Combinations = append(out.Combinations, FactorCombination{
+			Fields:         strings.Split(key, ","),
+			Patients:       int64(m.n),
+			Prevalence:     share(m.n, len(rows)),
+			AvgProbability: m.value(),
+		})
+	}
+	sort.Slice(out.Combinations, func(i, j int) bool {
+		a, b := out.Combinations[i], out.Combinations[j]
+		if a.Patients != b.Patients {
+			return a.Patients > b.Patients
+		}
+		return strings.Join(a.Fields, ",") < strings.Join(b.Fields, ",")
+	})
+	if len(out.Combinations) > limit {
+		out.Combinations = out.Combinations[:limit]
+	}
+	return out
+}
+
+// subsets liệt kê các tổ hợp k phần tử của fields (fields đã sắp xếp nên tổ hợp cũng sắp xếp).
+func subsets(fields []string, k int) [][]string {
+	if k <= 0 || len(fields) < k {
+		return nil
+	}
+	var out [][]string
+	combo := make([]string, 0, k)
+	var walk func(start int)
+	walk = func(start int) {
+		if len(combo) == k {
+			out = append(out, append([]string(nil), combo...))
+			return
+		}
+		for i := start; i <= len(fields)-(k-len(combo)); i++ {
+			combo = append(combo, fields[i])
+			walk(i + 1)
+			combo = combo[:len(combo)-1]
+		}
+	}
+	walk(0)
+	return out
+}
+
+type mean struct {
+	sum float64
+	n   int
+}
+
+func (m *mean) add(v float64) {
+	m.sum += v
+	m.n++
+}
+
+// value trả nil khi không có mẫu để phân biệt với trung bình bằng 0.
+func (m mean) value() *float64 {
+	if m.n == 0 {
+		return nil
+	}
+	v := round4(m.sum / float64(m.n))
+	return &v
+}
+
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round4(float64(n) / float64(total))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	db "chidinh/db/sqlc"
+	"chidinh/utils"
+)
+
+const refreshBatchSize = 100
+
+// Refresher làm mới user_stats_rollups cho các user nằm trong stats_dirty_users
+// (CreatePatient/CreatePrediction/xoá/khôi phục bệnh nhân tự đánh dấu trong cùng câu lệnh SQL).
+type Refresher struct {
+	Queries *db.Queries
+}
+
+func NewRefresher(queries *db.Queries) *Refresher {
+	return &Refresher{Queries: queries}
+}
+
+// Run chạy RefreshDirty mỗi interval cho tới khi ctx bị huỷ.
+func (r *Refresher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := r.RefreshDirty(ctx); err != nil {
+			utils.L().Warnw("refresh stats rollups failed", "error", err, "refreshed", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshDirty làm mới rollup của mọi user đang bị đánh dấu, trả về số user đã làm mới.
+func (r *Refresher) RefreshDirty(ctx context.Context) (int, error) {
+	refreshed := 0
+	for {
+		userIDs, err := r.Queries.ListDirtyStatsUsers(ctx, refreshBatchSize)
+		if err != nil {
+			return refreshed, err
+		}
+		for _, userID := range userIDs {
+			if _, err := r.Queries.RefreshUserStatsRollup(ctx, userID); err != nil {
+				return refreshed, err
+			}
+			refreshed++
+		}
+		if len(userIDs) < refreshBatchSize {
+			return refreshed, nil
+		}
+	}
+}
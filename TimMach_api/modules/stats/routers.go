@@ -6,4 +6,7 @@ import "github.com/gin-gonic/gin"
 func RegisterStatsRoutes(r *gin.RouterGroup, h *Controller) {
 	group := r.Group("/stats")
 	group.GET("", h.GetStats)
+	group.GET("/timeseries", h.GetTimeseries)
+	group.GET("/risk-factors", h.GetRiskFactors)
+	group.GET("/risk-factors/combinations", h.GetRiskFactorCombinations)
 }
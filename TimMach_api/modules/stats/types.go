@@ -1,5 +1,7 @@
 package stats
 
+import "time"
+
 type RiskCount struct {
 	RiskLabel string `json:"risk_label"`
 	Count     int64  `json:"count"`
@@ -10,3 +12,99 @@ type StatsResponse struct {
 	TotalPredictions int64       `json:"total_predictions"`
 	RiskCounts       []RiskCount `json:"risk_counts"`
 }
+
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+
+	// maxTimeseriesBuckets giới hạn số bucket một lần truy vấn (≈ 1 năm theo ngày).
+	maxTimeseriesBuckets = 366
+)
+
+// TimeseriesParams: from/to là ngày yyyy-mm-dd (bao gồm cả hai đầu) theo múi giờ tz.
+// Bỏ trống from/to thì lấy 30 ngày, 12 tuần hoặc 12 tháng gần nhất tuỳ bucket.
+type TimeseriesParams struct {
+	Bucket string `form:"bucket" binding:"omitempty,oneof=day week month"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+	TZ     string `form:"tz"`
+}
+
+type TimeseriesPoint struct {
+	BucketStart    time.Time   `json:"bucket_start"`
+	NewPatients    int64       `json:"new_patients"`
+	Predictions    int64       `json:"predictions"`
+	AvgProbability *float64    `json:"avg_probability"`
+	RiskCounts     []RiskCount `json:"risk_counts"`
+}
+
+type TimeseriesResponse struct {
+	Bucket string            `json:"bucket"`
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	TZ     string            `json:"tz"`
+	Points []TimeseriesPoint `json:"points"`
+}
+
+const (
+	GroupByAgeBand = "age_band"
+	GroupByGender  = "gender"
+
+	defaultCombinationSize  = 2
+	defaultCombinationLimit = 10
+)
+
+// FactorParams: group_by = age_band | gender (bỏ trống = chỉ số liệu toàn bộ).
+type FactorParams struct {
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=age_band gender"`
+}
+
+// CombinationParams: size là số factor trong một tổ hợp (2 hoặc 3), limit là số tổ hợp trả về mỗi nhóm.
+type CombinationParams struct {
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=age_band gender"`
+	Size    int    `form:"size" binding:"omitempty,min=2,max=3"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// FactorStat: prevalence là tỉ lệ bệnh nhân có factor trên tổng bệnh nhân của nhóm.
+type FactorStat struct {
+	Field                 string   `json:"field"`
+	Patients              int64    `json:"patients"`
+	Prevalence            float64  `json:"prevalence"`
+	AvgProbabilityWith    *float64 `json:"avg_probability_with"`
+	AvgProbabilityWithout *float64 `json:"avg_probability_without"`
+}
+
+type FactorBreakdown struct {
+	Group          string       `json:"group,omitempty"`
+	Patients       int64        `json:"patients"`
+	AvgProbability *float64     `json:"avg_probability"`
+	Factors        []FactorStat `json:"factors"`
+}
+
+type FactorsResponse struct {
+	GroupBy string `json:"group_by,omitempty"`
+	FactorBreakdown
+	Groups []FactorBreakdown `json:"groups,omitempty"`
+}
+
+type FactorCombination struct {
+	Fields         []string `json:"fields"`
+	Patients       int64    `json:"patients"`
+	Prevalence     float64  `json:"prevalence"`
+	AvgProbability *float64 `json:"avg_probability"`
+}
+
+type CombinationBreakdown struct {
+	Group        string              `json:"group,omitempty"`
+	Patients     int64               `json:"patients"`
+	Combinations []FactorCombination `json:"combinations"`
+}
+
+type CombinationsResponse struct {
+	GroupBy string `json:"group_by,omitempty"`
+	Size    int    `json:"size"`
+	CombinationBreakdown
+	Groups []CombinationBreakdown `json:"groups,omitempty"`
+}
@@ -9,6 +9,7 @@ import (
 type User struct {
 	ID        string
 	Email     string
+	Clinic    string
 	CreatedAt time.Time
 }
 
@@ -16,6 +17,7 @@ func toUserDomain(u db.User) User {
 	return User{
 		ID:        u.ID,
 		Email:     u.Email,
+		Clinic:    u.Clinic,
 		CreatedAt: u.CreatedAt.Time,
 	}
 }
@@ -24,6 +26,7 @@ func toUserResponse(u User) UserResponse {
 	return UserResponse{
 		ID:        u.ID,
 		Email:     u.Email,
+		Clinic:    u.Clinic,
 		CreatedAt: u.CreatedAt,
 	}
 }
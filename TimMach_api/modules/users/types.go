@@ -6,5 +6,6 @@ import "time"
 type UserResponse struct {
 	ID        string    `json:"id"`
 	Email     string    `json:"email"`
+	Clinic    string    `json:"clinic"`
 	CreatedAt time.Time `json:"created_at"`
 }
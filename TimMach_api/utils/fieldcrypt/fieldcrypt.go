@@ -0,0 +1,281 @@
+// Package fieldcrypt mã hoá từng field PII ở tầng ứng dụng theo kiểu envelope:
+// mỗi giá trị có một data key (DEK) ngẫu nhiên, mã hoá AES-256-GCM; DEK được bọc bằng
+// key encryption key (KEK) có version, nên xoay key chỉ cần thêm KEK mới và chạy cmd/reencrypt.
+//
+// Định dạng lưu: "enc:v1:<kek version>:<base64 DEK đã bọc>:<base64 nonce+ciphertext>".
+// Giá trị không có tiền tố "enc:" được coi là plaintext cũ (trước khi bật mã hoá) và đọc nguyên trạng.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	prefix        = "enc:"
+	formatVersion = "v1"
+	dekSize       = 32
+)
+
+var (
+	ErrMalformed  = errors.New("fieldcrypt: malformed ciphertext")
+	ErrUnknownKey = errors.New("fieldcrypt: unknown key version")
+	ErrNoKeys     = errors.New("fieldcrypt: encryption keys not configured")
+	ErrNoIndexKey = errors.New("fieldcrypt: index key is required when encryption keys are configured")
+)
+
+// Keyring giữ các KEK theo version và key cho blind index.
+// Keyring rỗng (không cấu hình key) chạy ở chế độ passthrough: ghi plaintext, version 0.
+type Keyring struct {
+	keys     map[int32][]byte
+	active   int32
+	indexKey []byte
+}
+
+// ParseKeys đọc spec dạng "1:<base64 32 byte>,2:<base64 32 byte>"; active là version dùng khi ghi.
+// indexKey (base64) dùng cho blind index, bắt buộc khi có key: blind index không được đi theo key active,
+// nếu không thêm key mới sẽ đổi toàn bộ hash tìm kiếm.
+func ParseKeys(spec string, active int32, indexKey string) (*Keyring, error) {
+	k := &Keyring{keys: map[int32][]byte{}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		idStr, keyStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be <version>:<base64>", part)
+		}
+		id, err := strconv.ParseInt(idStr, 10, 32)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("fieldcrypt: invalid key version %q", idStr)
+		}
+		key, err := base64.StdEncoding.DecodeString(keyStr)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("fieldcrypt: key %d must be 32 bytes base64", id)
+		}
+		k.keys[int32(id)] = key
+	}
+	if len(k.keys) == 0 {
+		return k, nil
+	}
+
+	if active == 0 {
+		for id := range k.keys {
+			if id > active {
+				active = id
+			}
+		}
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: active key %d not configured", active)
+	}
+	k.active = active
+
+	if indexKey == "" {
+		return nil, ErrNoIndexKey
+	}
+	key, err := base64.StdEncoding.DecodeString(indexKey)
+	if err != nil || len(key) < 16 {
+		return nil, errors.New("fieldcrypt: index key must be at least 16 bytes base64")
+	}
+	k.indexKey = key
+	return k, nil
+}
+
+// Enabled cho biết có key để mã hoá hay không.
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.active != 0
+}
+
+// ActiveVersion là version KEK dùng khi ghi (0 = passthrough).
+func (k *Keyring) ActiveVersion() int32 {
+	if k == nil {
+		return 0
+	}
+	return k.active
+}
+
+// Encrypt mã hoá plaintext bằng DEK mới bọc bởi KEK active.
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	if !k.Enabled() {
+		return string(plaintext), nil
+	}
+	dek := make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.active], dek)
+	if err != nil {
+		return "", err
+	}
+	body, err := seal(dek, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%d:%s:%s", prefix, formatVersion, k.active,
+		base64.RawStdEncoding.EncodeToString(wrapped),
+		base64.RawStdEncoding.EncodeToString(body)), nil
+}
+
+// Decrypt giải mã giá trị do Encrypt tạo ra; plaintext cũ (không có tiền tố) trả nguyên trạng.
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return []byte(value), nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 4 || parts[0] != formatVersion {
+		return nil, ErrMalformed
+	}
+	version, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if k == nil || len(k.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	kek, ok := k.keys[int32(version)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKey, version)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	body, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	dek, err := open(kek, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return open(dek, body)
+}
+
+// EncryptString / DecryptString là bản tiện dụng cho field text.
+func (k *Keyring) EncryptString(s string) (string, error) {
+	return k.Encrypt([]byte(s))
+}
+
+func (k *Keyring) DecryptString(s string) (string, error) {
+	b, err := k.Decrypt(s)
+	return string(b), err
+}
+
+// SealJSON mã hoá một document JSON và trả về JSON string (vẫn hợp lệ cho cột JSONB).
+// Ở chế độ passthrough trả nguyên document.
+func (k *Keyring) SealJSON(doc []byte) ([]byte, error) {
+	if !k.Enabled() {
+		return doc, nil
+	}
+	enc, err := k.Encrypt(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(enc)
+}
+
+// OpenJSON là chiều ngược của SealJSON; document chưa mã hoá trả nguyên trạng.
+func (k *Keyring) OpenJSON(raw []byte) ([]byte, error) {
+	var s string
+	if len(raw) == 0 || raw[0] != '"' || json.Unmarshal(raw, &s) != nil || !IsEncrypted(s) {
+		return raw, nil
+	}
+	return k.Decrypt(s)
+}
+
+// KeyVersion trả về version KEK của giá trị đã mã hoá (0 nếu là plaintext).
+func KeyVersion(value string) int32 {
+	if !IsEncrypted(value) {
+		return 0
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 3)
+	if len(parts) < 2 {
+		return 0
+	}
+	v, _ := strconv.ParseInt(parts[1], 10, 32)
+	return int32(v)
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// BlindIndex trả về HMAC của từng token đã chuẩn hoá (xem Tokens) để tìm kiếm trên cột mã hoá
+// bằng so khớp chính xác. Ở chế độ passthrough trả token chuẩn hoá không băm.
+func (k *Keyring) BlindIndex(tokens []string) []string {
+	out := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !k.Enabled() {
+			out = append(out, t)
+			continue
+		}
+		mac := hmac.New(sha256.New, k.indexKey)
+		mac.Write([]byte(t))
+		out = append(out, hex.EncodeToString(mac.Sum(nil)[:16]))
+	}
+	return out
+}
+
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ct := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ct, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultKeyring = &Keyring{}
+)
+
+// SetDefault đặt keyring dùng chung (gọi một lần lúc khởi động, giống utils.InitLogger).
+func SetDefault(k *Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = k
+}
+
+// Default trả về keyring dùng chung cho các converter.
+func Default() *Keyring {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultKeyring
+}
@@ -0,0 +1,57 @@
+package fieldcrypt
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const minPrefixLen = 2
+
+// Normalize hạ chữ thường và bỏ dấu tiếng Việt ("Đặng" → "dang").
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, strings.ToLower(s))
+	if err != nil {
+		out = strings.ToLower(s)
+	}
+	return strings.ReplaceAll(out, "đ", "d")
+}
+
+// NameTokens tách tên thành các tiền tố (>= 2 ký tự) của từng từ để lưu blind index,
+// cho phép tìm "ng van" khớp "Nguyễn Văn A".
+func NameTokens(name string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, word := range strings.Fields(Normalize(name)) {
+		r := []rune(word)
+		start := minPrefixLen
+		if len(r) < minPrefixLen {
+			start = len(r)
+		}
+		for i := start; i <= len(r); i++ {
+			p := string(r[:i])
+			if !seen[p] {
+				seen[p] = true
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// QueryTokens chuẩn hoá chuỗi tìm kiếm thành các từ; mỗi từ phải khớp một tiền tố trong NameTokens.
+func QueryTokens(q string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, word := range strings.Fields(Normalize(q)) {
+		if !seen[word] {
+			seen[word] = true
+			out = append(out, word)
+		}
+	}
+	return out
+}
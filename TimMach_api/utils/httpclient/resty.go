@@ -35,3 +35,22 @@ func PostJSON(ctx context.Context, client *resty.Client, path string, payload an
 	}
 	return resp, nil
 }
+
+// GetJSON sends a GET request and unmarshals the JSON response into out.
+// Returns an error if the request fails or the service returns a non-2xx status.
+func GetJSON(ctx context.Context, client *resty.Client, path string, out any) (*resty.Response, error) {
+	if client == nil {
+		return nil, fmt.Errorf("http client is not configured")
+	}
+	resp, err := client.R().
+		SetContext(ctx).
+		SetResult(out).
+		Get(path)
+	if err != nil {
+		return resp, err
+	}
+	if resp.IsError() {
+		return resp, fmt.Errorf("service status %d", resp.StatusCode())
+	}
+	return resp, nil
+}
@@ -4,16 +4,40 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/textproto"
 	"strings"
 
 	"gopkg.in/gomail.v2"
 )
 
-// Attachment represents a file attached to the email.
+// Attachment represents a file attached to the email. When ContentID is set the file is
+// embedded inline (multipart/related) and can be referenced from the HTML part as cid:<ContentID>.
 type Attachment struct {
-	Filename string
-	MimeType string
-	Content  []byte
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mime_type"`
+	Content   []byte `json:"content"`
+	ContentID string `json:"content_id,omitempty"`
+}
+
+// Message is an email with a plain-text part and an optional HTML alternative.
+// To and Cc become the visible headers; Bcc addresses only receive a copy.
+type Message struct {
+	To  []string
+	Cc  []string
+	Bcc []string
+	// Rcpt, when set, replaces the SMTP envelope recipients (default To + Cc + Bcc). The email outbox
+	// delivers one copy per address this way so each recipient gets its own delivery status.
+	Rcpt        []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Sender delivers a Message. *Mailer implements it; the email outbox depends on this interface
+// so a stub (or a local SMTP catcher such as Mailpit) can stand in during tests.
+type Sender interface {
+	SendMessage(msg Message) error
 }
 
 // Mailer wraps gomail dialer plus metadata about sender and config state.
@@ -23,18 +47,24 @@ type Mailer struct {
 	enabled bool
 }
 
-var ErrNotConfigured = errors.New("mailer is not configured")
+var (
+	ErrNotConfigured = errors.New("mailer is not configured")
+	ErrNoRecipients  = errors.New("message has no recipients")
+	// ErrRejected marks a permanent SMTP failure (5xx reply to MAIL/RCPT/DATA): retrying will not help.
+	ErrRejected = errors.New("message rejected by smtp server")
+)
 
-// New creates a Mailer. If host/user/pass are empty, the mailer is disabled
-// and Send will return ErrNotConfigured.
+// New creates a Mailer. If host is empty (or a placeholder) or no sender address can be
+// determined, the mailer is disabled and Send will return ErrNotConfigured.
+// Username/password are optional so local SMTP catchers without auth (Mailpit) work.
 func New(host string, port int, username, password, from string) *Mailer {
-	// Treat placeholder or empty configuration as disabled to avoid dialing dummy hosts in dev.
-	if host == "" || strings.Contains(host, "example.com") || username == "" || password == "" {
-		return &Mailer{enabled: false}
-	}
 	if from == "" {
 		from = username
 	}
+	// Treat placeholder or empty configuration as disabled to avoid dialing dummy hosts in dev.
+	if host == "" || strings.Contains(host, "example.com") || from == "" {
+		return &Mailer{enabled: false}
+	}
 	dialer := gomail.NewDialer(host, port, username, password)
 	return &Mailer{
 		dialer:  dialer,
@@ -47,21 +77,42 @@ func (m *Mailer) Enabled() bool {
 	return m != nil && m.enabled
 }
 
-// Send delivers an email with optional attachments.
+// Send delivers a plain-text email with optional attachments.
 func (m *Mailer) Send(to, subject, body string, attachments []Attachment) error {
+	return m.SendMessage(Message{To: []string{to}, Subject: subject, Text: body, Attachments: attachments})
+}
+
+// SendMessage delivers msg; the HTML part (if any) is sent as an alternative to the text part.
+func (m *Mailer) SendMessage(msg Message) error {
 	if !m.Enabled() {
 		return ErrNotConfigured
 	}
+	rcpt := msg.Rcpt
+	if len(rcpt) == 0 {
+		rcpt = append(append(append([]string(nil), msg.To...), msg.Cc...), msg.Bcc...)
+	}
+	if len(rcpt) == 0 {
+		return ErrNoRecipients
+	}
+	subject := msg.Subject
 	if subject == "" {
 		subject = "(no subject)"
 	}
-	msg := gomail.NewMessage()
-	msg.SetHeader("From", m.from)
-	msg.SetHeader("To", to)
-	msg.SetHeader("Subject", subject)
-	msg.SetBody("text/plain", body)
+	gm := gomail.NewMessage()
+	gm.SetHeader("From", m.from)
+	if len(msg.To) > 0 {
+		gm.SetHeader("To", msg.To...)
+	}
+	if len(msg.Cc) > 0 {
+		gm.SetHeader("Cc", msg.Cc...)
+	}
+	gm.SetHeader("Subject", subject)
+	gm.SetBody("text/plain", msg.Text)
+	if msg.HTML != "" {
+		gm.AddAlternative("text/html", msg.HTML)
+	}
 
-	for _, att := range attachments {
+	for _, att := range msg.Attachments {
 		name := att.Filename
 		if name == "" {
 			name = "attachment"
@@ -70,21 +121,42 @@ func (m *Mailer) Send(to, subject, body string, attachments []Attachment) error
 		if mime == "" {
 			mime = "application/octet-stream"
 		}
-
-		msg.Attach(
-			name,
-			gomail.SetHeader(map[string][]string{
-				"Content-Type": {mime},
-			}),
+		header := map[string][]string{
+			"Content-Type": {mime},
+		}
+		content := att.Content
+		settings := []gomail.FileSetting{
+			gomail.SetHeader(header),
 			gomail.SetCopyFunc(func(w io.Writer) error {
-				_, err := w.Write(att.Content)
+				_, err := w.Write(content)
 				if err != nil {
 					return fmt.Errorf("write attachment %s: %w", name, err)
 				}
 				return nil
 			}),
-		)
+		}
+
+		if att.ContentID != "" {
+			header["Content-ID"] = []string{"<" + att.ContentID + ">"}
+			gm.Embed(name, settings...)
+			continue
+		}
+		gm.Attach(name, settings...)
 	}
 
-	return m.dialer.DialAndSend(msg)
+	s, err := m.dialer.Dial()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Send directly on the connection (instead of gomail.Send) to keep the SMTP reply code.
+	if err := s.Send(m.from, rcpt, gm); err != nil {
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		return err
+	}
+	return nil
 }
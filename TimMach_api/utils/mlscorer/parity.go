@@ -0,0 +1,58 @@
+package mlscorer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Ngưỡng lệch mặc định khi so với output của FastAPI: probability làm tròn 4 chữ số,
+// contribution làm tròn 6 chữ số bên Python nên chỉ cho phép lệch một đơn vị làm tròn.
+const (
+	DefaultProbTol    = 1e-4
+	DefaultContribTol = 1e-5
+)
+
+// ParityCase là một case do ml-python/training/export_model.py sinh ra: input và output của FastAPI.
+type ParityCase struct {
+	Input  Input  `json:"input"`
+	Output Result `json:"output"`
+}
+
+// Compare trả về các điểm lệch giữa kết quả Go (got) và Python (want); rỗng nếu khớp.
+func Compare(got, want Result, probTol, contribTol float64) []string {
+	var msgs []string
+	if d := math.Abs(got.Probability - want.Probability); d > probTol {
+		msgs = append(msgs, fmt.Sprintf("probability %.4f != %.4f", got.Probability, want.Probability))
+	}
+	if got.Label != want.Label {
+		msgs = append(msgs, fmt.Sprintf("label %d != %d", got.Label, want.Label))
+	}
+	if got.RiskLevel != want.RiskLevel {
+		msgs = append(msgs, fmt.Sprintf("risk_level %s != %s", got.RiskLevel, want.RiskLevel))
+	}
+	if len(got.Factors) != len(want.Factors) {
+		msgs = append(msgs, fmt.Sprintf("factors %d != %d", len(got.Factors), len(want.Factors)))
+	} else {
+		for i := range got.Factors {
+			if got.Factors[i].Field != want.Factors[i].Field {
+				msgs = append(msgs, fmt.Sprintf("factor[%d] %s != %s", i, got.Factors[i].Field, want.Factors[i].Field))
+			}
+		}
+	}
+
+	wantContrib := make(map[string]float64, len(want.Contributions))
+	for _, c := range want.Contributions {
+		wantContrib[c.Feature] = c.Contribution
+	}
+	for _, c := range got.Contributions {
+		w, ok := wantContrib[c.Feature]
+		if !ok {
+			msgs = append(msgs, fmt.Sprintf("contribution %s missing in python output", c.Feature))
+			continue
+		}
+		if d := math.Abs(c.Contribution - w); d > contribTol {
+			msgs = append(msgs, fmt.Sprintf("contribution %s %.6f != %.6f", c.Feature, c.Contribution, w))
+		}
+	}
+	return msgs
+}
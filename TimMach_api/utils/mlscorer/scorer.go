@@ -0,0 +1,275 @@
+// Package mlscorer chấm điểm nguy cơ tim mạch in-process từ RandomForest đã export sang JSON
+// (ml-python/training/export_model.py). Kết quả khớp với endpoint /predict của FastAPI:
+// cùng feature kỹ thuật (bmi, bp_ratio), cùng StandardScaler, cùng ngưỡng risk level.
+package mlscorer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Input là features đầu vào, giống HeartInput bên FastAPI.
+type Input struct {
+	AgeYears    float64 `json:"age_years"`
+	Gender      int     `json:"gender"`
+	Height      float64 `json:"height"`
+	Weight      float64 `json:"weight"`
+	APHi        int     `json:"ap_hi"`
+	APLo        int     `json:"ap_lo"`
+	Cholesterol int     `json:"cholesterol"`
+	Gluc        int     `json:"gluc"`
+	Smoke       int     `json:"smoke"`
+	Alco        int     `json:"alco"`
+	Active      int     `json:"active"`
+}
+
+type Factor struct {
+	Field   string `json:"field"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type Contribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Result giống HeartPrediction bên FastAPI.
+type Result struct {
+	Probability       float64        `json:"probability"`
+	Label             int            `json:"label"`
+	RiskLevel         string         `json:"risk_level"`
+	Factors           []Factor       `json:"factors"`
+	BaseValue         float64        `json:"base_value"`
+	Contributions     []Contribution `json:"contributions"`
+	ModelName         string         `json:"model_name"`
+	ModelVersion      string         `json:"model_version"`
+	FeatureSchemaHash string         `json:"feature_schema_hash"`
+}
+
+type scaler struct {
+	Features []string  `json:"features"`
+	Mean     []float64 `json:"mean"`
+	Scale    []float64 `json:"scale"`
+}
+
+type tree struct {
+	Left      []int     `json:"left"`
+	Right     []int     `json:"right"`
+	Feature   []int     `json:"feature"`
+	Threshold []float64 `json:"threshold"`
+	Value     []float64 `json:"value"`
+}
+
+// Model là RandomForest đã load; an toàn khi dùng đồng thời (chỉ đọc).
+type Model struct {
+	ModelName           string   `json:"model_name"`
+	ModelVersion        string   `json:"model_version"`
+	FeatureSchemaHash   string   `json:"feature_schema_hash"`
+	InputFeatures       []string `json:"input_features"`
+	TransformedFeatures []string `json:"transformed_features"`
+	Scaler              scaler   `json:"scaler"`
+	Trees               []tree   `json:"trees"`
+
+	// scaleIdx[i] là vị trí trong Scaler của transformed feature i (-1 nếu passthrough).
+	scaleIdx []int
+}
+
+var ErrInvalidInput = errors.New("invalid input")
+
+// Load đọc model JSON và kiểm tra cấu trúc.
+func Load(path string) (*Model, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("decode model: %w", err)
+	}
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Model) init() error {
+	if len(m.Trees) == 0 {
+		return errors.New("model has no trees")
+	}
+	if len(m.Scaler.Mean) != len(m.Scaler.Features) || len(m.Scaler.Scale) != len(m.Scaler.Features) {
+		return errors.New("scaler size mismatch")
+	}
+	m.scaleIdx = make([]int, len(m.TransformedFeatures))
+	for i, name := range m.TransformedFeatures {
+		m.scaleIdx[i] = -1
+		for j, s := range m.Scaler.Features {
+			if s == name {
+				m.scaleIdx[i] = j
+			}
+		}
+		if _, ok := featureValue(Input{}, 0, 0, name); !ok {
+			return fmt.Errorf("unknown feature %q", name)
+		}
+	}
+	for ti, t := range m.Trees {
+		n := len(t.Value)
+		if len(t.Left) != n || len(t.Right) != n || len(t.Feature) != n || len(t.Threshold) != n {
+			return fmt.Errorf("tree %d: node arrays size mismatch", ti)
+		}
+	}
+	return nil
+}
+
+// Predict tính xác suất, label, risk level, factors và contribution theo từng feature.
+func (m *Model) Predict(in Input) (Result, error) {
+	if in.Height <= 0 {
+		return Result{}, fmt.Errorf("%w: height must be positive", ErrInvalidInput)
+	}
+	if in.APLo == 0 {
+		return Result{}, fmt.Errorf("%w: ap_lo must not be 0", ErrInvalidInput)
+	}
+
+	heightM := in.Height / 100.0
+	bmi := in.Weight / (heightM * heightM)
+	bpRatio := float64(in.APHi) / float64(in.APLo)
+
+	raw := make([]float64, len(m.TransformedFeatures))
+	x := make([]float64, len(m.TransformedFeatures))
+	for i, name := range m.TransformedFeatures {
+		v, _ := featureValue(in, bmi, bpRatio, name)
+		raw[i] = v
+		if j := m.scaleIdx[i]; j >= 0 {
+			scale := m.Scaler.Scale[j]
+			if scale == 0 {
+				scale = 1
+			}
+			v = (v - m.Scaler.Mean[j]) / scale
+		}
+		// sklearn ép X về float32 trước khi so sánh với threshold.
+		x[i] = float64(float32(v))
+	}
+
+	contrib := make([]float64, len(x))
+	var proba, base float64
+	for _, t := range m.Trees {
+		node := 0
+		base += t.Value[0]
+		for t.Left[node] != -1 {
+			f := t.Feature[node]
+			next := t.Right[node]
+			if x[f] <= t.Threshold[node] {
+				next = t.Left[node]
+			}
+			contrib[f] += t.Value[next] - t.Value[node]
+			node = next
+		}
+		proba += t.Value[node]
+	}
+	n := float64(len(m.Trees))
+	proba /= n
+	base /= n
+
+	res := Result{
+		Probability:       round(proba, 4),
+		RiskLevel:         riskLevel(proba),
+		Factors:           factors(in, bmi),
+		BaseValue:         round(base, 6),
+		Contributions:     make([]Contribution, 0, len(contrib)),
+		ModelName:         m.ModelName,
+		ModelVersion:      m.ModelVersion,
+		FeatureSchemaHash: m.FeatureSchemaHash,
+	}
+	if proba > 0.5 {
+		res.Label = 1
+	}
+	for i, name := range m.TransformedFeatures {
+		res.Contributions = append(res.Contributions, Contribution{
+			Feature:      name,
+			Value:        raw[i],
+			Contribution: round(contrib[i]/n, 6),
+		})
+	}
+	return res, nil
+}
+
+func featureValue(in Input, bmi, bpRatio float64, name string) (float64, bool) {
+	switch name {
+	case "age_years":
+		return in.AgeYears, true
+	case "gender":
+		return float64(in.Gender), true
+	case "height":
+		return in.Height, true
+	case "weight":
+		return in.Weight, true
+	case "ap_hi":
+		return float64(in.APHi), true
+	case "ap_lo":
+		return float64(in.APLo), true
+	case "cholesterol":
+		return float64(in.Cholesterol), true
+	case "gluc":
+		return float64(in.Gluc), true
+	case "smoke":
+		return float64(in.Smoke), true
+	case "alco":
+		return float64(in.Alco), true
+	case "active":
+		return float64(in.Active), true
+	case "bmi":
+		return bmi, true
+	case "bp_ratio":
+		return bpRatio, true
+	default:
+		return 0, false
+	}
+}
+
+// riskLevel dùng cùng ngưỡng với ml-python/main.py.
+func riskLevel(proba float64) string {
+	switch {
+	case proba < 0.33:
+		return "low"
+	case proba < 0.66:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// factors sao chép các quy tắc ngưỡng trong ml-python/main.py.
+func factors(in Input, bmi float64) []Factor {
+	out := []Factor{}
+	if in.Cholesterol >= 3 {
+		out = append(out, Factor{Field: "cholesterol", Status: "high", Message: "Cholesterol cao (>= 3)"})
+	}
+	if in.Gluc >= 3 {
+		out = append(out, Factor{Field: "gluc", Status: "high", Message: "Đường huyết cao (>= 3)"})
+	}
+	if in.APHi > 140 || in.APLo > 90 {
+		out = append(out, Factor{Field: "blood_pressure", Status: "high", Message: "Huyết áp cao (>140/90)"})
+	}
+	if bmi >= 30 {
+		out = append(out, Factor{Field: "bmi", Status: "high", Message: "BMI cao (>= 30)"})
+	}
+	if in.Smoke == 1 {
+		out = append(out, Factor{Field: "smoke", Status: "yes", Message: "Hút thuốc"})
+	}
+	if in.Alco == 1 {
+		out = append(out, Factor{Field: "alco", Status: "yes", Message: "Uống rượu"})
+	}
+	if in.Active == 0 {
+		out = append(out, Factor{Field: "active", Status: "low", Message: "Ít vận động"})
+	}
+	return out
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,65 @@
+package mlscorer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// testdata/: model.json là rừng 5 cây theo định dạng export, golden_cases.json là output mong đợi của model đó.
+// Đây là test hồi quy cho scorer Go, không chứng minh khớp với model sklearn thật (xem testdata/README.md);
+// khớp với production kiểm bằng make ml-parity.
+
+func loadFixture(t *testing.T) (*Model, []ParityCase) {
+	t.Helper()
+	model, err := Load("testdata/model.json")
+	if err != nil {
+		t.Fatalf("load model: %v", err)
+	}
+	raw, err := os.ReadFile("testdata/golden_cases.json")
+	if err != nil {
+		t.Fatalf("read cases: %v", err)
+	}
+	var cases []ParityCase
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatalf("decode cases: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no golden cases")
+	}
+	return model, cases
+}
+
+func TestPredictMatchesGolden(t *testing.T) {
+	model, cases := loadFixture(t)
+	for i, c := range cases {
+		got, err := model.Predict(c.Input)
+		if err != nil {
+			t.Errorf("case %d: predict: %v", i, err)
+			continue
+		}
+		for _, msg := range Compare(got, c.Output, DefaultProbTol, DefaultContribTol) {
+			t.Errorf("case %d: %s", i, msg)
+		}
+		if got.ModelVersion != c.Output.ModelVersion || got.FeatureSchemaHash != c.Output.FeatureSchemaHash {
+			t.Errorf("case %d: model metadata %s/%s != %s/%s", i,
+				got.ModelVersion, got.FeatureSchemaHash, c.Output.ModelVersion, c.Output.FeatureSchemaHash)
+		}
+	}
+}
+
+func TestPredictRejectsInvalidInput(t *testing.T) {
+	model, cases := loadFixture(t)
+	in := cases[0].Input
+
+	in.APLo = 0
+	if _, err := model.Predict(in); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("ap_lo = 0: got %v, want ErrInvalidInput", err)
+	}
+	in = cases[0].Input
+	in.Height = 0
+	if _, err := model.Predict(in); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("height = 0: got %v, want ErrInvalidInput", err)
+	}
+}
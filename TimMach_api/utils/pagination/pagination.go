@@ -0,0 +1,161 @@
+// Package pagination gom phân trang keyset (cursor) dùng chung cho các endpoint list.
+//
+// Query: ?limit=20&sort=-created_at&cursor=<next_cursor của trang trước>.
+// sort là tên field trong allowlist của endpoint, tiền tố "-" là giảm dần.
+// Cursor mã hóa (giá trị sort, id) của phần tử cuối trang và gắn với sort đã dùng,
+// nên đổi sort giữa chừng sẽ bị từ chối thay vì trả kết quả lệch.
+// Mỗi list có hai query tĩnh (...Desc và ...Asc, so sánh hàng (created_at, id) đơn giản để dùng được index);
+// controller chọn query theo Request.Desc.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultLimit int32 = 20
+	MaxLimit     int32 = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+)
+
+// Params là query phân trang chung, nhúng vào struct query của từng endpoint.
+type Params struct {
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+}
+
+// Cursor là vị trí keyset của phần tử cuối trang trước; Time dùng khi sort theo cột thời gian.
+type Cursor struct {
+	Sort string    `json:"s"`
+	Time time.Time `json:"t,omitempty"`
+	ID   int64     `json:"id"`
+}
+
+// Request là Params đã được kiểm tra: limit có mặc định, sort thuộc allowlist, cursor đã giải mã (nil ở trang đầu).
+type Request struct {
+	Limit  int32
+	Field  string
+	Desc   bool
+	Cursor *Cursor
+}
+
+// Newest là trang đầu theo thứ tự mới nhất trước, dùng khi chỉ cần vài bản ghi mới nhất (không phân trang).
+var Newest = Request{Desc: true}
+
+// Meta là phần envelope chung của mọi response list.
+type Meta struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int32  `json:"limit"`
+	Sort       string `json:"sort"`
+}
+
+// Parse kiểm tra Params theo allowlist field sort của endpoint; defaultSort dạng "-created_at".
+func Parse(p Params, allowed []string, defaultSort string) (Request, error) {
+	req := Request{Limit: p.Limit}
+	if req.Limit <= 0 {
+		req.Limit = DefaultLimit
+	}
+	req.Limit = min(req.Limit, MaxLimit)
+
+	sort := strings.TrimSpace(p.Sort)
+	if sort == "" {
+		sort = defaultSort
+	}
+	req.Desc = strings.HasPrefix(sort, "-")
+	req.Field = strings.TrimPrefix(sort, "-")
+	if !slices.Contains(allowed, req.Field) {
+		return req, ErrInvalidSort
+	}
+
+	if p.Cursor != "" {
+		cur, err := decode(p.Cursor)
+		if err != nil || cur.Sort != req.SortKey() {
+			return req, ErrInvalidCursor
+		}
+		req.Cursor = cur
+	}
+	return req, nil
+}
+
+// SortKey trả về sort ở dạng query ("-created_at").
+func (r Request) SortKey() string {
+	if r.Desc {
+		return "-" + r.Field
+	}
+	return r.Field
+}
+
+// FetchLimit là số dòng cần query: thêm 1 dòng để biết còn trang sau hay không.
+func (r Request) FetchLimit() int32 {
+	return r.Limit + 1
+}
+
+// CursorID dùng cho sqlc.arg('cursor_id') của các query keyset tĩnh (...Desc / ...Asc).
+// Trang đầu trả về mốc ngoài cùng theo chiều sort để (created_at, id) < / > cursor luôn đúng.
+func (r Request) CursorID() int64 {
+	switch {
+	case r.Cursor != nil:
+		return r.Cursor.ID
+	case r.Desc:
+		return math.MaxInt64
+	default:
+		return 0
+	}
+}
+
+// CursorTime dùng cho sqlc.arg('cursor_created_at'); trang đầu là +/-infinity theo chiều sort.
+func (r Request) CursorTime() pgtype.Timestamptz {
+	switch {
+	case r.Cursor != nil:
+		return pgtype.Timestamptz{Time: r.Cursor.Time, Valid: true}
+	case r.Desc:
+		return pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true}
+	default:
+		return pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+	}
+}
+
+// Trim bỏ dòng dư (query với FetchLimit) và dựng Meta; cursorOf tạo cursor từ phần tử cuối trang.
+func Trim[T any](items []T, r Request, cursorOf func(T) Cursor) ([]T, Meta) {
+	meta := Meta{Limit: r.Limit, Sort: r.SortKey()}
+	if int32(len(items)) <= r.Limit {
+		return items, meta
+	}
+	items = items[:r.Limit]
+	cur := cursorOf(items[len(items)-1])
+	cur.Sort = r.SortKey()
+	meta.HasMore = true
+	meta.NextCursor = encode(cur)
+	return items, meta
+}
+
+func encode(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decode(input string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(input)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cur Cursor
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
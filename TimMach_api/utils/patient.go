@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"math"
+	"time"
+
+	"chidinh/utils/fieldcrypt"
+)
+
+// Giới tính lưu trong patients.gender (theo form bệnh nhân phía FE).
+const (
+	GenderOther  int16 = 0
+	GenderMale   int16 = 1
+	GenderFemale int16 = 2
+)
+
+// Mã giới tính của dataset cardio và ML service (ngược với patients.gender).
+const (
+	MLGenderFemale = 1
+	MLGenderMale   = 2
+)
+
+// GenderToML đổi patients.gender sang mã của ML; false nếu không xác định (Other).
+func GenderToML(g int16) (int, bool) {
+	switch g {
+	case GenderMale:
+		return MLGenderMale, true
+	case GenderFemale:
+		return MLGenderFemale, true
+	default:
+		return 0, false
+	}
+}
+
+// GenderLabel hiển thị patients.gender.
+func GenderLabel(g int16) string {
+	switch g {
+	case GenderMale:
+		return "Male"
+	case GenderFemale:
+		return "Female"
+	default:
+		return "Other"
+	}
+}
+
+// MLGenderLabel hiển thị mã giới tính của ML (raw_features.gender).
+func MLGenderLabel(g int) string {
+	switch g {
+	case MLGenderMale:
+		return "Male"
+	case MLGenderFemale:
+		return "Female"
+	default:
+		return "Other"
+	}
+}
+
+// AgeYearsAt tính tuổi (năm, 2 chữ số thập phân) tại thời điểm at, giống cách dataset đổi ngày sang năm.
+func AgeYearsAt(dob, at time.Time) float64 {
+	days := at.Sub(dob).Hours() / 24
+	return math.Round(days/365.25*100) / 100
+}
+
+const dobLayout = "2006-01-02"
+
+// SealedPatient là các cột PII của patients sau khi mã hoá, kèm năm sinh và blind index tên
+// để vẫn lọc theo tuổi / tìm theo tên được mà không cần giải mã trong SQL.
+type SealedPatient struct {
+	Name       string
+	Dob        string
+	BirthYear  *int16
+	NameIndex  []string
+	KeyVersion int32
+}
+
+// SealPatient mã hoá tên và ngày sinh bằng keyring mặc định (utils/fieldcrypt).
+func SealPatient(name string, dob time.Time) (SealedPatient, error) {
+	k := fieldcrypt.Default()
+	encName, err := k.EncryptString(name)
+	if err != nil {
+		return SealedPatient{}, err
+	}
+	encDob, err := k.EncryptString(dob.Format(dobLayout))
+	if err != nil {
+		return SealedPatient{}, err
+	}
+	year := int16(dob.Year())
+	return SealedPatient{
+		Name:       encName,
+		Dob:        encDob,
+		BirthYear:  &year,
+		NameIndex:  k.BlindIndex(fieldcrypt.NameTokens(name)),
+		KeyVersion: k.ActiveVersion(),
+	}, nil
+}
+
+// OpenPatient giải mã tên và ngày sinh đọc từ patients (plaintext cũ trả nguyên trạng).
+func OpenPatient(name, dob string) (string, time.Time, error) {
+	k := fieldcrypt.Default()
+	plainName, err := k.DecryptString(name)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	plainDob, err := k.DecryptString(dob)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	t, err := time.Parse(dobLayout, plainDob)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return plainName, t, nil
+}
+
+// OpenFeatures giải mã predictions.raw_features; lỗi giải mã được log và trả nil.
+func OpenFeatures(raw []byte) []byte {
+	out, err := fieldcrypt.Default().OpenJSON(raw)
+	if err != nil {
+		L().Warnw("cannot decrypt raw_features", "error", err)
+		return nil
+	}
+	return out
+}
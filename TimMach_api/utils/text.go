@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// RemoveDiacritics bỏ dấu tiếng Việt (và các dấu kết hợp khác), ví dụ "Đi bộ nhanh" -> "Di bo nhanh".
+func RemoveDiacritics(input string) string {
+	var b strings.Builder
+	b.Grow(len(input))
+	for _, r := range norm.NFD.String(input) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ':
+			b.WriteRune('d')
+		case r == 'Đ':
+			b.WriteRune('D')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Slugify tạo slug ASCII dạng "di-bo-nhanh" từ chuỗi bất kỳ.
+func Slugify(input string) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(RemoveDiacritics(input)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+		if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -25,6 +25,16 @@ func UserIDFromContext(c *gin.Context) (string, bool) {
 	return "", false
 }
 
+// RolesFromContext lấy realm roles Keycloak do middleware auth set.
+func RolesFromContext(c *gin.Context) []string {
+	if raw, ok := c.Get("keycloak_roles"); ok {
+		if roles, ok := raw.([]string); ok {
+			return roles
+		}
+	}
+	return nil
+}
+
 func FormatUserID(seq int64, ts time.Time) string {
 	date := ts.Format("20060102")
 	return fmt.Sprintf("USER_%s_%03d", date, seq)
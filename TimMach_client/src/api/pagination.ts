@@ -0,0 +1,13 @@
+// Phân trang keyset dùng chung: gửi lại next_cursor của trang trước để lấy trang tiếp theo.
+export interface PageParams {
+  limit?: number;
+  cursor?: string;
+  sort?: string; // vd. "-created_at"; field hợp lệ tuỳ endpoint
+}
+
+export interface PageMeta {
+  next_cursor?: string;
+  has_more: boolean;
+  limit: number;
+  sort: string;
+}
@@ -0,0 +1,47 @@
+import client from '../../api/client';
+import { PageParams } from '../../api/pagination';
+import {
+  CohortRequest,
+  CohortResponse,
+  CompareCohortsParams,
+  CompareCohortsResponse,
+  ListCohortsResponse,
+} from './types';
+
+export async function listCohorts(params?: PageParams): Promise<ListCohortsResponse> {
+  const { data } = await client.get<ListCohortsResponse>('/cohorts', { params });
+  return data;
+}
+
+export async function getCohort(id: string): Promise<CohortResponse> {
+  const { data } = await client.get<CohortResponse>(`/cohorts/${id}`);
+  return data;
+}
+
+export async function createCohort(payload: CohortRequest): Promise<CohortResponse> {
+  const { data } = await client.post<CohortResponse>('/cohorts', payload);
+  return data;
+}
+
+export async function updateCohort(id: string, payload: CohortRequest): Promise<CohortResponse> {
+  const { data } = await client.put<CohortResponse>(`/cohorts/${id}`, payload);
+  return data;
+}
+
+export async function deleteCohort(id: string): Promise<void> {
+  await client.delete(`/cohorts/${id}`);
+}
+
+function compareQuery({ ids, from, to }: CompareCohortsParams) {
+  return { ids: ids.join(','), from, to };
+}
+
+export async function compareCohorts(params: CompareCohortsParams): Promise<CompareCohortsResponse> {
+  const { data } = await client.get<CompareCohortsResponse>('/cohorts/compare', { params: compareQuery(params) });
+  return data;
+}
+
+export async function downloadCohortComparison(params: CompareCohortsParams): Promise<Blob> {
+  const { data } = await client.get('/cohorts/compare.pdf', { params: compareQuery(params), responseType: 'blob' });
+  return data;
+}
@@ -0,0 +1,85 @@
+import { PageMeta } from '../../api/pagination';
+
+export type CohortRiskLabel = 'low' | 'medium' | 'high' | 'none';
+
+// Bộ lọc đã lưu của cohort; các điều kiện kết hợp bằng AND, bỏ trống là không lọc.
+export interface CohortFilter {
+  gender?: 0 | 1 | 2;
+  min_age?: number;
+  max_age?: number;
+  risk_labels?: CohortRiskLabel[];
+  min_probability?: number;
+  max_probability?: number;
+  factors?: string[]; // cholesterol, gluc, blood_pressure, bmi, smoke, alco, active
+  exercise_program?: boolean;
+  created_from?: string; // yyyy-mm-dd
+  created_to?: string; // yyyy-mm-dd
+}
+
+export interface CohortRequest {
+  name: string;
+  description?: string;
+  filter: CohortFilter;
+}
+
+export interface CohortResponse {
+  id: string;
+  name: string;
+  description: string;
+  filter: CohortFilter;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ListCohortsResponse extends PageMeta {
+  cohorts: CohortResponse[];
+}
+
+export interface CompareCohortsParams {
+  ids: string[]; // 2-4 cohort
+  from?: string; // yyyy-mm-dd
+  to?: string; // yyyy-mm-dd
+}
+
+export interface CohortRiskShare {
+  risk_label: CohortRiskLabel;
+  count: number;
+  share: number;
+}
+
+export interface CohortProbabilityChange {
+  patients: number;
+  mean_change: number | null;
+  improved: number;
+  worsened: number;
+}
+
+export interface CohortTrendPoint {
+  month: string; // yyyy-mm
+  predictions: number;
+  mean_probability: number | null;
+}
+
+export interface CohortAdherence {
+  patients_with_plan: number;
+  patients_logging: number;
+  sessions: number;
+  mean_adherence: number | null;
+  adherent: number;
+}
+
+export interface CohortMetrics {
+  cohort: CohortResponse;
+  patients: number;
+  risk_counts: CohortRiskShare[];
+  mean_probability: number | null;
+  probability_change: CohortProbabilityChange;
+  trend: CohortTrendPoint[];
+  adherence: CohortAdherence;
+}
+
+export interface CompareCohortsResponse {
+  from: string;
+  to: string;
+  cohorts: CohortMetrics[];
+}
@@ -10,8 +10,8 @@ import { StatsResponse } from '../../stats/types';
 
 function DashboardPage() {
   const { data: recentPatients = [], isLoading } = useQuery<PatientResponse[]>({
-    queryKey: ['patients', 5, 0, null],
-    queryFn: () => listPatients({ limit: 5 }),
+    queryKey: ['patients', 'recent'],
+    queryFn: () => listPatients({ limit: 5 }).then((res) => res.patients),
   });
   const { data: stats, isLoading: statsLoading } = useQuery<StatsResponse>({
     queryKey: ['stats'],
@@ -0,0 +1,41 @@
+import client from '../../api/client';
+import {
+  ClinicBrandingRequest,
+  ClinicBrandingResponse,
+  EmailLocale,
+  EmailPreviewResponse,
+  EmailStatusResponse,
+  EmailTemplateInfo,
+} from './types';
+
+export async function listEmailTemplates(): Promise<EmailTemplateInfo[]> {
+  const { data } = await client.get<{ templates: EmailTemplateInfo[] }>('/email-templates');
+  return data.templates;
+}
+
+// Xem trước template với dữ liệu mẫu và branding phòng khám của user đang đăng nhập.
+export async function previewEmailTemplate(name: string, locale?: EmailLocale): Promise<EmailPreviewResponse> {
+  const { data } = await client.get<EmailPreviewResponse>(`/email-templates/${name}/preview`, {
+    params: locale ? { locale } : undefined,
+  });
+  return data;
+}
+
+export async function listClinicBrandings(): Promise<ClinicBrandingResponse[]> {
+  const { data } = await client.get<{ brandings: ClinicBrandingResponse[] }>('/clinic-brandings');
+  return data.brandings;
+}
+
+export async function saveClinicBranding(clinic: string, payload: ClinicBrandingRequest): Promise<ClinicBrandingResponse> {
+  const { data } = await client.put<ClinicBrandingResponse>(`/clinic-brandings/${encodeURIComponent(clinic)}`, payload);
+  return data;
+}
+
+export async function deleteClinicBranding(clinic: string): Promise<void> {
+  await client.delete(`/clinic-brandings/${encodeURIComponent(clinic)}`);
+}
+
+export async function getEmailStatus(emailId: number): Promise<EmailStatusResponse> {
+  const { data } = await client.get<EmailStatusResponse>(`/email-outbox/${emailId}`);
+  return data;
+}
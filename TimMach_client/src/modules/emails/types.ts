@@ -0,0 +1,67 @@
+export type EmailLocale = 'vi' | 'en';
+
+// Mã hoá PDF báo cáo gửi email: dob = mật khẩu là ngày sinh DDMMYYYY, code = mã dùng một lần gửi riêng.
+export type PdfProtection = 'none' | 'dob' | 'code';
+
+export interface EmailTemplateInfo {
+  name: string;
+  locales: EmailLocale[];
+}
+
+export interface EmailPreviewResponse {
+  name: string;
+  locale: EmailLocale;
+  subject: string;
+  text: string;
+  html: string;
+}
+
+// Branding phòng khám (khớp users.clinic), chỉ admin quản lý.
+export interface ClinicBrandingRequest {
+  display_name: string;
+  logo_base64?: string; // bỏ qua: giữ logo hiện tại, "": bỏ logo
+  primary_color?: string; // #rrggbb
+  default_locale?: EmailLocale;
+  footer?: string;
+  pdf_protection?: PdfProtection; // bỏ qua: giữ cấu hình hiện tại
+}
+
+export interface ClinicBrandingResponse {
+  clinic: string;
+  display_name: string;
+  has_logo: boolean;
+  logo_mime?: string;
+  primary_color: string;
+  default_locale: EmailLocale;
+  footer: string;
+  pdf_protection: PdfProtection;
+  updated_at: string;
+}
+
+export type EmailStatus = 'queued' | 'sending' | 'sent' | 'failed' | 'bounced';
+
+export interface EmailAttempt {
+  attempt: number;
+  status: Exclude<EmailStatus, 'queued' | 'sending'>;
+  error?: string;
+  started_at: string;
+  finished_at: string;
+}
+
+export interface EmailStatusResponse {
+  id: number;
+  to: string;
+  role: 'to' | 'cc' | 'bcc';
+  pdf_protection: PdfProtection;
+  subject: string;
+  patient_id?: number;
+  report_id?: number;
+  status: EmailStatus;
+  attempts: number;
+  max_attempts: number;
+  next_attempt_at?: string;
+  last_error?: string;
+  sent_at?: string;
+  created_at: string;
+  history: EmailAttempt[];
+}
@@ -1,8 +1,17 @@
 import client from '../../api/client';
-import { CreateTemplateRequest, TemplateResponse, RecommendationResponse } from './types';
+import { PageParams } from '../../api/pagination';
+import {
+  CreateExerciseSessionRequest,
+  CreateTemplateRequest,
+  ExerciseSessionResponse,
+  ListExerciseSessionsResponse,
+  ListTemplatesResponse,
+  TemplateResponse,
+  RecommendationResponse,
+} from './types';
 
 export async function listTemplates(): Promise<TemplateResponse[]> {
-  const { data } = await client.get<{ templates: TemplateResponse[] }>('/exercise-templates');
+  const { data } = await client.get<ListTemplatesResponse>('/exercise-templates');
   return data.templates;
 }
 
@@ -13,7 +22,7 @@ export async function createTemplate(payload: CreateTemplateRequest): Promise<Te
 
 export async function listRecommendations(
   patientId: string,
-  params?: { limit?: number; offset?: number },
+  params?: PageParams,
 ): Promise<RecommendationResponse[]> {
   const { data } = await client.get<{ recommendations: RecommendationResponse[] }>(
     `/patients/${patientId}/recommendations`,
@@ -21,3 +30,22 @@ export async function listRecommendations(
   );
   return data.recommendations;
 }
+
+// Ghi nhận buổi tập bệnh nhân đã thực hiện (dùng để tính mức tuân thủ kế hoạch tập).
+export async function createExerciseSession(
+  patientId: string,
+  payload: CreateExerciseSessionRequest,
+): Promise<ExerciseSessionResponse> {
+  const { data } = await client.post<ExerciseSessionResponse>(`/patients/${patientId}/exercise-sessions`, payload);
+  return data;
+}
+
+export async function listExerciseSessions(
+  patientId: string,
+  params?: PageParams,
+): Promise<ListExerciseSessionsResponse> {
+  const { data } = await client.get<ListExerciseSessionsResponse>(`/patients/${patientId}/exercise-sessions`, {
+    params,
+  });
+  return data;
+}
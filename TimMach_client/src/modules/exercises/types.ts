@@ -1,3 +1,5 @@
+import { PageMeta } from '../../api/pagination';
+
 export interface TemplateResponse {
   id: number;
   name: string;
@@ -9,6 +11,10 @@ export interface TemplateResponse {
   tags: string[];
 }
 
+export interface ListTemplatesResponse extends PageMeta {
+  templates: TemplateResponse[];
+}
+
 export interface CreateTemplateRequest {
   name: string;
   intensity: string;
@@ -39,3 +45,24 @@ export interface RecommendationResponse {
   plan?: RecommendationPlan | null;
   created_at: string;
 }
+
+export interface CreateExerciseSessionRequest {
+  performed_on: string; // yyyy-mm-dd
+  duration_min: number;
+  notes?: string;
+}
+
+export interface ExerciseSessionResponse {
+  id: string;
+  patient_id: string;
+  recommendation_id?: string;
+  performed_on: string;
+  duration_min: number;
+  notes?: string;
+  recorded_by?: string;
+  created_at: string;
+}
+
+export interface ListExerciseSessionsResponse extends PageMeta {
+  sessions: ExerciseSessionResponse[];
+}
@@ -1,14 +1,21 @@
 import client from '../../api/client';
+import { PageParams } from '../../api/pagination';
 import {
+  CreateOutcomeRequest,
   CreatePatientRequest,
+  DataRequestResponse,
+  DataRequestType,
   ListPatientsParams,
+  ListOutcomesResponse,
+  ListPatientsResponse,
+  OutcomeResponse,
   PatientResponse,
   UpdatePatientRequest,
 } from './types';
 
-export async function listPatients(params: ListPatientsParams): Promise<PatientResponse[]> {
-  const { data } = await client.get<{ patients: PatientResponse[] }>('/patients', { params });
-  return data.patients;
+export async function listPatients(params: ListPatientsParams): Promise<ListPatientsResponse> {
+  const { data } = await client.get<ListPatientsResponse>('/patients', { params });
+  return data;
 }
 
 export async function getPatient(id: string): Promise<PatientResponse> {
@@ -30,7 +37,43 @@ export async function deletePatient(id: string): Promise<void> {
   await client.delete(`/patients/${id}`);
 }
 
+// Khôi phục bệnh nhân đã xoá (xoá mềm), trước khi bị job retention xoá hẳn.
+export async function restorePatient(id: string): Promise<PatientResponse> {
+  const { data } = await client.post<PatientResponse>(`/patients/${id}/restore`);
+  return data;
+}
+
 export async function downloadPatientReport(id: string): Promise<Blob> {
   const { data } = await client.get(`/patients/${id}/report.pdf`, { responseType: 'blob' });
   return data;
 }
+
+// Yêu cầu export/xoá toàn bộ dữ liệu bệnh nhân; erasure cần confirm = tên bệnh nhân.
+export async function createDataRequest(
+  id: string,
+  type: DataRequestType,
+  confirm?: string,
+): Promise<DataRequestResponse> {
+  const { data } = await client.post<DataRequestResponse>(`/patients/${id}/data-requests`, { type, confirm });
+  return data;
+}
+
+export async function downloadDataExport(requestId: number): Promise<Blob> {
+  const { data } = await client.get(`/data-requests/${requestId}/download`, { responseType: 'blob' });
+  return data;
+}
+
+// Kết cục lâm sàng (chẩn đoán / theo dõi âm tính) dùng để đánh giá model.
+export async function listOutcomes(id: string, params?: PageParams): Promise<ListOutcomesResponse> {
+  const { data } = await client.get<ListOutcomesResponse>(`/patients/${id}/outcomes`, { params });
+  return data;
+}
+
+export async function createOutcome(id: string, payload: CreateOutcomeRequest): Promise<OutcomeResponse> {
+  const { data } = await client.post<OutcomeResponse>(`/patients/${id}/outcomes`, payload);
+  return data;
+}
+
+export async function deleteOutcome(outcomeId: string): Promise<void> {
+  await client.delete(`/outcomes/${outcomeId}`);
+}
@@ -17,6 +17,14 @@ import { RecommendationResponse } from '../../exercises/types';
 import { createReport, downloadReport, listReports, sendReportEmail } from '../../reports/api';
 import { ListReportsResponse, ReportResponse } from '../../reports/types';
 
+// splitEmails tách danh sách email nhập tay (phân cách dấu phẩy hoặc chấm phẩy).
+function splitEmails(input: string): string[] {
+  return input
+    .split(/[,;]/)
+    .map((e) => e.trim())
+    .filter(Boolean);
+}
+
 function PatientDetailPage() {
   const { id } = useParams<{ id: string }>();
   const { data: patient, isLoading } = useQuery<PatientResponse>({
@@ -59,6 +67,7 @@ function PatientDetailPage() {
 
   const [creatingReport, setCreatingReport] = useState(false);
   const [email, setEmail] = useState('');
+  const [ccEmail, setCcEmail] = useState('');
   const [sendingEmail, setSendingEmail] = useState(false);
   const [emailStatus, setEmailStatus] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
   const [downloadingReportId, setDownloadingReportId] = useState<number | null>(null);
@@ -98,15 +107,25 @@ function PatientDetailPage() {
   };
 
   const handleSendEmail = async (report: ReportResponse) => {
-    if (!email.trim()) {
+    const to = splitEmails(email);
+    if (to.length === 0) {
       setEmailStatus({ type: 'error', text: 'Vui lòng nhập email nhận báo cáo.' });
       return;
     }
     setEmailStatus(null);
     try {
       setSendingEmail(true);
-      await sendReportEmail(report.id, { email: email.trim() });
-      setEmailStatus({ type: 'success', text: `Đã gửi báo cáo tới ${email.trim()}.` });
+      const res = await sendReportEmail(report.id, { to, cc: splitEmails(ccEmail) });
+      let text = `Đã đưa báo cáo vào hàng đợi gửi tới ${res.recipients.map((r) => r.email).join(', ')}.`;
+      if (res.pdf_password) {
+        text += ` File PDF có mật khẩu ${res.pdf_password} (chỉ hiển thị một lần, hãy báo riêng cho bệnh nhân).`;
+      } else if (res.pdf_protection === 'dob') {
+        text += ' File PDF có mật khẩu là ngày sinh của bệnh nhân (DDMMYYYY).';
+      }
+      if (res.skipped?.length) {
+        text += ` Bỏ qua (đã nhận trước đó): ${res.skipped.join(', ')}.`;
+      }
+      setEmailStatus({ type: 'success', text });
       await refetchReports();
     } catch (err) {
       let message = 'Không thể gửi email';
@@ -147,12 +166,17 @@ function PatientDetailPage() {
         <div className="flex flex-col gap-3 sm:flex-row sm:items-center sm:justify-between">
           <div className="flex flex-col gap-2 sm:flex-row sm:items-center">
             <Input
-              type="email"
-              placeholder="Email nhận báo cáo"
+              placeholder="Email nhận báo cáo (phân cách dấu phẩy)"
               className="sm:max-w-sm"
               value={email}
               onChange={(e) => setEmail(e.target.value)}
             />
+            <Input
+              placeholder="CC (tuỳ chọn)"
+              className="sm:max-w-xs"
+              value={ccEmail}
+              onChange={(e) => setCcEmail(e.target.value)}
+            />
             <Button onClick={handleCreateReport} disabled={creatingReport}>
               {creatingReport ? 'Đang tạo...' : 'Tạo báo cáo mới'}
             </Button>
@@ -173,6 +197,14 @@ function PatientDetailPage() {
                   <p className="text-xs text-slate-500">
                     Tạo lúc: {new Date(report.created_at).toLocaleString('vi-VN')}
                   </p>
+                  {report.recipients.length > 0 && (
+                    <p className="text-xs text-slate-500">
+                      Đã gửi:{' '}
+                      {report.recipients
+                        .map((r) => `${r.email}${r.role && r.role !== 'to' ? ` (${r.role})` : ''}: ${r.status}`)
+                        .join(', ')}
+                    </p>
+                  )}
                 </div>
                 <div className="flex gap-2">
                   <Button
@@ -11,14 +11,6 @@ import { CreatePredictionRequest, CreatePredictionResponse } from '../../predict
 import { getPatient } from '../api';
 import { PatientResponse } from '../types';
 
-function ageFromDob(dob?: string) {
-  if (!dob) return undefined;
-  const date = new Date(dob);
-  if (Number.isNaN(date.getTime())) return undefined;
-  const diff = Date.now() - date.getTime();
-  return Math.floor(diff / (1000 * 60 * 60 * 24 * 365.25));
-}
-
 function PatientPredictPage() {
   const { id } = useParams<{ id: string }>();
   const navigate = useNavigate();
@@ -46,19 +38,15 @@ function PatientPredictPage() {
     enabled: !!patientId,
   });
 
-  const defaultAge = useMemo(() => ageFromDob(patient?.dob), [patient?.dob]);
-
   const latestDefaults = useMemo(() => {
     const raw = latestPrediction?.raw_features as Record<string, any> | undefined;
-    if (!raw) return { age_years: defaultAge };
+    if (!raw) return {};
     const input = (raw.input as Record<string, number | undefined>) || (raw as Record<string, number | undefined>);
     const pick = <T extends string>(key: T): number | undefined => {
       const v = input?.[key];
       return typeof v === 'number' ? v : undefined;
     };
     return {
-      age_years: pick('age_years') ?? defaultAge,
-      gender: pick('gender'),
       height: pick('height'),
       weight: pick('weight'),
       ap_hi: pick('ap_hi'),
@@ -69,7 +57,7 @@ function PatientPredictPage() {
       alco: pick('alco'),
       active: pick('active'),
     };
-  }, [latestPrediction?.raw_features, defaultAge]);
+  }, [latestPrediction?.raw_features]);
 
   const handleSubmit = async (values: CreatePredictionRequest) => {
     if (!patientId) return;
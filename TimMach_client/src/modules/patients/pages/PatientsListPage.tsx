@@ -1,10 +1,11 @@
+import { useState } from 'react';
 import { useQuery } from '@tanstack/react-query';
 import { Link, useSearchParams } from 'react-router-dom';
 import { Card } from '../../../components/ui/Card';
 import { Button } from '../../../components/ui/Button';
 import { PatientsTable } from '../components/PatientsTable';
 import { listPatients } from '../api';
-import { PatientResponse, ListPatientsParams } from '../types';
+import { ListPatientsParams, ListPatientsResponse } from '../types';
 
 function parseNumber(value: string | null, fallback: number) {
   const n = value ? Number(value) : NaN;
@@ -19,28 +20,53 @@ function parseRisk(value: string | null): string | undefined {
   return riskOptions.includes(normalized as (typeof riskOptions)[number]) ? normalized : undefined;
 }
 
+function parseOptionalNumber(value: string | null): number | undefined {
+  if (!value) return undefined;
+  const n = Number(value);
+  return Number.isFinite(n) ? n : undefined;
+}
+
+const filterInputClass =
+  'ml-2 rounded-lg border border-slate-200 bg-white px-3 py-2 text-sm shadow-sm focus:border-blue-500 focus:outline-none';
+
 function PatientsListPage() {
   const [params, setParams] = useSearchParams();
   const limit = parseNumber(params.get('limit'), 10);
-  const offset = parseNumber(params.get('offset'), 0);
   const risk = parseRisk(params.get('risk'));
+  // cursors[i] là cursor để tải trang i (trang đầu không có cursor).
+  const [cursors, setCursors] = useState<(string | undefined)[]>([undefined]);
+  const pageIndex = cursors.length - 1;
+  const cursor = cursors[pageIndex];
+
+  const filters: ListPatientsParams = {
+    risk,
+    q: params.get('q') || undefined,
+    gender: parseOptionalNumber(params.get('gender')),
+    min_age: parseOptionalNumber(params.get('min_age')),
+    max_age: parseOptionalNumber(params.get('max_age')),
+    min_probability: parseOptionalNumber(params.get('min_probability')),
+  };
 
-  const queryParams: ListPatientsParams = { limit, offset, risk };
-  const { data: patients = [], isLoading } = useQuery<PatientResponse[]>({
-    queryKey: ['patients', limit, offset, risk ?? null],
+  const queryParams: ListPatientsParams = { limit, cursor, ...filters };
+  const { data, isLoading } = useQuery<ListPatientsResponse>({
+    queryKey: ['patients', limit, cursor ?? null, filters],
     queryFn: () => listPatients(queryParams),
   });
+  const patients = data?.patients ?? [];
 
-  const nextPage = () => setParams({ limit: String(limit), offset: String(offset + limit), risk: risk ?? '' });
-  const prevPage = () =>
-    setParams({ limit: String(limit), offset: String(Math.max(0, offset - limit)), risk: risk ?? '' });
+  const nextPage = () => {
+    if (data?.next_cursor) setCursors([...cursors, data.next_cursor]);
+  };
+  const prevPage = () => setCursors(cursors.length > 1 ? cursors.slice(0, -1) : cursors);
 
-  const onChangeRisk = (value: string) => {
-    setParams({
-      limit: String(limit),
-      offset: '0',
-      risk: value,
-    });
+  // Đổi bộ lọc thì quay về trang đầu.
+  const onChangeFilter = (key: string, value: string) => {
+    setCursors([undefined]);
+    const next = new URLSearchParams(params);
+    if (value) next.set(key, value);
+    else next.delete(key);
+    next.set('limit', String(limit));
+    setParams(next);
   };
 
   return (
@@ -57,12 +83,67 @@ function PatientsListPage() {
 
       <Card>
         <div className="mb-3 flex flex-wrap items-center gap-3">
+          <label className="text-sm text-slate-600">
+            Tìm tên:
+            <input
+              className={filterInputClass}
+              placeholder="vd: nguyen van a"
+              defaultValue={filters.q ?? ''}
+              onKeyDown={(e) => {
+                if (e.key === 'Enter') onChangeFilter('q', e.currentTarget.value.trim());
+              }}
+            />
+          </label>
+          <label className="text-sm text-slate-600">
+            Giới tính:
+            <select
+              className={filterInputClass}
+              value={params.get('gender') ?? ''}
+              onChange={(e) => onChangeFilter('gender', e.target.value)}
+            >
+              <option value="">Tất cả</option>
+              <option value="1">Nam</option>
+              <option value="2">Nữ</option>
+              <option value="0">Khác</option>
+            </select>
+          </label>
+          <label className="text-sm text-slate-600">
+            Tuổi:
+            <input
+              type="number"
+              min={0}
+              className={`${filterInputClass} w-20`}
+              placeholder="từ"
+              defaultValue={params.get('min_age') ?? ''}
+              onBlur={(e) => onChangeFilter('min_age', e.target.value)}
+            />
+            <input
+              type="number"
+              min={0}
+              className={`${filterInputClass} w-20`}
+              placeholder="đến"
+              defaultValue={params.get('max_age') ?? ''}
+              onBlur={(e) => onChangeFilter('max_age', e.target.value)}
+            />
+          </label>
+          <label className="text-sm text-slate-600">
+            Xác suất ≥
+            <input
+              type="number"
+              min={0}
+              max={1}
+              step={0.05}
+              className={`${filterInputClass} w-24`}
+              defaultValue={params.get('min_probability') ?? ''}
+              onBlur={(e) => onChangeFilter('min_probability', e.target.value)}
+            />
+          </label>
           <label className="text-sm text-slate-600">
             Lọc theo nguy cơ:
             <select
-              className="ml-2 rounded-lg border border-slate-200 bg-white px-3 py-2 text-sm shadow-sm focus:border-blue-500 focus:outline-none"
+              className={filterInputClass}
               value={risk ?? ''}
-              onChange={(e) => onChangeRisk(e.target.value)}
+              onChange={(e) => onChangeFilter('risk', e.target.value)}
             >
               <option value="">Tất cả</option>
               {riskOptions.map((r) => (
@@ -76,19 +157,19 @@ function PatientsListPage() {
         {isLoading ? (
           <p className="text-sm text-slate-600">Đang tải...</p>
         ) : patients.length === 0 ? (
-          <p className="text-sm text-slate-600">Chưa có bệnh nhân nào.</p>
+          <p className="text-sm text-slate-600">Không có bệnh nhân phù hợp.</p>
         ) : (
           <PatientsTable patients={patients} />
         )}
         <div className="mt-3 flex items-center justify-between text-sm text-slate-700">
           <div>
-            Trang: {Math.floor(offset / limit) + 1}
+            Trang: {pageIndex + 1}
           </div>
           <div className="flex gap-2">
-            <Button variant="secondary" size="sm" onClick={prevPage} disabled={offset === 0}>
+            <Button variant="secondary" size="sm" onClick={prevPage} disabled={pageIndex === 0}>
               Trước
             </Button>
-            <Button variant="secondary" size="sm" onClick={nextPage} disabled={patients.length < limit}>
+            <Button variant="secondary" size="sm" onClick={nextPage} disabled={!data?.has_more}>
               Sau
             </Button>
           </div>
@@ -1,3 +1,5 @@
+import { PageMeta, PageParams } from '../../api/pagination';
+
 export interface CreatePatientRequest {
   name: string;
   gender: number;
@@ -26,8 +28,87 @@ export interface PatientResponse {
   latest_prediction?: PatientLatestPrediction | null;
 }
 
-export interface ListPatientsParams {
-  limit?: number;
-  offset?: number;
+// sort: chỉ 'created_at' | '-created_at' (tên đã mã hoá nên không còn sort=name).
+export interface ListPatientsParams extends PageParams {
   risk?: string;
+  q?: string; // tiền tố các từ trong tên, không phân biệt dấu
+  gender?: number;
+  min_age?: number; // tuổi tính theo năm sinh (năm hiện tại - năm sinh)
+  max_age?: number;
+  predicted_from?: string; // YYYY-MM-DD
+  predicted_to?: string; // YYYY-MM-DD
+  min_probability?: number;
+  max_probability?: number;
+}
+
+export interface ListPatientsResponse extends PageMeta {
+  patients: PatientResponse[];
+}
+
+export type DataRequestType = 'export' | 'erasure';
+
+export interface DataRequestCounts {
+  patients: number;
+  predictions: number;
+  rescores: number;
+  shadows: number;
+  recommendations: number;
+  reports: number;
+  outcomes: number;
+  exercise_sessions: number;
+  emails: number;
+  schedules: number;
+}
+
+export interface DataRequestTombstone {
+  erased_at: string;
+  deleted: DataRequestCounts;
+  files_deleted: number;
+  remaining: DataRequestCounts;
+  files_remaining: number;
+  verified: boolean;
+}
+
+export interface DataRequestResponse {
+  id: number;
+  patient_id: number;
+  type: DataRequestType;
+  status: 'pending' | 'completed' | 'failed';
+  download_url?: string;
+  tombstone?: DataRequestTombstone;
+  error?: string;
+  created_at: string;
+  completed_at?: string;
+}
+
+export type OutcomeEventType = 'diagnosis' | 'negative_followup';
+export type OutcomeCondition =
+  | 'coronary_heart_disease'
+  | 'stroke'
+  | 'heart_failure'
+  | 'peripheral_artery_disease'
+  | 'other_cvd';
+export type OutcomeSource = 'clinician' | 'ehr' | 'registry' | 'self_report';
+
+// condition bắt buộc với diagnosis, bỏ trống với negative_followup.
+export interface CreateOutcomeRequest {
+  event_type: OutcomeEventType;
+  condition?: OutcomeCondition;
+  occurred_on: string; // YYYY-MM-DD
+  source: OutcomeSource;
+}
+
+export interface OutcomeResponse {
+  id: string;
+  patient_id: string;
+  event_type: OutcomeEventType;
+  condition?: OutcomeCondition;
+  occurred_on: string;
+  source: OutcomeSource;
+  recorded_by?: string;
+  created_at: string;
+}
+
+export interface ListOutcomesResponse extends PageMeta {
+  outcomes: OutcomeResponse[];
 }
@@ -26,6 +26,6 @@ export async function listPredictions(
 }
 
 export async function getLatestPrediction(patientId: string): Promise<PredictionResponse | null> {
-  const list = await listPredictions(patientId, { limit: 1, offset: 0 });
+  const list = await listPredictions(patientId, { limit: 1 });
   return list[0] ?? null;
 }
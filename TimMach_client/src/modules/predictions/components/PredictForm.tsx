@@ -5,8 +5,6 @@ import { Button } from '../../../components/ui/Button';
 import { Input } from '../../../components/ui/Input';
 
 const schema = z.object({
-  age_years: z.coerce.number().positive(),
-  gender: z.coerce.number(),
   height: z.coerce.number().positive(),
   weight: z.coerce.number().positive(),
   ap_hi: z.coerce.number(),
@@ -27,11 +25,10 @@ interface Props {
 
 export function PredictForm({ defaultValues, onSubmit }: Props) {
   // defaultValues lấy từ lần dự đoán gần nhất (nếu có) để tiết kiệm thao tác nhập lại.
+  // Tuổi và giới tính do backend lấy từ hồ sơ bệnh nhân nên không nhập ở đây.
   const { register, handleSubmit, formState } = useForm<FormValues>({
     resolver: zodResolver(schema),
     defaultValues: {
-      age_years: 30,
-      gender: 1,
       height: 170,
       weight: 65,
       ap_hi: 120,
@@ -49,8 +46,6 @@ export function PredictForm({ defaultValues, onSubmit }: Props) {
     <form onSubmit={handleSubmit(onSubmit)} className="grid grid-cols-1 gap-4 md:grid-cols-2">
       {(
         [
-          ['age_years', 'Tuổi (năm)'],
-          ['gender', 'Giới tính (0/1/2)'],
           ['height', 'Chiều cao (cm)'],
           ['weight', 'Cân nặng (kg)'],
           ['ap_hi', 'Huyết áp tâm thu'],
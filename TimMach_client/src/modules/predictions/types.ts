@@ -1,8 +1,11 @@
+import { PageParams } from '../../api/pagination';
 import { RecommendationPlan } from '../exercises/types';
 
 export interface CreatePredictionRequest {
-  age_years: number;
-  gender: number;
+  // Backend tự tính từ hồ sơ bệnh nhân; chỉ gửi khi hồ sơ thiếu ngày sinh / giới tính (gender: 1 = nữ, 2 = nam).
+  age_years?: number;
+  gender?: number;
+  measured_at?: string;
   height: number;
   weight: number;
   ap_hi: number;
@@ -21,6 +24,12 @@ export interface RiskFactor {
   contribution?: number;
 }
 
+export interface FieldIssue {
+  field: string;
+  code: string;
+  message: string;
+}
+
 export interface PredictionResponse {
   id: string;
   patient_id: string;
@@ -29,13 +38,11 @@ export interface PredictionResponse {
   model_version?: string;
   raw_features?: Record<string, unknown> | null;
   factors?: RiskFactor[];
+  warnings?: FieldIssue[];
   created_at: string;
 }
 
-export interface ListPredictionsParams {
-  limit?: number;
-  offset?: number;
-}
+export type ListPredictionsParams = PageParams;
 
 export interface CreatePredictionResponse {
   prediction: PredictionResponse;
@@ -4,6 +4,7 @@ import {
   ListReportsResponse,
   ReportResponse,
   SendReportEmailRequest,
+  SendReportEmailResponse,
 } from './types';
 
 export async function createReport(patientId: string): Promise<ReportResponse> {
@@ -21,8 +22,10 @@ export async function downloadReport(reportId: number): Promise<Blob> {
   return data;
 }
 
-export async function sendReportEmail(reportId: number, payload: SendReportEmailRequest): Promise<void> {
-  await client.post(`/reports/${reportId}/email`, payload);
+// Email được đưa vào outbox và gửi nền (mỗi địa chỉ một email_id); theo dõi bằng getEmailStatus(email_id).
+export async function sendReportEmail(reportId: number, payload: SendReportEmailRequest): Promise<SendReportEmailResponse> {
+  const { data } = await client.post<SendReportEmailResponse>(`/reports/${reportId}/email`, payload);
+  return data;
 }
 
 export async function deleteReport(reportId: number): Promise<void> {
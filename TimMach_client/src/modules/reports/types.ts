@@ -1,7 +1,17 @@
+import { PageMeta, PageParams } from '../../api/pagination';
+import { EmailStatus, PdfProtection } from '../emails/types';
+
+// Trạng thái đồng bộ với outbox email; bản ghi cũ (trước outbox) không có outbox_id.
 export interface ReportRecipient {
   email: string;
-  sent_at: string;
-  status: string;
+  role?: RecipientRole;
+  status: EmailStatus;
+  outbox_id?: number;
+  queued_at?: string;
+  sent_at?: string;
+  attempts?: number;
+  error?: string;
+  pdf_protection?: PdfProtection;
 }
 
 export interface ReportResponse {
@@ -13,18 +23,39 @@ export interface ReportResponse {
   created_at: string;
 }
 
-export interface ListReportsResponse {
+export interface ListReportsResponse extends PageMeta {
   reports: ReportResponse[];
-  total: number;
 }
 
-export interface ListReportsParams {
-  limit?: number;
-  offset?: number;
-}
+export type ListReportsParams = PageParams;
 
+export type RecipientRole = 'to' | 'cc' | 'bcc';
+
+// Báo cáo đã lưu: địa chỉ đã nhận (queued/sending/sent) bị bỏ qua trừ khi resend = true.
 export interface SendReportEmailRequest {
+  email?: string; // trường cũ, tương đương một địa chỉ trong to
+  to?: string[];
+  cc?: string[];
+  bcc?: string[];
+  resend?: boolean;
+  subject?: string; // bỏ trống: dùng subject của template email
+  message?: string; // lời nhắn thêm hiển thị trong email
+  locale?: 'vi' | 'en'; // bỏ trống: ngôn ngữ mặc định của phòng khám
+  pdf_protection?: PdfProtection; // bỏ trống: theo cấu hình phòng khám
+}
+
+export interface QueuedRecipient {
+  email_id: number;
   email: string;
-  subject?: string;
-  message?: string;
+  role: RecipientRole;
+  status: EmailStatus;
+}
+
+export interface SendReportEmailResponse {
+  message: 'queued';
+  report_id?: number;
+  pdf_protection: PdfProtection;
+  pdf_password?: string; // chỉ có với code: báo cho bệnh nhân qua kênh riêng, không hiển thị lại
+  recipients: QueuedRecipient[];
+  skipped?: string[];
 }
@@ -0,0 +1,40 @@
+import client from '../../api/client';
+import {
+  ListSchedulesParams,
+  ListSchedulesResponse,
+  ScheduleRequest,
+  ScheduleResponse,
+  ScheduleRun,
+} from './types';
+
+export async function listSchedules(params?: ListSchedulesParams): Promise<ListSchedulesResponse> {
+  const { data } = await client.get<ListSchedulesResponse>('/report-schedules', { params });
+  return data;
+}
+
+export async function getSchedule(id: number): Promise<ScheduleResponse> {
+  const { data } = await client.get<ScheduleResponse>(`/report-schedules/${id}`);
+  return data;
+}
+
+export async function createSchedule(payload: ScheduleRequest): Promise<ScheduleResponse> {
+  const { data } = await client.post<ScheduleResponse>('/report-schedules', payload);
+  return data;
+}
+
+export async function updateSchedule(id: number, payload: ScheduleRequest): Promise<ScheduleResponse> {
+  const { data } = await client.put<ScheduleResponse>(`/report-schedules/${id}`, payload);
+  return data;
+}
+
+export async function deleteSchedule(id: number): Promise<void> {
+  await client.delete(`/report-schedules/${id}`);
+}
+
+// Các lần chạy gần nhất (mới nhất trước).
+export async function listScheduleRuns(id: number, limit?: number): Promise<ScheduleRun[]> {
+  const { data } = await client.get<{ runs: ScheduleRun[] }>(`/report-schedules/${id}/runs`, {
+    params: limit ? { limit } : undefined,
+  });
+  return data.runs;
+}
@@ -0,0 +1,59 @@
+import { PageMeta, PageParams } from '../../api/pagination';
+import { EmailLocale } from '../emails/types';
+
+// report: gửi báo cáo PDF mới của một bệnh nhân; reminder: nhắc tái khám khi lâu chưa có prediction.
+export type ScheduleKind = 'report' | 'reminder';
+
+export type ScheduleRunStatus = 'running' | 'succeeded' | 'skipped' | 'failed';
+
+// cron: 5 trường hoặc @daily/@weekly/@monthly, có thể thêm tiền tố "CRON_TZ=Asia/Ho_Chi_Minh ";
+// không chạy dày hơn mỗi giờ. Khi cập nhật không đổi được kind/patient_id.
+export interface ScheduleRequest {
+  patient_id?: number; // bắt buộc với report; reminder bỏ trống = mọi bệnh nhân
+  kind: ScheduleKind;
+  cron: string;
+  to?: string[]; // reminder bỏ trống = email của bác sĩ
+  cc?: string[];
+  locale?: EmailLocale;
+  message?: string;
+  inactive_months?: number; // reminder, mặc định 3
+  enabled?: boolean;
+}
+
+export interface ScheduleResponse {
+  id: number;
+  patient_id: number | null;
+  kind: ScheduleKind;
+  cron: string;
+  to: string[];
+  cc: string[];
+  locale?: EmailLocale;
+  message?: string;
+  inactive_months: number;
+  enabled: boolean;
+  next_run_at: string;
+  last_run_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ListSchedulesParams extends PageParams {
+  patient_id?: number;
+}
+
+export interface ListSchedulesResponse extends PageMeta {
+  schedules: ScheduleResponse[];
+}
+
+export interface ScheduleRun {
+  id: number;
+  schedule_id: number;
+  scheduled_for: string;
+  status: ScheduleRunStatus;
+  report_id?: number;
+  email_ids: number[]; // theo dõi bằng getEmailStatus
+  patient_ids: number[];
+  detail?: string;
+  started_at: string;
+  finished_at?: string;
+}
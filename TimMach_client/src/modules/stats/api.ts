@@ -1,7 +1,34 @@
 import client from '../../api/client';
-import { StatsResponse } from './types';
+import {
+  RiskFactorCombinationsParams,
+  RiskFactorCombinationsResponse,
+  RiskFactorsResponse,
+  StatsGroupBy,
+  StatsResponse,
+  StatsTimeseriesParams,
+  StatsTimeseriesResponse,
+} from './types';
 
 export async function getStats(): Promise<StatsResponse> {
   const { data } = await client.get<StatsResponse>('/stats');
   return data;
 }
+
+export async function getStatsTimeseries(params: StatsTimeseriesParams = {}): Promise<StatsTimeseriesResponse> {
+  const { data } = await client.get<StatsTimeseriesResponse>('/stats/timeseries', { params });
+  return data;
+}
+
+export async function getRiskFactors(groupBy?: StatsGroupBy): Promise<RiskFactorsResponse> {
+  const { data } = await client.get<RiskFactorsResponse>('/stats/risk-factors', {
+    params: groupBy ? { group_by: groupBy } : undefined,
+  });
+  return data;
+}
+
+export async function getRiskFactorCombinations(
+  params: RiskFactorCombinationsParams = {},
+): Promise<RiskFactorCombinationsResponse> {
+  const { data } = await client.get<RiskFactorCombinationsResponse>('/stats/risk-factors/combinations', { params });
+  return data;
+}
@@ -8,3 +8,75 @@ export interface StatsResponse {
   total_predictions: number;
   risk_counts: StatsRiskCount[];
 }
+
+export type StatsBucket = 'day' | 'week' | 'month';
+
+export interface StatsTimeseriesParams {
+  bucket?: StatsBucket;
+  from?: string; // yyyy-mm-dd
+  to?: string; // yyyy-mm-dd
+  tz?: string;
+}
+
+export interface StatsTimeseriesPoint {
+  bucket_start: string;
+  new_patients: number;
+  predictions: number;
+  avg_probability: number | null;
+  risk_counts: StatsRiskCount[];
+}
+
+export interface StatsTimeseriesResponse {
+  bucket: StatsBucket;
+  from: string;
+  to: string;
+  tz: string;
+  points: StatsTimeseriesPoint[];
+}
+
+export type StatsGroupBy = 'age_band' | 'gender';
+
+export interface FactorStat {
+  field: string;
+  patients: number;
+  prevalence: number;
+  avg_probability_with: number | null;
+  avg_probability_without: number | null;
+}
+
+export interface FactorBreakdown {
+  group?: string;
+  patients: number;
+  avg_probability: number | null;
+  factors: FactorStat[];
+}
+
+export interface RiskFactorsResponse extends FactorBreakdown {
+  group_by?: StatsGroupBy;
+  groups?: FactorBreakdown[];
+}
+
+export interface FactorCombination {
+  fields: string[];
+  patients: number;
+  prevalence: number;
+  avg_probability: number | null;
+}
+
+export interface CombinationBreakdown {
+  group?: string;
+  patients: number;
+  combinations: FactorCombination[];
+}
+
+export interface RiskFactorCombinationsParams {
+  group_by?: StatsGroupBy;
+  size?: 2 | 3;
+  limit?: number;
+}
+
+export interface RiskFactorCombinationsResponse extends CombinationBreakdown {
+  group_by?: StatsGroupBy;
+  size: number;
+  groups?: CombinationBreakdown[];
+}
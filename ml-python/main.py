@@ -1,7 +1,9 @@
+import hashlib
 import os
 from fastapi import FastAPI, HTTPException
 from pydantic import BaseModel, Field
 import joblib
+import numpy as np
 import pandas as pd
 import traceback
 from typing import List, Optional
@@ -16,12 +18,32 @@ app = FastAPI(
 BASE_DIR = os.path.dirname(os.path.abspath(__file__))
 MODEL_PATH = os.path.join(BASE_DIR, "training", "heart_disease_model.pkl")
 
+# Thứ tự cột đúng như lúc train trong notebook
+FEATURE_COLUMNS = [
+    "age_years", "gender", "height", "weight", "ap_hi", "ap_lo",
+    "cholesterol", "gluc", "smoke", "alco", "active", "bmi", "bp_ratio",
+]
+FEATURE_SCHEMA_HASH = hashlib.sha256(",".join(FEATURE_COLUMNS).encode()).hexdigest()[:16]
+MODEL_NAME = os.getenv("MODEL_NAME", "heart-disease-rf")
+
+
+def file_version(path: str) -> str:
+    """Version mặc định = 12 ký tự đầu sha256 của file model (đổi khi retrain)."""
+    h = hashlib.sha256()
+    with open(path, "rb") as f:
+        for chunk in iter(lambda: f.read(1 << 20), b""):
+            h.update(chunk)
+    return h.hexdigest()[:12]
+
+
 # Load model khi khởi động server
 try:
     model = joblib.load(MODEL_PATH)
+    MODEL_VERSION = os.getenv("MODEL_VERSION") or file_version(MODEL_PATH)
 except Exception as e:
     print(f"Không thể load model từ {MODEL_PATH}: {e}")
     model = None
+    MODEL_VERSION = ""
 
 
 class HeartInput(BaseModel):
@@ -37,10 +59,10 @@ class HeartInput(BaseModel):
     """
     age_years: float = Field(..., example=54)
     gender: int = Field(..., ge=1, le=2, example=2)
-    height: float = Field(..., example=165)  # cm
+    height: float = Field(..., gt=0, example=165)  # cm
     weight: float = Field(..., example=70)   # kg
     ap_hi: int = Field(..., example=130)     # huyết áp tâm thu
-    ap_lo: int = Field(..., example=80)      # huyết áp tâm trương
+    ap_lo: int = Field(..., gt=0, example=80)  # huyết áp tâm trương (chia khi tính bp_ratio)
     cholesterol: int = Field(..., ge=1, le=3, example=2)
     gluc: int = Field(..., ge=1, le=3, example=1)
     smoke: int = Field(..., ge=0, le=1, example=0)
@@ -55,11 +77,57 @@ class RiskFactor(BaseModel):
     contribution: Optional[float] = None
 
 
+class FeatureContribution(BaseModel):
+    feature: str
+    value: float
+    contribution: float
+
+
 class HeartPrediction(BaseModel):
     probability: float
     label: int
     risk_level: str
     factors: List[RiskFactor] = []
+    base_value: Optional[float] = None
+    contributions: List[FeatureContribution] = []
+    model_name: str = MODEL_NAME
+    model_version: str = ""
+    feature_schema_hash: str = FEATURE_SCHEMA_HASH
+
+
+class ModelInfo(BaseModel):
+    model_name: str
+    model_version: str
+    feature_schema_hash: str
+    feature_columns: List[str]
+
+
+def explain_contributions(X: pd.DataFrame):
+    """
+    Tách xác suất dự đoán thành đóng góp của từng feature (phương pháp Saabas / tree interpreter):
+    với mỗi cây, đi theo đường quyết định và cộng phần thay đổi xác suất lớp 1 vào feature dùng để split.
+    Tổng base_value + sum(contribution) = probability.
+    Feature sau bước tiền xử lý được map lại về tên cột gốc.
+    """
+    preprocess = model.named_steps["preprocess"]
+    forest = model.named_steps["classifier"]
+    Xt = np.asarray(preprocess.transform(X), dtype=np.float32)
+    names = [n.split("__", 1)[-1] for n in preprocess.get_feature_names_out()]
+
+    contrib = np.zeros(len(names))
+    base = 0.0
+    for tree in forest.estimators_:
+        t = tree.tree_
+        values = t.value[:, 0, :]
+        values = values / values.sum(axis=1, keepdims=True)
+        p1 = values[:, 1]
+        node_ids = tree.decision_path(Xt).indices
+        base += p1[0]
+        for parent, child in zip(node_ids[:-1], node_ids[1:]):
+            contrib[t.feature[parent]] += p1[child] - p1[parent]
+
+    n = len(forest.estimators_)
+    return base / n, dict(zip(names, contrib / n))
 
 
 @app.get("/health")
@@ -72,8 +140,23 @@ def health_check():
     return {"status": "ok"}
 
 
+@app.get("/model", response_model=ModelInfo)
+def model_info():
+    """
+    Thông tin model đang phục vụ (tên, version, hash của schema feature).
+    """
+    if model is None:
+        raise HTTPException(status_code=503, detail="Model chưa được load")
+    return ModelInfo(
+        model_name=MODEL_NAME,
+        model_version=MODEL_VERSION,
+        feature_schema_hash=FEATURE_SCHEMA_HASH,
+        feature_columns=FEATURE_COLUMNS,
+    )
+
+
 @app.post("/predict", response_model=HeartPrediction)
-def predict_heart_disease(data: HeartInput):
+def predict_heart_disease(data: HeartInput, explain: bool = False):
     """
     Dự đoán nguy cơ bệnh tim mạch.
 
@@ -82,6 +165,7 @@ def predict_heart_disease(data: HeartInput):
     - label: 0 hoặc 1
     - risk_level: low / medium / high
     - factors: Danh sách các yếu tố nguy cơ kèm mô tả
+    - base_value, contributions: chỉ khi gọi với ?explain=true
     """
     if model is None:
         raise HTTPException(status_code=500, detail="Model chưa được load. Kiểm tra lại file heart_disease_model.pkl")
@@ -121,7 +205,7 @@ def predict_heart_disease(data: HeartInput):
         }
 
         # Tạo DataFrame 1 dòng
-        X = pd.DataFrame([row])
+        X = pd.DataFrame([row], columns=FEATURE_COLUMNS)
 
         # Dự đoán
         proba = model.predict_proba(X)[0, 1]
@@ -151,11 +235,26 @@ def predict_heart_disease(data: HeartInput):
         if input_dict["active"] == 0:
             factors.append(RiskFactor(field="active", status="low", message="Ít vận động"))
 
+        base_value = None
+        contributions = []
+        if explain:
+            base_value, per_feature = explain_contributions(X)
+            base_value = float(round(base_value, 6))
+            contributions = [
+                FeatureContribution(feature=name, value=float(row[name]), contribution=float(round(c, 6)))
+                for name, c in per_feature.items()
+            ]
+
         return HeartPrediction(
             probability=float(round(proba, 4)),
             label=label,
             risk_level=risk_level,
             factors=factors,
+            base_value=base_value,
+            contributions=contributions,
+            model_name=MODEL_NAME,
+            model_version=MODEL_VERSION,
+            feature_schema_hash=FEATURE_SCHEMA_HASH,
         )
 
     except HTTPException:
@@ -0,0 +1,104 @@
+"""
+Xuất pipeline heart_disease_model.pkl sang JSON để Go API chấm điểm in-process (fallback khi service ML down).
+
+Chạy (trong thư mục ml-python):
+    python training/export_model.py
+
+Tạo ra:
+- training/heart_disease_model.json: StandardScaler + toàn bộ cây của RandomForest
+- training/parity_cases.json: input mẫu + output của Python để so sánh với scorer Go
+  (go run ./cmd/mlparity bên TimMach_api)
+"""
+import argparse
+import json
+import os
+import sys
+
+import pandas as pd
+
+BASE_DIR = os.path.dirname(os.path.dirname(os.path.abspath(__file__)))
+sys.path.insert(0, BASE_DIR)
+
+import main  # noqa: E402  (dùng chung model, cột feature và hàm tính feature với API)
+
+
+def export_forest(model):
+    preprocess = model.named_steps["preprocess"]
+    forest = model.named_steps["classifier"]
+
+    scaler_name, scaler, scaler_cols = preprocess.transformers_[0]
+    if scaler_name != "num":
+        raise RuntimeError(f"unexpected first transformer {scaler_name}")
+
+    trees = []
+    for est in forest.estimators_:
+        t = est.tree_
+        values = t.value[:, 0, :]
+        values = values / values.sum(axis=1, keepdims=True)
+        trees.append({
+            "left": t.children_left.tolist(),
+            "right": t.children_right.tolist(),
+            "feature": t.feature.tolist(),
+            "threshold": t.threshold.tolist(),
+            "value": values[:, 1].tolist(),
+        })
+
+    return {
+        "model_name": main.MODEL_NAME,
+        "model_version": main.MODEL_VERSION,
+        "feature_schema_hash": main.FEATURE_SCHEMA_HASH,
+        "input_features": main.FEATURE_COLUMNS,
+        "transformed_features": [n.split("__", 1)[-1] for n in preprocess.get_feature_names_out()],
+        "scaler": {
+            "features": list(scaler_cols),
+            "mean": scaler.mean_.tolist(),
+            "scale": scaler.scale_.tolist(),
+        },
+        "trees": trees,
+    }
+
+
+def load_dataset():
+    df = pd.read_csv(os.path.join(BASE_DIR, "training", "cardio_train.csv"), sep=";")
+    return df[(df["ap_lo"] > 0) & (df["height"] > 0)]
+
+
+def export_parity_cases(n, seed):
+    df = load_dataset().sample(n=n, random_state=seed)
+
+    cases = []
+    for _, r in df.iterrows():
+        data = main.HeartInput(
+            age_years=round(r["age"] / 365.25, 2),
+            gender=int(r["gender"]),
+            height=float(r["height"]),
+            weight=float(r["weight"]),
+            ap_hi=int(r["ap_hi"]),
+            ap_lo=int(r["ap_lo"]),
+            cholesterol=int(r["cholesterol"]),
+            gluc=int(r["gluc"]),
+            smoke=int(r["smoke"]),
+            alco=int(r["alco"]),
+            active=int(r["active"]),
+        )
+        out = main.predict_heart_disease(data, explain=True)
+        cases.append({"input": data.dict(), "output": json.loads(out.json())})
+    return cases
+
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--cases", type=int, default=200, help="số case parity")
+    parser.add_argument("--seed", type=int, default=42)
+    args = parser.parse_args()
+
+    if main.model is None:
+        sys.exit(f"Không load được model từ {main.MODEL_PATH}")
+
+    out_dir = os.path.join(BASE_DIR, "training")
+    with open(os.path.join(out_dir, "heart_disease_model.json"), "w") as f:
+        json.dump(export_forest(main.model), f)
+    with open(os.path.join(out_dir, "parity_cases.json"), "w") as f:
+        json.dump(export_parity_cases(args.cases, args.seed), f, ensure_ascii=False, indent=1)
+
+    print("Đã xuất heart_disease_model.json và parity_cases.json")